@@ -0,0 +1,124 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// About is the component description shown in the credits of an "About"
+// dialog, built the way Explorer and the common dialogs show it.
+type About struct {
+	// Name is ProductName falling back to FileDescription, OriginalFilename
+	// and the file name.
+	Name string
+	// Version is ProductVersion falling back to the binary product and file
+	// versions in the Major.Minor.Build.Revision form.
+	Version   string
+	Company   string
+	Copyright string
+	// Trademarks is LegalTrademarks.
+	Trademarks string
+	// Notes are the build flags (Debug build, Pre-release and so on) and the
+	// SpecialBuild and PrivateBuild descriptions.
+	Notes []string
+}
+
+// About collects the About dialog data of the file.
+func (f Info) About() About {
+	about := About{
+		Name:       firstNonEmpty(f.ProductName(), f.FileDescription(), f.OriginalFilename()),
+		Version:    strings.TrimSpace(f.ProductVersion()),
+		Company:    strings.TrimSpace(f.CompanyName()),
+		Copyright:  copyrightNotice(f.LegalCopyright()),
+		Trademarks: strings.TrimSpace(f.LegalTrademarks()),
+	}
+	if about.Name == "" && f.path != "" {
+		about.Name = filepath.Base(f.path)
+	}
+
+	fixed := f.FixedInfo()
+	if about.Version == "" {
+		switch {
+		case fixed.ProductVersion != FileVersion{}:
+			about.Version = rawVersion(fixed.ProductVersion)
+		case fixed.FileVersion != FileVersion{}:
+			about.Version = rawVersion(fixed.FileVersion)
+		}
+	}
+
+	flags := fixed.FileFlags & fixed.FileFlagsMask
+	for _, flag := range []struct {
+		mask uint32
+		note string
+	}{
+		{vsFFDebug, "Debug build"},
+		{vsFFPrerelease, "Pre-release"},
+		{vsFFPatched, "Patched"},
+	} {
+		if flags&flag.mask != 0 {
+			about.Notes = append(about.Notes, flag.note)
+		}
+	}
+	if special := strings.TrimSpace(f.SpecialBuild()); special != "" {
+		about.Notes = append(about.Notes, "Special build: "+special)
+	}
+	if private := strings.TrimSpace(f.PrivateBuild()); private != "" {
+		about.Notes = append(about.Notes, "Private build: "+private)
+	}
+	return about
+}
+
+// String formats the credits line, e.g.
+//
+//	Contoso Viewer v1.2.3.4, © 2021 Contoso Ltd. (Special build: hotfix 7)
+func (a About) String() string {
+	var sb strings.Builder
+	sb.WriteString(a.Name)
+	if a.Version != "" {
+		if sb.Len() != 0 {
+			sb.WriteString(" ")
+		}
+		if a.Version[0] >= '0' && a.Version[0] <= '9' {
+			sb.WriteString("v")
+		}
+		sb.WriteString(a.Version)
+	}
+	if a.Copyright != "" {
+		if sb.Len() != 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(a.Copyright)
+	}
+	if len(a.Notes) != 0 {
+		sb.WriteString(" (" + strings.Join(a.Notes, "; ") + ")")
+	}
+	return sb.String()
+}
+
+// copyrightNotice prefixes the copyright with the © sign unless it already
+// starts with a copyright mark.
+func copyrightNotice(copyright string) string {
+	copyright = strings.TrimSpace(copyright)
+	if copyright == "" {
+		return ""
+	}
+	lower := strings.ToLower(copyright)
+	for _, mark := range []string{"©", "(c)", "copyright"} {
+		if strings.HasPrefix(lower, mark) {
+			return copyright
+		}
+	}
+	return "© " + copyright
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// AlternateStream is a named NTFS data stream of a file.
+type AlternateStream struct {
+	// Name is the stream name without the leading colon and the `:$DATA`
+	// type suffix. Query the stream with New(path + ":" + Name).
+	Name string
+	Size int64
+}
+
+//nolint:gochecknoglobals
+var (
+	// FindFirstStreamW and FindNextStreamW are not wrapped by x/sys/windows.
+	findFirstStreamProc = kernel32.NewProc("FindFirstStreamW")
+	findNextStreamProc  = kernel32.NewProc("FindNextStreamW")
+)
+
+// win32FindStreamData is WIN32_FIND_STREAM_DATA. Source:
+// https://docs.microsoft.com/en-us/windows/win32/api/fileapi/ns-fileapi-win32_find_stream_data
+type win32FindStreamData struct {
+	StreamSize int64
+	StreamName [windows.MAX_PATH + 36]uint16
+}
+
+// findStreamInfoStandard is STREAM_INFO_LEVELS FindStreamInfoStandard.
+const findStreamInfoStandard = 0
+
+// AlternateStreams lists the alternate data streams of the file: attackers
+// hide payloads (with full version resources) there, as the streams aren't
+// shown by Explorer and most scanners. The unnamed main stream is not
+// included. Volumes without streams support (FAT) give an empty list.
+func AlternateStreams(path string) ([]AlternateStream, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := findFirstStreamProc.Find(); err != nil {
+		return nil, fmt.Errorf("stream enumeration is not available: %w", err)
+	}
+	var data win32FindStreamData
+	handle, _, err := findFirstStreamProc.Call(
+		uintptr(unsafe.Pointer(pathPtr)), findStreamInfoStandard, uintptr(unsafe.Pointer(&data)), 0)
+	if windows.Handle(handle) == windows.InvalidHandle {
+		if errors.Is(err, windows.ERROR_HANDLE_EOF) || errors.Is(err, windows.ERROR_INVALID_PARAMETER) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to enumerate streams of %q: %w", path, err)
+	}
+	defer windows.FindClose(windows.Handle(handle)) //nolint:errcheck
+
+	var streams []AlternateStream
+	for {
+		name := strings.TrimSuffix(strings.TrimPrefix(windows.UTF16ToString(data.StreamName[:]), ":"), ":$DATA")
+		if name != "" {
+			streams = append(streams, AlternateStream{Name: name, Size: data.StreamSize})
+		}
+		ret, _, err := findNextStreamProc.Call(handle, uintptr(unsafe.Pointer(&data)))
+		if ret == 0 {
+			if errors.Is(err, windows.ERROR_HANDLE_EOF) {
+				return streams, nil
+			}
+			return nil, fmt.Errorf("failed to enumerate streams of %q: %w", path, err)
+		}
+	}
+}
+
+// isStreamPath reports whether the path names an alternate data stream like
+// `file.exe:payload` or `file.exe:payload:$DATA`. The loader maps only main
+// streams, so such paths are read like WithShareDelete does.
+func isStreamPath(path string) bool {
+	name := path[len(filepath.VolumeName(path)):]
+	if i := strings.LastIndexAny(name, `\/`); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.Contains(name, ":")
+}
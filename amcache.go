@@ -0,0 +1,249 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"crypto/sha1" //nolint:gosec // Amcache stores SHA-1 digests.
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// AmcacheEntry is a file entry of the Amcache.hve hive which records the
+// executables run or installed on the machine with their version info at
+// that time.
+type AmcacheEntry struct {
+	// Path is LowerCaseLongPath, the lowercased full path.
+	Path              string
+	Name              string
+	Publisher         string
+	Version           string
+	ProductName       string
+	ProductVersion    string
+	BinFileVersion    string
+	BinProductVersion string
+	LinkDate          string
+	Size              uint64
+	// SHA1 is the hex digest from FileId. Windows hashes only the first 30 MiB
+	// of bigger files, so it's the digest of that prefix for them.
+	SHA1 string
+}
+
+// AmcacheStatus is the result of comparing an Amcache entry with the file.
+type AmcacheStatus int
+
+// Comparison results.
+const (
+	// AmcacheMatch means the file matches the recorded data.
+	AmcacheMatch AmcacheStatus = iota
+	// AmcacheMissing means the file doesn't exist anymore.
+	AmcacheMissing
+	// AmcacheReplaced means the file content or version info differs.
+	AmcacheReplaced
+	// AmcacheUnreadable means the file exists but can't be read.
+	AmcacheUnreadable
+)
+
+// String returns the status name.
+func (s AmcacheStatus) String() string {
+	switch s {
+	case AmcacheMatch:
+		return "match"
+	case AmcacheMissing:
+		return "missing"
+	case AmcacheReplaced:
+		return "replaced"
+	case AmcacheUnreadable:
+		return "unreadable"
+	default:
+		return fmt.Sprintf("AmcacheStatus(%d)", int(s))
+	}
+}
+
+// AmcacheComparison is the result of CompareAmcache for an entry.
+type AmcacheComparison struct {
+	Entry  AmcacheEntry
+	Status AmcacheStatus
+	// Differences describe the mismatching fields of replaced files, e.g.
+	// `BinFileVersion "10.0.1.0" != "10.0.2.0"`.
+	Differences []string
+	Err         error
+}
+
+//nolint:gochecknoglobals
+var (
+	// RegLoadAppKeyW is not wrapped by x/sys/windows.
+	advapi32          = windows.NewLazySystemDLL("advapi32.dll")
+	regLoadAppKeyProc = advapi32.NewProc("RegLoadAppKeyW")
+)
+
+// Amcache layout. amcacheMaxHashedSize is the length of the file prefix
+// Windows hashes.
+const (
+	amcacheFileEntries   = `Root\InventoryApplicationFile`
+	amcacheMaxHashedSize = 31457280
+)
+
+// ReadAmcache reads the file entries of an Amcache.hve hive
+// (`Root\InventoryApplicationFile` of Windows 10 and later; the older
+// `Root\File` layout isn't supported). The hive is loaded privately with
+// RegLoadAppKey. The live `%SystemRoot%\AppCompat\Programs\Amcache.hve` is
+// locked by the system, read a copy of it (e.g. from a shadow copy).
+func ReadAmcache(hivePath string) ([]AmcacheEntry, error) {
+	pathPtr, err := windows.UTF16PtrFromString(hivePath)
+	if err != nil {
+		return nil, err
+	}
+	var hive registry.Key
+	ret, _, _ := regLoadAppKeyProc.Call(uintptr(unsafe.Pointer(pathPtr)), uintptr(unsafe.Pointer(&hive)), registry.READ, 0, 0)
+	if ret != 0 {
+		return nil, fmt.Errorf("failed to load hive %q: %w", hivePath, syscall.Errno(ret))
+	}
+	defer hive.Close()
+
+	root, err := openRegistryKey(hive, amcacheFileEntries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s of %q: %w", amcacheFileEntries, hivePath, err)
+	}
+	defer root.Close()
+	names, err := root.ReadSubKeyNames(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enumerate Amcache entries: %w", err)
+	}
+	entries := make([]AmcacheEntry, 0, len(names))
+	for _, name := range names {
+		key, err := openRegistryKey(root, name)
+		if err != nil {
+			continue
+		}
+		entry := AmcacheEntry{
+			Path:              amcacheString(key, "LowerCaseLongPath"),
+			Name:              amcacheString(key, "Name"),
+			Publisher:         amcacheString(key, "Publisher"),
+			Version:           amcacheString(key, "Version"),
+			ProductName:       amcacheString(key, "ProductName"),
+			ProductVersion:    amcacheString(key, "ProductVersion"),
+			BinFileVersion:    amcacheString(key, "BinFileVersion"),
+			BinProductVersion: amcacheString(key, "BinProductVersion"),
+			LinkDate:          amcacheString(key, "LinkDate"),
+		}
+		if size, _, err := key.GetIntegerValue("Size"); err == nil {
+			entry.Size = size
+		}
+		// FileId is the SHA-1 prefixed with four zeros.
+		if id := amcacheString(key, "FileId"); len(id) == 44 {
+			entry.SHA1 = strings.ToLower(id[4:])
+		}
+		key.Close()
+		if entry.Path != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func amcacheString(key registry.Key, name string) string {
+	value, _, err := key.GetStringValue(name)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// CompareAmcache checks the files of the entries against their current
+// state: a binary replaced since it was run keeps its Amcache entry with the
+// old digest and version info, which is evidence of tampering or of an
+// update. The entries without a digest are compared by size and version
+// info only. The comparisons are returned in the entries order.
+func CompareAmcache(entries []AmcacheEntry, opts ...Option) []AmcacheComparison {
+	comparisons := make([]AmcacheComparison, len(entries))
+	for i, entry := range entries {
+		comparisons[i] = compareAmcacheEntry(entry, opts)
+	}
+	return comparisons
+}
+
+func compareAmcacheEntry(entry AmcacheEntry, opts []Option) AmcacheComparison {
+	c := AmcacheComparison{Entry: entry, Status: AmcacheMatch}
+	stat, err := os.Stat(entry.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		c.Status = AmcacheMissing
+		return c
+	}
+	if err != nil {
+		c.Status, c.Err = AmcacheUnreadable, err
+		return c
+	}
+	differ := func(field string, recorded, current interface{}) {
+		c.Differences = append(c.Differences, fmt.Sprintf("%s %q != %q", field, fmt.Sprint(recorded), fmt.Sprint(current)))
+	}
+	if entry.Size != 0 && uint64(stat.Size()) != entry.Size {
+		differ("Size", entry.Size, stat.Size())
+	}
+	if entry.SHA1 != "" {
+		digest, err := fileSHA1(entry.Path)
+		if err != nil {
+			c.Status, c.Err = AmcacheUnreadable, err
+			return c
+		}
+		if digest != entry.SHA1 {
+			differ("SHA1", entry.SHA1, digest)
+		}
+	}
+
+	info, err := New(entry.Path, opts...)
+	switch {
+	case errors.Is(err, ErrNoVersionInfo):
+		if entry.Version != "" || entry.BinFileVersion != "" {
+			differ("Version", entry.Version, "")
+		}
+	case err != nil:
+		c.Status, c.Err = AmcacheUnreadable, err
+		return c
+	default:
+		fixed := info.FixedInfo()
+		for _, field := range []struct {
+			name              string
+			recorded, current string
+		}{
+			{"Version", entry.Version, info.FileVersion()},
+			{"ProductName", entry.ProductName, info.ProductName()},
+			{"ProductVersion", entry.ProductVersion, info.ProductVersion()},
+			{"BinFileVersion", entry.BinFileVersion, rawVersion(fixed.FileVersion)},
+			{"BinProductVersion", entry.BinProductVersion, rawVersion(fixed.ProductVersion)},
+		} {
+			// Amcache lowercases some of the strings.
+			if field.recorded != "" && !strings.EqualFold(strings.TrimSpace(field.recorded), strings.TrimSpace(field.current)) {
+				differ(field.name, field.recorded, field.current)
+			}
+		}
+	}
+	if len(c.Differences) != 0 {
+		c.Status = AmcacheReplaced
+	}
+	return c
+}
+
+// fileSHA1 returns the digest of the file as Amcache computes it: of the
+// first amcacheMaxHashedSize bytes.
+func fileSHA1(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %q: %w", path, err)
+	}
+	defer file.Close()
+	hash := sha1.New() //nolint:gosec
+	if _, err := io.Copy(hash, io.LimitReader(file, amcacheMaxHashedSize)); err != nil {
+		return "", fmt.Errorf("failed to hash %q: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,118 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ArchiveEntry is a PE image found inside an archive. Err is set if the image
+// version info can't be read (e.g. matches ErrNoVersionInfo).
+type ArchiveEntry struct {
+	Name string
+	Info Info
+	Err  error
+}
+
+// ArchiveWalker calls fn for every file of the archive at path. It's used for
+// plugging in archive formats not supported natively (like 7z).
+type ArchiveWalker func(path string, fn func(name string, r io.Reader) error) error
+
+//nolint:gochecknoglobals
+var (
+	archiveFormatsMu sync.RWMutex
+	archiveFormats   = map[string]ArchiveWalker{
+		".zip":   walkZip,
+		".nupkg": walkZip,
+		".appx":  walkZip,
+		".msix":  walkZip,
+		".jar":   walkZip,
+		".wim":   walkWIM,
+	}
+)
+
+// RegisterArchiveFormat registers a walker for archives with the given file
+// extension (like ".7z"), replacing the existing one.
+func RegisterArchiveFormat(ext string, walker ArchiveWalker) {
+	archiveFormatsMu.Lock()
+	defer archiveFormatsMu.Unlock()
+	archiveFormats[strings.ToLower(ext)] = walker
+}
+
+// maxArchiveEntrySize limits the size of archive entries loaded into memory.
+const maxArchiveEntrySize = 256 << 20
+
+// ScanArchive reads version info of all the PE images inside the archive at
+// path without extracting them to disk. The format is chosen by the file
+// extension: zip-based formats (.zip, .nupkg, .appx, .msix, .jar) and WIM
+// images (.wim, mounted with wimgapi, which requires administrator rights) are
+// supported out of the box, others can be added with RegisterArchiveFormat.
+//
+// Images are detected by the content like DetectExecutableFormat does, so
+// renamed ones are found too. Non-PE executables are reported with
+// ErrUnsupportedFormat. Entries larger than 256MB are skipped.
+func ScanArchive(path string, opts ...Option) ([]ArchiveEntry, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	archiveFormatsMu.RLock()
+	walker, ok := archiveFormats[ext]
+	archiveFormatsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported archive format %q", ext)
+	}
+
+	var entries []ArchiveEntry
+	err := walker(path, func(name string, r io.Reader) error {
+		data, err := ioutil.ReadAll(io.LimitReader(r, maxArchiveEntrySize+1))
+		if err != nil {
+			return fmt.Errorf("failed to read %q: %w", name, err)
+		}
+		if len(data) > maxArchiveEntrySize {
+			return nil
+		}
+		image := bytes.NewReader(data)
+		if DetectExecutableFormat(image) == FormatUnknown {
+			return nil
+		}
+		info, err := NewFromReader(image, opts...)
+		entries = append(entries, ArchiveEntry{Name: name, Info: info, Err: err})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk archive %q: %w", path, err)
+	}
+	return entries, nil
+}
+
+func walkZip(path string, fn func(name string, r io.Reader) error) error {
+	z, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer z.Close()
+	for _, file := range z.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		if err := walkZipFile(file, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkZipFile(file *zip.File, fn func(name string, r io.Reader) error) error {
+	r, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %q: %w", file.Name, err)
+	}
+	defer r.Close()
+	return fn(file.Name, r)
+}
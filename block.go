@@ -0,0 +1,281 @@
+package fileversion
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// versionBlock is a generic node of the version-information resource tree.
+// VS_VERSIONINFO, StringFileInfo, StringTable, String, VarFileInfo and Var
+// share the same header layout.
+//
+// Ref: https://docs.microsoft.com/en-us/windows/win32/menurc/version-information-structures
+type versionBlock struct {
+	key      string
+	isText   bool
+	value    []byte
+	children []versionBlock
+}
+
+// VersionBlock is a node of a version-information resource tree as stored in
+// the resource: VS_VERSIONINFO, StringFileInfo, StringTable, String,
+// VarFileInfo and Var structures all share this layout.
+type VersionBlock struct {
+	Key string
+	// IsText is set for blocks with a UTF16 string value.
+	IsText bool
+	// Value is the raw value, it references the parsed data.
+	Value    []byte
+	Children []VersionBlock
+}
+
+// Text decodes a zero-terminated UTF16 value of a text block.
+func (b VersionBlock) Text() string {
+	return versionBlock{value: b.Value}.text()
+}
+
+// ParseVersionBlock parses a raw VS_VERSIONINFO resource in pure Go without
+// calling any windows API. It never panics on malformed data: all the lengths
+// are checked against the data bounds.
+func ParseVersionBlock(data []byte) (VersionBlock, error) {
+	block, _, err := parseVersionBlock(data)
+	if err != nil {
+		return VersionBlock{}, err
+	}
+	return block.export(), nil
+}
+
+func (b versionBlock) export() VersionBlock {
+	exported := VersionBlock{Key: b.key, IsText: b.isText, Value: b.value}
+	if len(b.children) != 0 {
+		exported.Children = make([]VersionBlock, len(b.children))
+		for i, c := range b.children {
+			exported.Children[i] = c.export()
+		}
+	}
+	return exported
+}
+
+// blockHeaderSize is a size of wLength, wValueLength and wType fields.
+const blockHeaderSize = 6
+
+// parseVersionBlock parses a block starting at data[0]. It returns the block
+// and its length including the padding to the next block.
+func parseVersionBlock(data []byte) (versionBlock, int, error) {
+	if len(data) < blockHeaderSize {
+		return versionBlock{}, 0, errors.New("block header is truncated")
+	}
+	length := int(binary.LittleEndian.Uint16(data))
+	valueLength := int(binary.LittleEndian.Uint16(data[2:]))
+	valueType := binary.LittleEndian.Uint16(data[4:])
+	if length < blockHeaderSize || length > len(data) {
+		return versionBlock{}, 0, fmt.Errorf("invalid block length %d", length)
+	}
+	data = data[:length]
+
+	pos := blockHeaderSize
+	var key []uint16
+	for {
+		if pos+2 > len(data) {
+			return versionBlock{}, 0, errors.New("block key is not terminated")
+		}
+		c := binary.LittleEndian.Uint16(data[pos:])
+		pos += 2
+		if c == 0 {
+			break
+		}
+		key = append(key, c)
+	}
+	pos = align4(pos)
+
+	block := versionBlock{
+		key:    string(utf16.Decode(key)),
+		isText: valueType == 1,
+	}
+	if block.isText {
+		// For text values wValueLength is a number of characters.
+		valueLength *= 2
+	}
+	if valueLength > 0 && pos < len(data) {
+		end := pos + valueLength
+		if end > len(data) {
+			end = len(data)
+		}
+		block.value = data[pos:end]
+		pos = align4(end)
+	}
+
+	for pos < len(data) {
+		child, n, err := parseVersionBlock(data[pos:])
+		if err != nil {
+			return versionBlock{}, 0, fmt.Errorf("failed to parse %q child: %w", block.key, err)
+		}
+		block.children = append(block.children, child)
+		pos = align4(pos + n)
+	}
+	return block, align4(length), nil
+}
+
+func align4(n int) int {
+	return (n + 3) &^ 3
+}
+
+// text decodes a zero-terminated UTF16 value of the block.
+func (b versionBlock) text() string {
+	return decodeUTF16(b.value)
+}
+
+// decodeUTF16 decodes a little endian UTF16 string up to the first zero
+// character. A trailing odd byte is ignored and unpaired surrogates become
+// U+FFFD like in utf16.Decode. It's on the hot path of the property getters,
+// so the UTF8 length is counted first and the string is the only allocation.
+func decodeUTF16(data []byte) string {
+	units, size := 0, 0
+	for ; 2*units+1 < len(data); units++ {
+		c := binary.LittleEndian.Uint16(data[2*units:])
+		switch {
+		case c == 0:
+			return buildUTF16String(data, units, size)
+		case c < utf8.RuneSelf:
+			size++
+		case c < 0x800:
+			size += 2
+		default:
+			// A surrogate pair takes 4 bytes, an unpaired surrogate becomes
+			// 3-byte U+FFFD, so 3 per unit is an upper bound.
+			size += 3
+		}
+	}
+	return buildUTF16String(data, units, size)
+}
+
+// buildUTF16String decodes units of data to a string of at most size bytes.
+func buildUTF16String(data []byte, units, size int) string {
+	var b strings.Builder
+	b.Grow(size)
+	if size == units {
+		for i := 0; i < units; i++ {
+			b.WriteByte(data[2*i])
+		}
+		return b.String()
+	}
+	for i := 0; i < units; {
+		r, n := decodeUTF16Rune(data, i, units)
+		b.WriteRune(r)
+		i += n
+	}
+	return b.String()
+}
+
+// decodeUTF16Rune decodes the rune starting at the i-th of units UTF16 code
+// units of data and returns it with the number of units it takes.
+func decodeUTF16Rune(data []byte, i, units int) (rune, int) {
+	c := rune(binary.LittleEndian.Uint16(data[2*i:]))
+	if !utf16.IsSurrogate(c) {
+		return c, 1
+	}
+	if i+1 < units {
+		if r := utf16.DecodeRune(c, rune(binary.LittleEndian.Uint16(data[2*i+2:]))); r != utf8.RuneError {
+			return r, 2
+		}
+	}
+	return utf8.RuneError, 1
+}
+
+// child returns the first child with the given key.
+func (b versionBlock) child(key string) (versionBlock, bool) {
+	for _, c := range b.children {
+		if c.key == key {
+			return c, true
+		}
+	}
+	return versionBlock{}, false
+}
+
+// stringTable returns the string table of the locale. The keys are compared
+// as numbers, so "040904B0" and "040904b0" name the same table.
+func stringTable(root versionBlock, locale Locale) (versionBlock, bool) {
+	stringFileInfo, _ := root.child("StringFileInfo")
+	for _, table := range stringFileInfo.children {
+		if l, ok := parseLocaleKey(table.key); ok && l == locale {
+			return table, true
+		}
+	}
+	return versionBlock{}, false
+}
+
+// parseLocaleKey parses a string table key like "040904b0".
+func parseLocaleKey(key string) (Locale, bool) {
+	if len(key) != 8 {
+		return Locale{}, false
+	}
+	v, err := strconv.ParseUint(key, 16, 32)
+	if err != nil {
+		return Locale{}, false
+	}
+	return Locale{LangID: LangID(v >> 16), CharsetID: CharsetID(v & 0xffff)}, true
+}
+
+// PropertyKey identifies a single translation of a string property.
+type PropertyKey struct {
+	Locale Locale
+	Name   string
+}
+
+// Property is a single translation of a string property.
+type Property struct {
+	PropertyKey
+	Value string
+}
+
+// stringTableLocales returns the locales of the string tables skipping ones
+// with malformed keys.
+func stringTableLocales(root versionBlock) []Locale {
+	var locales []Locale
+	stringFileInfo, _ := root.child("StringFileInfo")
+	for _, table := range stringFileInfo.children {
+		if locale, ok := parseLocaleKey(table.key); ok {
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
+
+func copyVarValues(vars map[string][]byte) map[string][]byte {
+	values := make(map[string][]byte, len(vars))
+	for key, value := range vars {
+		values[key] = append([]byte(nil), value...)
+	}
+	return values
+}
+
+// varValues collects the Var values referencing the parsed data.
+func varValues(root versionBlock) map[string][]byte {
+	values := make(map[string][]byte)
+	varFileInfo, _ := root.child("VarFileInfo")
+	for _, v := range varFileInfo.children {
+		if _, ok := values[v.key]; !ok {
+			values[v.key] = v.value
+		}
+	}
+	return values
+}
+
+// propertyNames returns unique names of the string properties in the resource
+// order.
+func propertyNames(properties []Property) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, p := range properties {
+		if !seen[p.Name] {
+			seen[p.Name] = true
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
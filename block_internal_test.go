@@ -0,0 +1,55 @@
+package fileversion
+
+import (
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+func encodeUTF16(s string) []byte {
+	u16 := append(utf16.Encode([]rune(s)), 0)
+	data := make([]byte, 2*len(u16))
+	for i, c := range u16 {
+		binary.LittleEndian.PutUint16(data[2*i:], c)
+	}
+	return data
+}
+
+func TestDecodeUTF16(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", nil, ""},
+		{"ascii", encodeUTF16("Microsoft Corporation"), "Microsoft Corporation"},
+		{"cyrillic", encodeUTF16("Корпорация Майкрософт"), "Корпорация Майкрософт"},
+		{"surrogate pair", encodeUTF16("emoji 😀"), "emoji 😀"},
+		{"unpaired surrogate", []byte{0x00, 0xd8, 'a', 0, 0, 0}, "�a"},
+		{"not terminated", []byte{'a', 0, 'b', 0}, "ab"},
+		{"stops at zero", []byte{'a', 0, 0, 0, 'b', 0}, "a"},
+		{"odd byte", []byte{'a', 0, 'b'}, "a"},
+	}
+	for _, tt := range tests {
+		if got := decodeUTF16(tt.data); got != tt.want {
+			t.Errorf("%s: decodeUTF16() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func BenchmarkDecodeUTF16(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		data []byte
+	}{
+		{"ascii", encodeUTF16("Microsoft® Windows® Operating System")},
+		{"cyrillic", encodeUTF16("Операционная система Microsoft® Windows®")},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				decodeUTF16(bm.data)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+//go:build go1.18
+// +build go1.18
+
+package fileversion_test
+
+import (
+	"testing"
+
+	"github.com/bi-zone/go-fileversion"
+	"github.com/bi-zone/go-fileversion/fileversiontest"
+)
+
+// fuzzResources are the seed resources of the fuzz targets.
+func fuzzResources() []fileversiontest.Resource {
+	english := fileversion.Locale{LangID: fileversion.LangEnglishUS, CharsetID: fileversion.CSUnicode}
+	return []fileversiontest.Resource{
+		{},
+		{NoFixedInfo: true, Translations: []fileversion.Locale{}},
+		{
+			Fixed: fileversion.FixedFileInfo{FileVersion: fileversion.FileVersion{Major: 10, Build: 19041, Patch: 1}},
+			StringTables: []fileversiontest.StringTable{{Locale: english, Strings: []fileversiontest.String{
+				{Name: "CompanyName", Value: "Contoso Ltd."},
+				{Name: "FileVersion", Value: "10.0.19041.1"},
+				{Name: "Comments", Value: ""},
+			}}},
+		},
+		{Translations: []fileversion.Locale{english, {LangID: 0x0419, CharsetID: fileversion.CSUnicode}}},
+	}
+}
+
+func FuzzParseVersionBlock(f *testing.F) {
+	for _, r := range fuzzResources() {
+		block := r.Block()
+		f.Add(block)
+		f.Add(block[:len(block)/2])
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		root, err := fileversion.ParseVersionBlock(data)
+		if err != nil {
+			return
+		}
+		var visit func(b fileversion.VersionBlock, depth int)
+		visit = func(b fileversion.VersionBlock, depth int) {
+			if depth > len(data) {
+				t.Fatalf("block tree is deeper than the data length %d", len(data))
+			}
+			_ = b.Text()
+			for _, c := range b.Children {
+				visit(c, depth+1)
+			}
+		}
+		visit(root, 0)
+	})
+}
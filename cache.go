@@ -0,0 +1,142 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"container/list"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnableGlobalCache enables a process-wide cache of raw version-information
+// resources used by New and NewWithLocale. Files are identified by their
+// path, modification time and size, so replaced files are re-read. The cache
+// keeps at most maxEntries resources evicting the least recently used ones.
+//
+// The cache is safe for concurrent use. EnableGlobalCache(0) disables and
+// drops the cache.
+func EnableGlobalCache(maxEntries int) {
+	globalCache.mu.Lock()
+	defer globalCache.mu.Unlock()
+	globalCache.max = maxEntries
+	if maxEntries <= 0 {
+		globalCache.entries = nil
+		globalCache.order = nil
+		return
+	}
+	if globalCache.entries == nil {
+		globalCache.entries = make(map[cacheKey]*list.Element)
+		globalCache.order = list.New()
+	}
+	globalCache.evict()
+}
+
+//nolint:gochecknoglobals
+var globalCache infoCache
+
+type cacheKey struct {
+	path    string
+	modTime time.Time
+	size    int64
+	flags   VersionInfoFlags
+}
+
+type cacheEntry struct {
+	key  cacheKey
+	data []byte
+}
+
+type infoCache struct {
+	mu      sync.Mutex
+	max     int
+	entries map[cacheKey]*list.Element
+	order   *list.List
+}
+
+// newCacheKey returns a key for the file or false if the cache is disabled or
+// the file can't be stat-ed.
+func newCacheKey(path string, flags VersionInfoFlags) (cacheKey, bool) {
+	globalCache.mu.Lock()
+	enabled := globalCache.max > 0
+	globalCache.mu.Unlock()
+	if !enabled {
+		return cacheKey{}, false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return cacheKey{}, false
+	}
+	stat, err := os.Stat(abs)
+	if err != nil {
+		return cacheKey{}, false
+	}
+	return cacheKey{
+		// Windows paths are case-insensitive.
+		path:    strings.ToLower(abs),
+		modTime: stat.ModTime(),
+		size:    stat.Size(),
+		flags:   flags,
+	}, true
+}
+
+func (c *infoCache) get(key cacheKey) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*cacheEntry).data, true
+}
+
+func (c *infoCache) put(key cacheKey, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.max <= 0 {
+		return
+	}
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*cacheEntry).data = data
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, data: data})
+	c.evict()
+}
+
+// evict drops the least recently used entries exceeding the limit.
+func (c *infoCache) evict() {
+	for c.order.Len() > c.max {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.entries, e.Value.(*cacheEntry).key)
+	}
+}
+
+// readVersionInfo reads the raw resource using the global cache if enabled.
+// The cached data is shared between Info values, it's never modified.
+func readVersionInfo(path string, o options) (Info, error) {
+	key, cacheable := newCacheKey(path, o.flags)
+	if cacheable {
+		if data, ok := globalCache.get(key); ok {
+			// The entry may be cached by a call with a looser limit.
+			if err := o.resourceTooLarge("GetFileVersionInfoSizeEx", path, uint64(len(data))); err != nil {
+				return Info{}, err
+			}
+			return newInfo(path, data), nil
+		}
+	}
+	info, err := newWithoutLocale(path, o)
+	if err != nil {
+		return Info{}, err
+	}
+	if cacheable {
+		globalCache.put(key, info.data)
+	}
+	return info, nil
+}
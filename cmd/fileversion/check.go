@@ -0,0 +1,96 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/bi-zone/go-fileversion"
+)
+
+// errCheckFailed is returned when the version doesn't satisfy the check.
+var errCheckFailed = errors.New("version check failed") //nolint:gochecknoglobals
+
+// Exit statuses of check besides 1 for the failed check, so scripts can tell
+// "too old" from "couldn't read".
+const (
+	checkUsageStatus = 2
+	checkReadStatus  = 3
+)
+
+func runCheck(args []string) error {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	minVersion := flags.String("min", "", "minimal accepted version, inclusive")
+	maxVersion := flags.String("max", "", "first rejected version, exclusive")
+	constraint := flags.String("version", "", `version constraint, e.g. ">=10.0, !=10.0.19041.1"`)
+	product := flags.Bool("product", false, "check the binary product version instead of the file one")
+	property := flags.String("property", "", "print the version info property, e.g. CompanyName")
+	quiet := flags.Bool("q", false, "don't print the version")
+	files := parseInterspersed(flags, args)
+	if len(files) != 1 {
+		return statusError{checkUsageStatus, errors.New("check: a single file required")}
+	}
+
+	var conditions []string
+	if *minVersion != "" {
+		conditions = append(conditions, ">="+*minVersion)
+	}
+	if *maxVersion != "" {
+		conditions = append(conditions, "<"+*maxVersion)
+	}
+	if *constraint != "" {
+		conditions = append(conditions, *constraint)
+	}
+	c, err := fileversion.ParseVersionConstraint(strings.Join(conditions, ","))
+	if err != nil {
+		return statusError{checkUsageStatus, err}
+	}
+
+	info, err := fileversion.New(files[0])
+	if err != nil {
+		return statusError{checkReadStatus, err}
+	}
+	if *property != "" {
+		value, err := info.GetProperty(*property)
+		if err != nil {
+			return statusError{checkReadStatus, err}
+		}
+		fmt.Println(value)
+	}
+	version := info.FixedInfo().FileVersion
+	if *product {
+		version = info.FixedInfo().ProductVersion
+	}
+	if !*quiet && *property == "" {
+		fmt.Println(formatVersion(version))
+	}
+	if !c.Match(version) {
+		return fmt.Errorf("%w: %s", errCheckFailed, formatVersion(version))
+	}
+	return nil
+}
+
+// parseInterspersed parses the flags placed both before and after the
+// positional arguments, e.g. `check kernel32.dll -min 10.0`, and returns the
+// positional ones. flag.FlagSet stops at the first positional argument, so
+// the rest is parsed again after it; "--" ends the flags as usual.
+func parseInterspersed(flags *flag.FlagSet, args []string) []string {
+	var positional []string
+	for len(args) != 0 {
+		flags.Parse(args) //nolint:errcheck
+		rest := flags.Args()
+		if parsed := len(args) - len(rest); parsed > 0 && args[parsed-1] == "--" {
+			return append(positional, rest...)
+		}
+		if len(rest) == 0 {
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+	return positional
+}
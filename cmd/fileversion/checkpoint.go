@@ -0,0 +1,112 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// checkpoint is the scan progress: the roots of the scan, the index of the
+// root being walked and the last scanned file in it. The walk order is
+// deterministic (os.ReadDir sorts the names), so the resumed scan skips all
+// the paths up to Last.
+type checkpoint struct {
+	Roots []string `json:"roots"`
+	Root  int      `json:"root"`
+	Last  string   `json:"last"`
+}
+
+// checkpointStore persists checkpoints. Load returns false if there is no
+// checkpoint to resume.
+type checkpointStore interface {
+	Load() (checkpoint, bool, error)
+	Save(c checkpoint) error
+	Clear() error
+}
+
+// nopCheckpointStore is used when no checkpoint is requested.
+type nopCheckpointStore struct{}
+
+func (nopCheckpointStore) Load() (checkpoint, bool, error) { return checkpoint{}, false, nil }
+func (nopCheckpointStore) Save(checkpoint) error           { return nil }
+func (nopCheckpointStore) Clear() error                    { return nil }
+
+// fileCheckpointStore keeps the checkpoint in a JSON file.
+type fileCheckpointStore struct {
+	path string
+}
+
+func (s fileCheckpointStore) Load() (checkpoint, bool, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint{}, false, nil
+	}
+	if err != nil {
+		return checkpoint{}, false, err
+	}
+	var c checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return checkpoint{}, false, fmt.Errorf("failed to parse checkpoint %q: %w", s.path, err)
+	}
+	return c, true, nil
+}
+
+// Save replaces the file atomically, so a crash during the write keeps the
+// previous checkpoint.
+func (s fileCheckpointStore) Save(c checkpoint) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s fileCheckpointStore) Clear() error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// resumePoint loads the checkpoint of a scan of the same roots. A checkpoint
+// of other roots is an error rather than silently ignored.
+func resumePoint(store checkpointStore, roots []string) (checkpoint, error) {
+	c, ok, err := store.Load()
+	if err != nil || !ok {
+		return checkpoint{Roots: roots}, err
+	}
+	if !reflect.DeepEqual(c.Roots, roots) || c.Root < 0 || c.Root >= len(roots) {
+		return checkpoint{}, fmt.Errorf("checkpoint is of a scan of %s", strings.Join(c.Roots, ", "))
+	}
+	return c, nil
+}
+
+// comparePaths compares the paths element by element, which is the order
+// the walker visits them in.
+func comparePaths(a, b string) int {
+	as := strings.Split(filepath.Clean(a), string(filepath.Separator))
+	bs := strings.Split(filepath.Clean(b), string(filepath.Separator))
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if c := strings.Compare(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	return len(as) - len(bs)
+}
+
+// isWithin reports whether the path is the dir or is inside it.
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,164 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bi-zone/go-fileversion"
+)
+
+// diffEntry is a versioned file of one side of the diff.
+type diffEntry struct {
+	key      string
+	path     string
+	original string
+	version  fileversion.FileVersion
+}
+
+func runDiff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	unchanged := flags.Bool("unchanged", false, "also list unchanged files")
+	flags.Parse(args) //nolint:errcheck
+	if flags.NArg() != 2 {
+		return errors.New("diff: two directories or file lists required")
+	}
+	a, err := loadDiffSide(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	b, err := loadDiffSide(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	pairs, removed, added := pairEntries(a, b)
+	for _, p := range pairs {
+		x, y := p[0].version.Uint64(), p[1].version.Uint64()
+		switch {
+		case x < y:
+			fmt.Printf("upgraded    %s  %s -> %s\n", p[1].key, formatVersion(p[0].version), formatVersion(p[1].version))
+		case x > y:
+			fmt.Printf("downgraded  %s  %s -> %s\n", p[1].key, formatVersion(p[0].version), formatVersion(p[1].version))
+		case *unchanged:
+			fmt.Printf("unchanged   %s  %s\n", p[1].key, formatVersion(p[1].version))
+		}
+	}
+	for _, e := range removed {
+		fmt.Printf("removed     %s  %s\n", e.key, formatVersion(e.version))
+	}
+	for _, e := range added {
+		fmt.Printf("added       %s  %s\n", e.key, formatVersion(e.version))
+	}
+	return nil
+}
+
+// loadDiffSide reads the images of a directory (keyed by the relative path)
+// or of a file listing one path per line (keyed by the file name).
+func loadDiffSide(path string) ([]diffEntry, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []diffEntry
+	add := func(key, file string) {
+		info, err := fileversion.New(file)
+		if err != nil {
+			return
+		}
+		entries = append(entries, diffEntry{
+			key:      strings.ToLower(key),
+			path:     file,
+			original: strings.ToLower(strings.TrimSpace(info.OriginalFilename())),
+			version:  info.FixedInfo().FileVersion,
+		})
+	}
+	if fi.IsDir() {
+		err := filepath.Walk(path, func(file string, fi os.FileInfo, err error) error {
+			if err != nil || fi.IsDir() || !fileversion.HasPEExtension(file) {
+				return nil
+			}
+			rel, err := filepath.Rel(path, file)
+			if err != nil {
+				return err
+			}
+			add(rel, file)
+			return nil
+		})
+		return entries, err
+	}
+
+	list, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer list.Close()
+	scanner := bufio.NewScanner(list)
+	for scanner.Scan() {
+		if file := strings.TrimSpace(scanner.Text()); file != "" {
+			add(filepath.Base(file), file)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// pairEntries pairs the files by key and then the rest by OriginalFilename.
+// The results are sorted by key.
+func pairEntries(a, b []diffEntry) ([][2]diffEntry, []diffEntry, []diffEntry) {
+	byKey := make(map[string]int, len(b))
+	for i, e := range b {
+		byKey[e.key] = i
+	}
+	used := make([]bool, len(b))
+	var pairs [][2]diffEntry
+	var rest []diffEntry
+	for _, e := range a {
+		if i, ok := byKey[e.key]; ok && !used[i] {
+			used[i] = true
+			pairs = append(pairs, [2]diffEntry{e, b[i]})
+			continue
+		}
+		rest = append(rest, e)
+	}
+
+	byOriginal := make(map[string]int)
+	for i, e := range b {
+		if !used[i] && e.original != "" {
+			byOriginal[e.original] = i
+		}
+	}
+	var removed []diffEntry
+	for _, e := range rest {
+		if i, ok := byOriginal[e.original]; ok && e.original != "" && !used[i] {
+			used[i] = true
+			pairs = append(pairs, [2]diffEntry{e, b[i]})
+			continue
+		}
+		removed = append(removed, e)
+	}
+	var added []diffEntry
+	for i, e := range b {
+		if !used[i] {
+			added = append(added, e)
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i][1].key < pairs[j][1].key })
+	sort.Slice(removed, func(i, j int) bool { return removed[i].key < removed[j].key })
+	sort.Slice(added, func(i, j int) bool { return added[i].key < added[j].key })
+	return pairs, removed, added
+}
+
+// formatVersion formats the version in the Major.Minor.Build.Revision order.
+func formatVersion(v fileversion.FileVersion) string {
+	text, _ := v.MarshalText()
+	return string(text)
+}
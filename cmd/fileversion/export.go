@@ -0,0 +1,181 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// tableWriter writes scan records as rows of a spreadsheet.
+type tableWriter interface {
+	WriteRow(row []string) error
+	Close() error
+}
+
+// exportColumns are the column keys of the CSV and XLSX exports.
+//
+//nolint:gochecknoglobals
+var exportColumns = []string{
+	"path", "status", "company", "product", "productVersion", "description",
+	"fileVersion", "originalFilename", "signed", "error", "reason", "durationMs", "sha256",
+}
+
+// exportHeaders are the localized column headers selected by scan -lang.
+//
+//nolint:gochecknoglobals
+var exportHeaders = map[string]map[string]string{
+	"en": {
+		"path": "Path", "company": "Company", "product": "Product", "productVersion": "Product version",
+		"description": "Description", "fileVersion": "File version", "originalFilename": "Original filename",
+		"signed": "Signed", "error": "Error", "status": "Status", "reason": "Reason", "durationMs": "Duration, ms", "sha256": "SHA-256",
+	},
+	"de": {
+		"path": "Pfad", "company": "Firma", "product": "Produkt", "productVersion": "Produktversion",
+		"description": "Beschreibung", "fileVersion": "Dateiversion", "originalFilename": "Ursprünglicher Dateiname",
+		"signed": "Signiert", "error": "Fehler", "status": "Status", "reason": "Grund", "durationMs": "Dauer, ms", "sha256": "SHA-256",
+	},
+	"fr": {
+		"path": "Chemin", "company": "Société", "product": "Produit", "productVersion": "Version du produit",
+		"description": "Description", "fileVersion": "Version du fichier", "originalFilename": "Nom de fichier d'origine",
+		"signed": "Signé", "error": "Erreur", "status": "État", "reason": "Motif", "durationMs": "Durée, ms", "sha256": "SHA-256",
+	},
+	"ru": {
+		"path": "Путь", "company": "Организация", "product": "Продукт", "productVersion": "Версия продукта",
+		"description": "Описание", "fileVersion": "Версия файла", "originalFilename": "Исходное имя файла",
+		"signed": "Подписан", "error": "Ошибка", "status": "Статус", "reason": "Причина", "durationMs": "Время, мс", "sha256": "SHA-256",
+	},
+}
+
+// exportHeader returns the header row in the language.
+func exportHeader(lang string) ([]string, error) {
+	headers, ok := exportHeaders[lang]
+	if !ok {
+		return nil, fmt.Errorf("unsupported export language %q", lang)
+	}
+	row := make([]string, len(exportColumns))
+	for i, column := range exportColumns {
+		row[i] = headers[column]
+	}
+	return row, nil
+}
+
+// exportSelection parses scan -columns into the indexes of exportColumns. An
+// empty list selects all the columns.
+func exportSelection(list string) ([]int, error) {
+	if list == "" {
+		return nil, nil
+	}
+	var selected []int
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		i := 0
+		for i < len(exportColumns) && !strings.EqualFold(exportColumns[i], name) {
+			i++
+		}
+		if i == len(exportColumns) {
+			return nil, fmt.Errorf("unknown column %q, expected one of %s", name, strings.Join(exportColumns, ", "))
+		}
+		selected = append(selected, i)
+	}
+	return selected, nil
+}
+
+// selectColumns returns the selected cells of the row in the selection order.
+func selectColumns(row []string, selected []int) []string {
+	if selected == nil {
+		return row
+	}
+	cells := make([]string, len(selected))
+	for i, column := range selected {
+		cells[i] = row[column]
+	}
+	return cells
+}
+
+// exportRow converts the record to the row of exportColumns.
+func exportRow(record scanRecord) []string {
+	row := make([]string, len(exportColumns))
+	row[0] = record.Path
+	row[1] = record.Status
+	if info := record.Info; info != nil {
+		row[2] = info.CompanyName
+		row[3] = info.ProductName
+		row[4] = info.ProductVersion
+		row[5] = info.FileDescription
+		row[6] = info.FileVersionRaw
+		row[7] = info.OriginalFilename
+	}
+	if record.Signed != nil {
+		row[8] = strconv.FormatBool(*record.Signed)
+	}
+	row[9] = record.Error
+	row[10] = record.Reason
+	row[11] = strconv.FormatFloat(record.DurationMs, 'f', 3, 64)
+	if record.Hashes != nil {
+		row[12] = record.Hashes.SHA256
+	}
+	return row
+}
+
+// csvWriter writes UTF-8 CSV Excel opens as is: with the BOM (without it
+// Excel assumes the ANSI code page), CRLF line ends and the cells Excel would
+// evaluate as formulas escaped.
+type csvWriter struct {
+	file *os.File
+	buf  *bufio.Writer
+	csv  *csv.Writer
+}
+
+func newCSVWriter(path string, separator rune) (*csvWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(file)
+	if _, err := io.WriteString(buf, "\ufeff"); err != nil {
+		file.Close()
+		return nil, err
+	}
+	w := csv.NewWriter(buf)
+	w.Comma = separator
+	w.UseCRLF = true
+	return &csvWriter{file: file, buf: buf, csv: w}, nil
+}
+
+func (w *csvWriter) WriteRow(row []string) error {
+	escaped := make([]string, len(row))
+	for i, cell := range row {
+		escaped[i] = escapeFormula(cell)
+	}
+	return w.csv.Write(escaped)
+}
+
+func (w *csvWriter) Close() error {
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		w.file.Close()
+		return err
+	}
+	if err := w.buf.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// escapeFormula prefixes the cells starting with the formula characters with
+// an apostrophe, so that Excel shows them as text. Version info strings are
+// attacker-controlled, so the exports must not be able to run formulas.
+func escapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
@@ -0,0 +1,146 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"crypto/md5"  //nolint:gosec
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+)
+
+// fileHashes are the hashes of a scanned image added by scan -hash. MD5 and
+// SHA-1 are weak, they are only computed for the lookups in the threat
+// intelligence feeds still keyed by them.
+type fileHashes struct {
+	MD5    string `json:"md5"`
+	SHA1   string `json:"sha1"`
+	SHA256 string `json:"sha256"`
+	// Authentihash is the SHA-256 Authenticode hash: the one signatures and
+	// catalogs sign, so it stays the same when the file is re-signed.
+	Authentihash string `json:"authentihash,omitempty"`
+}
+
+// hashFile computes all the hashes in a single read of the file. Only the
+// headers are read beforehand to locate the ranges Authenticode skips.
+func hashFile(path string) (*fileHashes, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	md5Hash, sha1Hash, sha256Hash := md5.New(), sha1.New(), sha256.New() //nolint:gosec
+	writers := []io.Writer{md5Hash, sha1Hash, sha256Hash}
+	var authenticode *skipWriter
+	if skip, ok := authenticodeExclusions(file); ok {
+		authenticode = &skipWriter{hash: sha256.New(), skip: skip}
+		writers = append(writers, authenticode)
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), file); err != nil {
+		return nil, err
+	}
+	hashes := &fileHashes{
+		MD5:    hex.EncodeToString(md5Hash.Sum(nil)),
+		SHA1:   hex.EncodeToString(sha1Hash.Sum(nil)),
+		SHA256: hex.EncodeToString(sha256Hash.Sum(nil)),
+	}
+	if authenticode != nil {
+		hashes.Authentihash = hex.EncodeToString(authenticode.hash.Sum(nil))
+	}
+	return hashes, nil
+}
+
+// byteRange is a range of file offsets.
+type byteRange struct {
+	offset int64
+	size   int64
+}
+
+// authenticodeExclusions returns the ranges the Authenticode hash skips: the
+// optional header CheckSum, the certificate table directory entry and the
+// certificate table itself. Source:
+// https://learn.microsoft.com/en-us/windows/win32/debug/pe-format#process-for-generating-the-authenticode-pe-image-hash
+func authenticodeExclusions(r io.ReaderAt) ([]byteRange, bool) {
+	var dos [64]byte
+	if _, err := r.ReadAt(dos[:], 0); err != nil || dos[0] != 'M' || dos[1] != 'Z' {
+		return nil, false
+	}
+	ntHeaders := int64(binary.LittleEndian.Uint32(dos[0x3c:]))
+	// The signature, the file header and the optional header magic.
+	var header [4 + 20 + 2]byte
+	if _, err := r.ReadAt(header[:], ntHeaders); err != nil || string(header[:4]) != "PE\x00\x00" {
+		return nil, false
+	}
+	optionalHeader := ntHeaders + 4 + 20
+	var directories int64
+	switch binary.LittleEndian.Uint16(header[24:]) {
+	case 0x10b: // PE32
+		directories = optionalHeader + 96
+	case 0x20b: // PE32+
+		directories = optionalHeader + 112
+	default:
+		return nil, false
+	}
+	skip := []byteRange{{offset: optionalHeader + 64, size: 4}}
+
+	// NumberOfRvaAndSizes precedes the directories, the certificate table
+	// is the fifth one.
+	const certificateTable = 4
+	var count [4]byte
+	if _, err := r.ReadAt(count[:], directories-4); err != nil {
+		return nil, false
+	}
+	if binary.LittleEndian.Uint32(count[:]) <= certificateTable {
+		return skip, true
+	}
+	entryOffset := directories + certificateTable*8
+	var entry [8]byte
+	if _, err := r.ReadAt(entry[:], entryOffset); err != nil {
+		return nil, false
+	}
+	skip = append(skip, byteRange{offset: entryOffset, size: 8})
+	// Unlike the other directories the certificate table address is a file
+	// offset.
+	if size := binary.LittleEndian.Uint32(entry[4:]); size != 0 {
+		skip = append(skip, byteRange{offset: int64(binary.LittleEndian.Uint32(entry[:4])), size: int64(size)})
+	}
+	return skip, true
+}
+
+// skipWriter hashes the stream written into it except the skipped ranges.
+type skipWriter struct {
+	hash hash.Hash
+	skip []byteRange
+	pos  int64
+}
+
+func (w *skipWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		// The chunk ends at the nearest boundary of a skipped range.
+		chunk := int64(len(p))
+		skipped := false
+		for _, r := range w.skip {
+			if end := r.offset + r.size; w.pos >= r.offset && w.pos < end {
+				skipped = true
+				if end-w.pos < chunk {
+					chunk = end - w.pos
+				}
+			} else if r.offset > w.pos && r.offset-w.pos < chunk {
+				chunk = r.offset - w.pos
+			}
+		}
+		if !skipped {
+			w.hash.Write(p[:chunk]) //nolint:errcheck
+		}
+		w.pos += chunk
+		p = p[chunk:]
+	}
+	return n, nil
+}
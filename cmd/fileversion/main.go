@@ -0,0 +1,102 @@
+//go:build windows
+// +build windows
+
+// Command fileversion is a command-line inventory tool built on the
+// fileversion package.
+//
+//	fileversion scan [-r] [-json out.ndjson] [-csv out.csv] [-xlsx out.xlsx] [-lang en] [-columns list] [-include glob] [-exclude glob] [-sniff] [-follow] [-dedupe=false] [-ads] [-hash] [-workers n] [-timeout d] [-max-size bytes] [-stats file] [-checkpoint file] [-usn state.json] [-sign=false] [-top n] <dir>...
+//	fileversion diff [-unchanged] <dirA|listA> <dirB|listB>
+//	fileversion check [-min v] [-max v] [-version constraint] [-product] [-property name] [-q] <file>
+//
+// scan reads version info of the PE images (judged by extension, see
+// fileversion.PEExtensions, or by the repeatable -include globs) in the
+// directories, skipping the files and directories matching -exclude. -sniff
+// also checks the MZ and PE signatures of the other files to find renamed
+// images. It optionally writes one JSON record per file or a table for Excel,
+// and prints summary statistics. The CSV is UTF-8 with the BOM and escaped
+// formulas, -sep sets the separator and -lang the language of the headers,
+// -columns selects and orders the table columns. The XLSX writer is built with
+// -tags xlsx. Symlinks and junctions are skipped unless -follow is given,
+// directory cycles are detected by the file IDs, and hard links of a file are
+// scanned once unless -dedupe=false is given. -ads also scans the PE images
+// hidden in alternate data streams of the files. -hash adds MD5, SHA-1,
+// SHA-256 and the Authenticode hash of the images to the records, computed in
+// one read of each file. -workers sets the number of files read in parallel
+// (the number of CPUs by default), the walk pauses while the workers or the
+// output fall behind. -timeout gives up on the files not read in time, e.g. on
+// a hung network share, and -max-size skips the larger files. -stats writes
+// the summary counters and the throughput as JSON for monitoring. -checkpoint
+// saves the progress periodically and resumes an interrupted scan of the same
+// directories, appending to the -json output; the summary and the tables cover
+// only the resumed part. -usn makes the scans incremental: the first one scans
+// the directories fully and saves the NTFS change journal positions to the
+// file, the next ones scan only the images created or changed since and report
+// the deleted ones (reading the journal requires administrator rights).
+//
+// diff pairs the images of two directories by the relative path (or of two
+// files listing one path per line by the file name), then the rest by
+// OriginalFilename, and reports upgrades, downgrades, removals and additions
+// of the binary file versions.
+//
+// check prints the binary file version (or the property) of a single file and
+// exits with status 1 if the version doesn't satisfy the constraints, e.g.
+// `fileversion check -q -min 10.0.19041.0 kernel32.dll` (or
+// `fileversion check kernel32.dll -min 10.0.19041.0`) gates installers on
+// DLL versions. Usage errors exit with status 2 and errors reading the file
+// or the property exit with status 3.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+const usage = `usage: fileversion <command> [arguments]
+
+commands:
+  scan   read version info of the images in directories
+  diff   compare file versions of two directories or file lists
+  check  check the file version of a single file against constraints
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "scan":
+		err = runScan(os.Args[2:])
+	case "diff":
+		err = runDiff(os.Args[2:])
+	case "check":
+		err = runCheck(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fileversion:", err)
+		var status statusError
+		if errors.As(err, &status) {
+			os.Exit(status.status)
+		}
+		os.Exit(1)
+	}
+}
+
+// statusError makes the command exit with the status instead of 1.
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e statusError) Error() string {
+	return e.err.Error()
+}
+
+func (e statusError) Unwrap() error {
+	return e.err
+}
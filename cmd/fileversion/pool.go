@@ -0,0 +1,122 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bi-zone/go-fileversion"
+)
+
+// scanBacklog is the number of queued files per worker. The queue is
+// bounded, so a slow consumer (a network share for -json, say) pauses the
+// walk instead of buffering millions of paths.
+const scanBacklog = 4
+
+// errScanStopped stops the walk after the consumer failed.
+var errScanStopped = errors.New("scan stopped") //nolint:gochecknoglobals
+
+// scanJob is a file handed from the walker to the workers.
+type scanJob struct {
+	path string
+	// root is the index of the walked root, saved to the checkpoint.
+	root int
+	// deleted marks a file removed according to the change journal, there
+	// is nothing to scan.
+	deleted bool
+	results chan []scanResult
+}
+
+// scanResult is the record of a scanned file or stream and the version
+// info read, if any.
+type scanResult struct {
+	record scanRecord
+	info   *fileversion.Info
+}
+
+// runPipeline scans the files produce submits on the worker goroutines and
+// passes the results to consume in the submission order, so the records and
+// the checkpoints are the same as of a sequential scan. submit blocks while
+// the queue is full and returns false once consume failed.
+func runPipeline(workers int, produce func(submit func(scanJob) bool) error,
+	scan func(scanJob) []scanResult, consume func(scanJob, []scanResult) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan scanJob, workers*scanBacklog)
+	pending := make(chan scanJob, workers*scanBacklog)
+	stop := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job.results <- scan(job)
+			}
+		}()
+	}
+	produced := make(chan error, 1)
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+		produced <- produce(func(job scanJob) bool {
+			job.results = make(chan []scanResult, 1)
+			select {
+			case pending <- job:
+			case <-stop:
+				return false
+			}
+			// The workers take the jobs in the order the consumer waits
+			// for them, so this never blocks for long.
+			jobs <- job
+			return true
+		})
+	}()
+
+	var err error
+	for job := range pending {
+		results := <-job.results
+		if err != nil {
+			continue
+		}
+		if err = consume(job, results); err != nil {
+			close(stop)
+		}
+	}
+	wg.Wait()
+	if walkErr := <-produced; err == nil && !errors.Is(walkErr, errScanStopped) {
+		err = walkErr
+	}
+	return err
+}
+
+// scanWithTimeout is scanFile giving up after the timeout. Reading the
+// version info can't be cancelled, so a file stuck on a dead network share
+// keeps its goroutine until the read fails, but the scan moves on.
+func scanWithTimeout(path string, opts scanOptions) scanResult {
+	if opts.timeout <= 0 {
+		return scanFile(path, opts)
+	}
+	done := make(chan scanResult, 1)
+	go func() { done <- scanFile(path, opts) }()
+	timer := time.NewTimer(opts.timeout)
+	defer timer.Stop()
+	select {
+	case result := <-done:
+		return result
+	case <-timer.C:
+		return scanResult{record: scanRecord{
+			Path:       path,
+			Status:     scanFailed,
+			Reason:     "timeout",
+			Error:      fmt.Sprintf("not read in %s", opts.timeout),
+			DurationMs: float64(opts.timeout.Microseconds()) / 1000,
+		}}
+	}
+}
@@ -0,0 +1,555 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/bi-zone/go-fileversion"
+	"golang.org/x/sys/windows"
+)
+
+// scanRecord is a single NDJSON line written by scan -json.
+type scanRecord struct {
+	Path string `json:"path"`
+	// Status is one of scanOK, scanSkipped and scanFailed, Reason classifies
+	// the skipped and the failed files for tuning the exclusions.
+	Status string                  `json:"status"`
+	Reason string                  `json:"reason,omitempty"`
+	Info   *fileversion.FormatData `json:"info,omitempty"`
+	Signed *bool                   `json:"signed,omitempty"`
+	Hashes *fileHashes             `json:"hashes,omitempty"`
+	Error  string                  `json:"error,omitempty"`
+	// DurationMs is the time of reading the version info and the signature.
+	DurationMs float64 `json:"durationMs"`
+}
+
+// Scan record statuses: skipped files are valid files without version
+// info, failed ones couldn't be read. Deleted files are reported by the
+// incremental scans.
+const (
+	scanOK      = "ok"
+	scanSkipped = "skipped"
+	scanFailed  = "failed"
+	scanDeleted = "deleted"
+)
+
+// checkpointInterval is the period of saving scan -checkpoint progress.
+const checkpointInterval = 10 * time.Second
+
+type scanStats struct {
+	files    int
+	images   int
+	failed   int
+	signed   int
+	unsigned int
+
+	reparsePoints int
+	cycles        int
+	duplicates    int
+	excluded      int
+	// sniffed counts the images found by the content despite the name.
+	sniffed int
+	// reasons counts the skipped and the failed files by the reason.
+	reasons map[string]int
+}
+
+func runScan(args []string) error {
+	flags := flag.NewFlagSet("scan", flag.ExitOnError)
+	recursive := flags.Bool("r", false, "scan subdirectories")
+	jsonPath := flags.String("json", "", "write NDJSON records to the file (- for stdout)")
+	sign := flags.Bool("sign", true, "check Authenticode signatures")
+	top := flags.Int("top", 10, "number of top products in the summary")
+	csvPath := flags.String("csv", "", "write an Excel-compatible CSV table to the file")
+	separator := flags.String("sep", ",", "CSV separator, Excel of some locales expects ;")
+	xlsxPath := flags.String("xlsx", "", "write an XLSX table to the file (requires -tags xlsx)")
+	lang := flags.String("lang", "en", "language of the table headers: en, de, fr or ru")
+	columns := flags.String("columns", "", "comma-separated columns of the tables, e.g. path,fileVersion (all by default)")
+	follow := flags.Bool("follow", false, "follow symlinks and junctions, directory cycles are skipped")
+	dedupe := flags.Bool("dedupe", true, "scan hard links of a file once")
+	checkpointPath := flags.String("checkpoint", "", "save the progress to the file and resume from it after a restart")
+	usnPath := flags.String("usn", "", "scan only the files changed since the journal positions saved in the file")
+	var include, exclude patternsFlag
+	flags.Var(&include, "include", "scan only the files matching the glob, e.g. *.sys, instead of the PE extensions; may be repeated")
+	flags.Var(&exclude, "exclude", "skip the files and directories matching the glob, e.g. WinSxS; may be repeated")
+	sniff := flags.Bool("sniff", false, "also check the content of the other files to find renamed images")
+	ads := flags.Bool("ads", false, "also scan PE images hidden in alternate data streams")
+	statsPath := flags.String("stats", "", "write the summary counters as JSON to the file for monitoring")
+	hashes := flags.Bool("hash", false, "compute MD5, SHA-1, SHA-256 and Authenticode hashes of the images")
+	workers := flags.Int("workers", runtime.NumCPU(), "number of files read in parallel")
+	timeout := flags.Duration("timeout", 0, "give up reading a file after the duration, e.g. 30s (no limit by default)")
+	maxSize := flags.Int64("max-size", 0, "skip the files larger than the number of bytes (no limit by default)")
+	flags.Parse(args) //nolint:errcheck
+	if flags.NArg() == 0 {
+		return errors.New("scan: no directories given")
+	}
+
+	var store checkpointStore = nopCheckpointStore{}
+	if *checkpointPath != "" {
+		store = fileCheckpointStore{path: *checkpointPath}
+	}
+	resume, err := resumePoint(store, flags.Args())
+	if err != nil {
+		return err
+	}
+
+	var changes []fileversion.UsnChange
+	var positions []fileversion.UsnPosition
+	incremental := false
+	if *usnPath != "" {
+		// The positions are taken before a full scan, so the changes made
+		// during it are picked up next time.
+		if changes, positions, incremental, err = journalChanges(*usnPath, flags.Args()); err != nil {
+			return err
+		}
+	}
+
+	summary := os.Stdout
+	var records *json.Encoder
+	switch *jsonPath {
+	case "":
+	case "-":
+		records = json.NewEncoder(os.Stdout)
+		summary = os.Stderr
+	default:
+		mode := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if resume.Last != "" {
+			// The records of the interrupted run are kept.
+			mode = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+		file, err := os.OpenFile(*jsonPath, mode, 0o666)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		records = json.NewEncoder(file)
+	}
+	selected, err := exportSelection(*columns)
+	if err != nil {
+		return err
+	}
+	tables, err := openTables(*csvPath, *separator, *xlsxPath, *lang, selected)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	stats := scanStats{reasons: make(map[string]int)}
+	inventory := fileversion.NewInventory()
+	// The walker counts the skipped directories on its own goroutine, the
+	// counters are merged after the scan.
+	var walked scanStats
+	var currentDir atomic.Value
+	currentDir.Store("")
+	w := newWalker(*recursive, *follow, *dedupe, &walked, func(dir string) { currentDir.Store(dir) })
+	w.include, w.exclude, w.sniff = include, exclude, *sniff
+	opts := scanOptions{sign: *sign, hashes: *hashes, timeout: *timeout, maxSize: *maxSize}
+	scan := func(job scanJob) []scanResult {
+		if job.deleted {
+			return []scanResult{{record: scanRecord{Path: job.path, Status: scanDeleted}}}
+		}
+		paths := []string{job.path}
+		if *ads {
+			paths = append(paths, streamImages(job.path)...)
+		}
+		results := make([]scanResult, 0, len(paths))
+		for _, path := range paths {
+			results = append(results, scanWithTimeout(path, opts))
+		}
+		return results
+	}
+	lastSave := time.Now()
+	lastDir := ""
+	consume := func(job scanJob, results []scanResult) error {
+		for _, result := range results {
+			stats.add(result.record)
+			if result.info != nil {
+				inventory.Add(*result.info)
+			}
+			for _, table := range tables {
+				if err := table.WriteRow(selectColumns(exportRow(result.record), selected)); err != nil {
+					return err
+				}
+			}
+			if records != nil {
+				if err := records.Encode(result.record); err != nil {
+					return err
+				}
+			}
+		}
+		if dir := currentDir.Load().(string); dir != lastDir {
+			progress(stats, dir, time.Since(start))
+			lastDir = dir
+		}
+		if !incremental && time.Since(lastSave) > checkpointInterval {
+			// job is the last fully scanned file, the checkpoint resumes
+			// after it.
+			if err := store.Save(checkpoint{Roots: resume.Roots, Root: job.root, Last: job.path}); err != nil {
+				return fmt.Errorf("failed to save checkpoint: %w", err)
+			}
+			lastSave = time.Now()
+		}
+		return nil
+	}
+
+	produce := func(submit func(scanJob) bool) error {
+		if incremental {
+			return submitChanges(changes, flags.Args(), *recursive, submit)
+		}
+		for i, root := range flags.Args() {
+			if i < resume.Root {
+				continue
+			}
+			if i == resume.Root {
+				w.resumeAfter = resume.Last
+			}
+			err := w.walk(root, func(path string) error {
+				if !submit(scanJob{path: path, root: i}) {
+					return errScanStopped
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	err = runPipeline(*workers, produce, scan, consume)
+	stats.merge(walked)
+	if err != nil {
+		closeTables(tables) //nolint:errcheck
+		return err
+	}
+	if err := closeTables(tables); err != nil {
+		return err
+	}
+	if err := store.Clear(); err != nil {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	if *usnPath != "" {
+		if err := saveJournalState(*usnPath, positions); err != nil {
+			return fmt.Errorf("failed to save journal state: %w", err)
+		}
+	}
+	elapsed := time.Since(start)
+	if *statsPath != "" {
+		if err := writeStats(*statsPath, stats, elapsed); err != nil {
+			return fmt.Errorf("failed to save stats: %w", err)
+		}
+	}
+	fmt.Fprint(os.Stderr, "\r\033[K")
+	printSummary(summary, stats, inventory, *sign, *top, elapsed)
+	return nil
+}
+
+// submitChanges submits the journal changes under the roots.
+func submitChanges(changes []fileversion.UsnChange, roots []string, recursive bool, submit func(scanJob) bool) error {
+	for _, change := range changes {
+		if !underRoots(change.Path, roots, recursive) {
+			continue
+		}
+		if !submit(scanJob{path: change.Path, deleted: change.Deleted}) {
+			return errScanStopped
+		}
+	}
+	return nil
+}
+
+// underRoots reports whether the path is a file the walk of the roots visits.
+func underRoots(path string, roots []string, recursive bool) bool {
+	for _, root := range roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		if recursive && isWithin(path, abs) || strings.EqualFold(filepath.Dir(path), abs) || strings.EqualFold(path, abs) {
+			return true
+		}
+	}
+	return false
+}
+
+// openTables creates the requested table exports and writes the headers.
+func openTables(csvPath, separator, xlsxPath, lang string, selected []int) ([]tableWriter, error) {
+	header, err := exportHeader(lang)
+	if err != nil {
+		return nil, err
+	}
+	sep := []rune(separator)
+	if len(sep) != 1 {
+		return nil, fmt.Errorf("invalid CSV separator %q", separator)
+	}
+	var tables []tableWriter
+	if csvPath != "" {
+		table, err := newCSVWriter(csvPath, sep[0])
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	if xlsxPath != "" {
+		table, err := newXLSXWriter(xlsxPath)
+		if err != nil {
+			closeTables(tables) //nolint:errcheck
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	for _, table := range tables {
+		if err := table.WriteRow(selectColumns(header, selected)); err != nil {
+			closeTables(tables) //nolint:errcheck
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+func closeTables(tables []tableWriter) error {
+	var first error
+	for _, table := range tables {
+		if err := table.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// streamImages returns the paths of the alternate data streams of the file
+// holding PE images.
+func streamImages(path string) []string {
+	streams, err := fileversion.AlternateStreams(path)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, s := range streams {
+		streamPath := path + ":" + s.Name
+		if ok, err := fileversion.IsPE(streamPath); err == nil && ok {
+			paths = append(paths, streamPath)
+		}
+	}
+	return paths
+}
+
+// scanOptions are the scan flags applied to every file.
+type scanOptions struct {
+	sign    bool
+	hashes  bool
+	timeout time.Duration
+	maxSize int64
+}
+
+// scanFile reads the file. It runs on the worker goroutines, the counters
+// are updated by the consumer of the result.
+func scanFile(path string, opts scanOptions) (result scanResult) {
+	start := time.Now()
+	record := &result.record
+	*record = scanRecord{Path: path, Status: scanOK}
+	defer func() {
+		record.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+	}()
+	if opts.maxSize > 0 {
+		if fi, err := os.Stat(path); err == nil && fi.Size() > opts.maxSize {
+			record.Status, record.Reason = scanSkipped, "too large"
+			record.Error = fmt.Sprintf("%d bytes exceed -max-size", fi.Size())
+			return result
+		}
+	}
+	info, err := fileversion.New(path)
+	if err != nil {
+		record.Status, record.Reason = classifyError(err)
+		record.Error = err.Error()
+		return result
+	}
+	result.info = &info
+	data := info.FormatData()
+	record.Info = &data
+	if opts.sign {
+		if signed, err := info.IsSigned(); err == nil {
+			record.Signed = &signed
+		}
+	}
+	if opts.hashes {
+		if record.Hashes, err = hashFile(path); err != nil {
+			record.Error = fmt.Sprintf("failed to hash: %v", err)
+		}
+	}
+	return result
+}
+
+// add counts the scan record.
+func (s *scanStats) add(record scanRecord) {
+	switch record.Status {
+	case scanDeleted:
+		s.reasons[scanDeleted]++
+		return
+	case scanOK:
+		s.images++
+	case scanFailed:
+		s.failed++
+	}
+	s.files++
+	if record.Status != scanOK {
+		s.reasons[record.Status+": "+record.Reason]++
+	}
+	if record.Signed != nil {
+		if *record.Signed {
+			s.signed++
+		} else {
+			s.unsigned++
+		}
+	}
+}
+
+// merge adds the walker counters to the stats.
+func (s *scanStats) merge(walked scanStats) {
+	s.failed += walked.failed
+	s.reparsePoints += walked.reparsePoints
+	s.cycles += walked.cycles
+	s.duplicates += walked.duplicates
+	s.excluded += walked.excluded
+	s.sniffed += walked.sniffed
+}
+
+// classifyError returns the status and the reason of a file New failed on.
+func classifyError(err error) (string, string) {
+	switch {
+	case errors.Is(err, fileversion.ErrNoVersionInfo):
+		return scanSkipped, "no version info"
+	case errors.Is(err, fileversion.ErrNotPE):
+		return scanSkipped, "not a PE image"
+	case errors.Is(err, fileversion.ErrUnsupportedFormat):
+		return scanSkipped, "unsupported format"
+	case errors.Is(err, fileversion.ErrResourceTooLarge):
+		return scanFailed, "resource too large"
+	case errors.Is(err, fileversion.ErrDeletePending):
+		return scanFailed, "delete pending"
+	case errors.Is(err, windows.ERROR_SHARING_VIOLATION), errors.Is(err, windows.ERROR_LOCK_VIOLATION):
+		return scanFailed, "sharing violation"
+	case errors.Is(err, os.ErrPermission):
+		return scanFailed, "access denied"
+	case errors.Is(err, os.ErrNotExist):
+		return scanFailed, "not found"
+	default:
+		return scanFailed, "other"
+	}
+}
+
+// progress updates the status line on stderr.
+func progress(stats scanStats, dir string, elapsed time.Duration) {
+	const width = 60
+	if len(dir) > width {
+		dir = "..." + dir[len(dir)-width+3:]
+	}
+	fmt.Fprintf(os.Stderr, "\r\033[K%d files (%.0f/s), %d images: %s", stats.files, rate(stats.files, elapsed), stats.images, dir)
+}
+
+// rate returns the number of items per second.
+func rate(n int, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(n) / elapsed.Seconds()
+}
+
+// statsRecord is the scan -stats file, the counters of the summary for the
+// monitoring agents to pick up after the scan.
+type statsRecord struct {
+	Files          int            `json:"files"`
+	Images         int            `json:"images"`
+	Failed         int            `json:"failed"`
+	Signed         int            `json:"signed"`
+	Unsigned       int            `json:"unsigned"`
+	ReparsePoints  int            `json:"reparsePoints"`
+	Cycles         int            `json:"cycles"`
+	Duplicates     int            `json:"duplicates"`
+	Excluded       int            `json:"excluded"`
+	Sniffed        int            `json:"sniffed"`
+	Reasons        map[string]int `json:"reasons"`
+	ElapsedSeconds float64        `json:"elapsedSeconds"`
+	FilesPerSecond float64        `json:"filesPerSecond"`
+}
+
+func writeStats(path string, stats scanStats, elapsed time.Duration) error {
+	data, err := json.Marshal(statsRecord{
+		Files:          stats.files,
+		Images:         stats.images,
+		Failed:         stats.failed,
+		Signed:         stats.signed,
+		Unsigned:       stats.unsigned,
+		ReparsePoints:  stats.reparsePoints,
+		Cycles:         stats.cycles,
+		Duplicates:     stats.duplicates,
+		Excluded:       stats.excluded,
+		Sniffed:        stats.sniffed,
+		Reasons:        stats.reasons,
+		ElapsedSeconds: elapsed.Seconds(),
+		FilesPerSecond: rate(stats.files, elapsed),
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o666)
+}
+
+func printSummary(w io.Writer, stats scanStats, inventory *fileversion.Inventory, sign bool, top int, elapsed time.Duration) {
+	fmt.Fprintf(w, "files:    %d\n", stats.files)
+	fmt.Fprintf(w, "images:   %d\n", stats.images)
+	fmt.Fprintf(w, "failed:   %d\n", stats.failed)
+	if sign {
+		fmt.Fprintf(w, "signed:   %d\n", stats.signed)
+		fmt.Fprintf(w, "unsigned: %d\n", stats.unsigned)
+	}
+	reasons := make([]string, 0, len(stats.reasons))
+	for reason := range stats.reasons {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		fmt.Fprintf(w, "  %5d  %s\n", stats.reasons[reason], reason)
+	}
+	if stats.reparsePoints != 0 {
+		fmt.Fprintf(w, "skipped reparse points: %d\n", stats.reparsePoints)
+	}
+	if stats.cycles != 0 {
+		fmt.Fprintf(w, "skipped cycles:         %d\n", stats.cycles)
+	}
+	if stats.duplicates != 0 {
+		fmt.Fprintf(w, "skipped hard links:     %d\n", stats.duplicates)
+	}
+	if stats.excluded != 0 {
+		fmt.Fprintf(w, "skipped excluded:       %d\n", stats.excluded)
+	}
+	if stats.sniffed != 0 {
+		fmt.Fprintf(w, "renamed images:         %d\n", stats.sniffed)
+	}
+	fmt.Fprintf(w, "elapsed:  %s\n", elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "rate:     %.1f files/s\n", rate(stats.files, elapsed))
+
+	products := inventory.Products()
+	sort.SliceStable(products, func(i, j int) bool {
+		return len(products[i].Files) > len(products[j].Files)
+	})
+	if len(products) > top {
+		products = products[:top]
+	}
+	if len(products) != 0 {
+		fmt.Fprintln(w, "top products:")
+	}
+	for _, p := range products {
+		fmt.Fprintf(w, "  %5d  %s %s %s\n", len(p.Files), p.CompanyName, p.ProductName, p.ProductVersion)
+	}
+}
@@ -0,0 +1,77 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/bi-zone/go-fileversion"
+)
+
+// journalChanges returns the PE files changed under the roots since the
+// positions saved in the state file and the positions to save after the
+// scan. Incremental is false if the state is missing or the journals were
+// reset: the roots must be scanned fully then.
+func journalChanges(statePath string, roots []string) (changes []fileversion.UsnChange, next []fileversion.UsnPosition, incremental bool, err error) {
+	current := make(map[string]fileversion.UsnPosition)
+	for _, root := range roots {
+		pos, err := fileversion.UsnJournalPosition(root)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if _, ok := current[pos.Volume]; !ok {
+			current[pos.Volume] = pos
+			next = append(next, pos)
+		}
+	}
+
+	data, err := os.ReadFile(statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, next, false, nil
+	}
+	if err != nil {
+		return nil, nil, false, err
+	}
+	var saved []fileversion.UsnPosition
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, nil, false, err
+	}
+	from := make(map[string]fileversion.UsnPosition)
+	for _, pos := range saved {
+		from[pos.Volume] = pos
+	}
+
+	var positions []fileversion.UsnPosition
+	for _, pos := range next {
+		start, ok := from[pos.Volume]
+		if !ok {
+			return nil, next, false, nil
+		}
+		volumeChanges, end, err := fileversion.UsnChanges(start)
+		if errors.Is(err, fileversion.ErrUsnJournalReset) {
+			return nil, next, false, nil
+		}
+		if err != nil {
+			return nil, nil, false, err
+		}
+		changes = append(changes, volumeChanges...)
+		positions = append(positions, end)
+	}
+	return changes, positions, true, nil
+}
+
+// saveJournalState writes the positions the next incremental scan starts at.
+func saveJournalState(statePath string, positions []fileversion.UsnPosition) error {
+	data, err := json.Marshal(positions)
+	if err != nil {
+		return err
+	}
+	tmp := statePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, statePath)
+}
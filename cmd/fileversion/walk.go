@@ -0,0 +1,181 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bi-zone/go-fileversion"
+)
+
+// walker walks the scanned directories. Unlike filepath.Walk it can follow
+// symlinks and junctions, detecting the directory cycles they create, and
+// skips hard links of already scanned files.
+type walker struct {
+	recursive bool
+	follow    bool
+	dedupe    bool
+	stats     *scanStats
+	onDir     func(path string)
+	// resumeAfter is the last file scanned before the restart, the walk
+	// skips the paths up to it.
+	resumeAfter string
+	// include replaces the PE extension check when set, exclude skips the
+	// matching files and directories.
+	include patternsFlag
+	exclude patternsFlag
+	// sniff checks the content of the files the names don't select, so
+	// the renamed images are scanned too.
+	sniff bool
+
+	ancestors map[fileversion.FileIdentity]bool
+	seen      map[fileversion.FileIdentity]bool
+}
+
+func newWalker(recursive, follow, dedupe bool, stats *scanStats, onDir func(string)) *walker {
+	return &walker{
+		recursive: recursive,
+		follow:    follow,
+		dedupe:    dedupe,
+		stats:     stats,
+		onDir:     onDir,
+		ancestors: make(map[fileversion.FileIdentity]bool),
+		seen:      make(map[fileversion.FileIdentity]bool),
+	}
+}
+
+// walk calls fn for the PE images under the root. The root itself is always
+// followed. Errors of fn stop the walk, the file system errors are counted.
+func (w *walker) walk(root string, fn func(path string) error) error {
+	fi, err := os.Stat(root)
+	if err != nil {
+		w.stats.failed++
+		return nil
+	}
+	if !fi.IsDir() {
+		return w.file(root, fn)
+	}
+	return w.dir(root, fn)
+}
+
+func (w *walker) dir(path string, fn func(path string) error) error {
+	if w.resumeAfter != "" && comparePaths(path, w.resumeAfter) < 0 && !isWithin(w.resumeAfter, path) {
+		return nil
+	}
+	if id, err := fileversion.IdentityOf(path); err == nil {
+		if w.ancestors[id] {
+			w.stats.cycles++
+			return nil
+		}
+		w.ancestors[id] = true
+		defer delete(w.ancestors, id)
+	}
+	w.onDir(path)
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		w.stats.failed++
+		return nil
+	}
+	for _, e := range entries {
+		child := filepath.Join(path, e.Name())
+		if w.exclude.match(e.Name()) {
+			w.stats.excluded++
+			continue
+		}
+		isDir := e.IsDir()
+		// Junctions are reported as symlinks or irregular files depending on
+		// the Go version.
+		if e.Type()&(os.ModeSymlink|os.ModeIrregular) != 0 {
+			if !w.follow {
+				w.stats.reparsePoints++
+				continue
+			}
+			fi, err := os.Stat(child)
+			if err != nil {
+				w.stats.failed++
+				continue
+			}
+			isDir = fi.IsDir()
+		}
+		if isDir {
+			if !w.recursive {
+				continue
+			}
+			if err := w.dir(child, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := w.file(child, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) file(path string, fn func(path string) error) error {
+	if !w.selected(path) {
+		if !w.sniff {
+			return nil
+		}
+		if ok, err := fileversion.IsPE(path); err != nil || !ok {
+			return nil
+		}
+		w.stats.sniffed++
+	}
+	if w.resumeAfter != "" {
+		if comparePaths(path, w.resumeAfter) <= 0 {
+			return nil
+		}
+		w.resumeAfter = ""
+	}
+	if w.dedupe {
+		if id, err := fileversion.IdentityOf(path); err == nil {
+			if w.seen[id] {
+				w.stats.duplicates++
+				return nil
+			}
+			w.seen[id] = true
+		}
+	}
+	return fn(path)
+}
+
+// selected reports whether the file name is selected for the scan.
+func (w *walker) selected(path string) bool {
+	if w.include != nil {
+		return w.include.match(filepath.Base(path))
+	}
+	return fileversion.HasPEExtension(path)
+}
+
+// patternsFlag collects repeated -include and -exclude glob patterns. The
+// patterns match the base names case-insensitively like Windows does.
+type patternsFlag []string
+
+func (p *patternsFlag) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *patternsFlag) Set(s string) error {
+	pattern := strings.ToLower(s)
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", s, err)
+	}
+	*p = append(*p, pattern)
+	return nil
+}
+
+func (p patternsFlag) match(name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range p {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,10 @@
+//go:build windows && !xlsx
+// +build windows,!xlsx
+
+package main
+
+import "errors"
+
+func newXLSXWriter(string) (tableWriter, error) {
+	return nil, errors.New("XLSX export is not built in, rebuild with -tags xlsx")
+}
@@ -0,0 +1,204 @@
+//go:build windows
+// +build windows
+
+// Command fileversiond serves version-information queries over HTTP/JSON for
+// non-Go systems running on the same Windows host.
+//
+//	POST /v1/query   {"path": "C:\\Windows\\notepad.exe"}
+//	POST /v1/parse   <PE image as the request body>
+//
+// Both return the fields of fileversion.FormatData as JSON. /v1/parse uses the
+// pure-Go parser, so the image is never written to disk.
+//
+// /v1/query accepts only absolute local paths: UNC and device paths (and
+// links resolving to them) are rejected, so a caller can't make the service
+// authenticate to a host of its choice. With -root (which may be repeated)
+// the paths are further restricted to the given directories.
+//
+// Usage:
+//
+//	fileversiond [-addr 127.0.0.1:8080] [-root "C:\Program Files"]...
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bi-zone/go-fileversion"
+)
+
+// Request limits: maxUploadSize limits the size of images accepted by
+// /v1/parse, maxQuerySize limits /v1/query bodies. The timeouts keep slow
+// clients from holding the connections.
+const (
+	maxUploadSize     = 256 << 20
+	maxQuerySize      = 64 << 10
+	maxHeaderSize     = 64 << 10
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 2 * time.Minute
+	writeTimeout      = 2 * time.Minute
+	idleTimeout       = 2 * time.Minute
+)
+
+// rootsFlag collects repeated -root flags.
+type rootsFlag []string
+
+func (r *rootsFlag) String() string {
+	return strings.Join(*r, ",")
+}
+
+func (r *rootsFlag) Set(s string) error {
+	if !filepath.IsAbs(s) || isRemote(s) {
+		return fmt.Errorf("root %q is not an absolute local path", s)
+	}
+	// Query paths are compared after resolving the links, so are the roots.
+	root, err := filepath.EvalSymlinks(s)
+	if err != nil {
+		return fmt.Errorf("failed to resolve root %q: %w", s, err)
+	}
+	*r = append(*r, root)
+	return nil
+}
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8080", "address to listen on")
+	var roots rootsFlag
+	flag.Var(&roots, "root", "directory /v1/query paths are restricted to, may be repeated")
+	flag.Parse()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/query", func(w http.ResponseWriter, r *http.Request) {
+		handleQuery(w, r, roots)
+	})
+	mux.HandleFunc("/v1/parse", handleParse)
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+		MaxHeaderBytes:    maxHeaderSize,
+	}
+	log.Printf("listening on %s", *addr)
+	log.Fatal(server.ListenAndServe())
+}
+
+type queryRequest struct {
+	Path string `json:"path"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func handleQuery(w http.ResponseWriter, r *http.Request, roots []string) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "POST required"})
+		return
+	}
+	var req queryRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxQuerySize)).Decode(&req); err != nil || req.Path == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "JSON body with non-empty \"path\" required"})
+		return
+	}
+	path, err := checkQueryPath(req.Path, roots)
+	if err != nil {
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: err.Error()})
+		return
+	}
+	info, err := fileversion.New(path)
+	writeInfo(w, info, err)
+}
+
+// checkQueryPath returns the cleaned path if it's an absolute local path
+// within the roots (if any). The directory links are resolved first, so a
+// junction or a symlink can't redirect the query to a remote share.
+func checkQueryPath(path string, roots []string) (string, error) {
+	if isRemote(path) {
+		return "", errors.New("UNC and device paths are not allowed")
+	}
+	if !filepath.IsAbs(path) {
+		return "", errors.New("absolute path required")
+	}
+	path = filepath.Clean(path)
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the directory of %q: %w", path, err)
+	}
+	path = filepath.Join(dir, filepath.Base(path))
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		// Alternate stream paths and missing files don't resolve, they are
+		// checked by the resolved directory.
+		path = resolved
+	}
+	if isRemote(path) {
+		return "", errors.New("path resolves to a UNC path")
+	}
+	if len(roots) == 0 {
+		return path, nil
+	}
+	for _, root := range roots {
+		if isWithin(path, root) {
+			return path, nil
+		}
+	}
+	return "", errors.New("path is out of the allowed roots")
+}
+
+// isRemote reports whether the path is a UNC or a device path like
+// `\\host\share\x.exe`, `\\?\UNC\host\x.exe` or `\\.\pipe\x`.
+func isRemote(path string) bool {
+	p := strings.ReplaceAll(path, "/", `\`)
+	return strings.HasPrefix(p, `\\`) || strings.HasPrefix(p, `\??\`)
+}
+
+// isWithin reports whether the path is the dir or is inside it.
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func handleParse(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "POST required"})
+		return
+	}
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxUploadSize))
+	if err != nil {
+		writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{Error: err.Error()})
+		return
+	}
+	info, err := fileversion.NewFromReader(bytes.NewReader(data))
+	writeInfo(w, info, err)
+}
+
+func writeInfo(w http.ResponseWriter, info fileversion.Info, err error) {
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, info.FormatData())
+	case errors.Is(err, fileversion.ErrNoVersionInfo):
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+	case errors.Is(err, fileversion.ErrNotPE):
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: err.Error()})
+	default:
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
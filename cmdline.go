@@ -0,0 +1,135 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+//nolint:gochecknoglobals
+var searchPathProc = kernel32.NewProc("SearchPathW")
+
+// NewFromCommandLine creates an Info for the executable of a command line like
+// the ones stored in services ImagePath and Run registry values, e.g.
+// `"C:\Program Files\Foo\foo.exe" -service`. See ExecutableFromCommandLine for
+// the parsing rules. Environment variables are expanded, so
+// WithPathNormalization is implied with an empty base directory.
+func NewFromCommandLine(cmd string, opts ...Option) (Info, error) {
+	path, err := ExecutableFromCommandLine(cmd)
+	if err != nil {
+		return Info{}, err
+	}
+	return New(path, append([]Option{WithPathNormalization("")}, opts...)...)
+}
+
+// ExecutableFromCommandLine extracts the executable path from a command line:
+//
+//   - a quoted path is taken up to the closing quote;
+//   - for an unquoted path containing spaces the shortest prefix ending with
+//     ".exe" or naming an existing file is taken, like CreateProcess does;
+//   - `rundll32.exe foo.dll,Entry` returns the DLL;
+//   - `\??\` and `\SystemRoot\` prefixes used by drivers are replaced with the
+//     Win32 equivalents;
+//   - a bare name like `svchost.exe -k netsvcs` is searched like CreateProcess
+//     (and LoadLibrary for the rundll32 DLLs) does: in the application
+//     directory, in System32 and then in the SearchPath directories. A
+//     missing extension defaults to ".exe" (".dll" for the DLLs).
+//
+// Environment variables are expanded before the line is split, so an
+// unquoted `%ProgramFiles%\Foo Bar\foo.exe -x` is found by its real path.
+func ExecutableFromCommandLine(cmd string) (string, error) {
+	expanded, err := expandEnvironmentStrings(strings.TrimSpace(cmd))
+	if err != nil {
+		return "", fmt.Errorf("failed to expand command line %q: %w", cmd, err)
+	}
+	path, args := splitCommandLine(expanded, isFile)
+	if path == "" {
+		return "", fmt.Errorf("no executable in command line %q", cmd)
+	}
+	// The application directory of CreateProcess is the one of the process
+	// starting the command, this one as far as we can tell.
+	appDir := ""
+	if exe, err := os.Executable(); err == nil {
+		appDir = filepath.Dir(exe)
+	}
+	path = resolveImage(ntPathToWin32(path, systemRoot), appDir, ".exe")
+	if base := strings.ToLower(filepath.Base(path)); base == "rundll32" || base == "rundll32.exe" {
+		if dll := rundll32Target(args, isFile); dll != "" {
+			// LoadLibrary in rundll32 starts with its own directory.
+			appDir = ""
+			if filepath.IsAbs(path) {
+				appDir = filepath.Dir(path)
+			}
+			path = resolveImage(ntPathToWin32(dll, systemRoot), appDir, ".dll")
+		}
+	}
+	return path, nil
+}
+
+// resolveImage returns the path of an image given by a bare name: the first
+// one found in the application directory, in System32 and with SearchPath
+// (the current and the Windows directories and PATH). Paths and the names
+// not found are returned as is.
+func resolveImage(name, appDir, ext string) string {
+	if name == "" || strings.ContainsAny(name, `\/:`) {
+		return name
+	}
+	file := name
+	if filepath.Ext(file) == "" {
+		file += ext
+	}
+	for _, dir := range []string{appDir, systemRoot() + `\System32`} {
+		if dir == "" {
+			continue
+		}
+		if path := filepath.Join(dir, file); isFile(path) {
+			return path
+		}
+	}
+	if path, err := searchPath(file); err == nil {
+		return path
+	}
+	return name
+}
+
+// searchPath is SearchPathW with the default search order.
+func searchPath(name string) (string, error) {
+	file, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]uint16, windows.MAX_PATH)
+	for {
+		n, _, err := searchPathProc.Call(0, uintptr(unsafe.Pointer(file)), 0, uintptr(len(buf)),
+			uintptr(unsafe.Pointer(&buf[0])), 0)
+		if n == 0 {
+			return "", fmt.Errorf("failed to search for %q: %w", name, err)
+		}
+		if int(n) < len(buf) {
+			return windows.UTF16ToString(buf[:n]), nil
+		}
+		buf = make([]uint16, n)
+	}
+}
+
+// systemRoot returns the Windows directory. The command line is already
+// expanded, so it's expanded separately and `%SystemRoot%` is kept (for
+// WithPathNormalization) only if the expansion fails.
+func systemRoot() string {
+	if root, err := expandEnvironmentStrings(`%SystemRoot%`); err == nil {
+		return root
+	}
+	return `%SystemRoot%`
+}
+
+func isFile(path string) bool {
+	stat, err := os.Stat(path)
+	return err == nil && !stat.IsDir()
+}
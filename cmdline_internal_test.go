@@ -0,0 +1,90 @@
+package fileversion
+
+import "testing"
+
+// existingFiles is the isFile of the command line tests.
+func existingFiles(paths ...string) func(string) bool {
+	return func(path string) bool {
+		for _, p := range paths {
+			if p == path {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	isFile := existingFiles(`C:\Program Files\Foo\foo.com`, `C:\My Drivers\foo.sys`)
+	tests := []struct {
+		name string
+		cmd  string
+		path string
+		args string
+	}{
+		{"empty", "", "", ""},
+		{"quoted", `"C:\Program Files\Foo\foo.exe" -service`, `C:\Program Files\Foo\foo.exe`, "-service"},
+		{"quoted without args", `"C:\Program Files\Foo\foo.exe"`, `C:\Program Files\Foo\foo.exe`, ""},
+		{"missing closing quote", `"C:\Program Files\Foo\foo.exe -service`, `C:\Program Files\Foo\foo.exe -service`, ""},
+		{"unquoted", `C:\Windows\foo.exe -k netsvcs`, `C:\Windows\foo.exe`, "-k netsvcs"},
+		{"unquoted with spaces", `C:\Program Files\Foo\foo.exe -service`, `C:\Program Files\Foo\foo.exe`, "-service"},
+		{"unquoted existing file", `C:\Program Files\Foo\foo.com /s`, `C:\Program Files\Foo\foo.com`, "/s"},
+		{"whole line is a file", `C:\My Drivers\foo.sys`, `C:\My Drivers\foo.sys`, ""},
+		{"first token", `foo.bat -x -y`, "foo.bat", "-x -y"},
+		{"bare name", "svchost.exe", "svchost.exe", ""},
+		{"nt path", `\??\C:\Windows\foo.sys -x`, `\??\C:\Windows\foo.sys`, "-x"},
+		{"system root", `\SystemRoot\System32\drivers\acpi.sys`, `\SystemRoot\System32\drivers\acpi.sys`, ""},
+	}
+	for _, tt := range tests {
+		path, args := splitCommandLine(tt.cmd, isFile)
+		if path != tt.path || args != tt.args {
+			t.Errorf("%s: splitCommandLine(%q) = %q, %q, want %q, %q", tt.name, tt.cmd, path, args, tt.path, tt.args)
+		}
+	}
+}
+
+func TestRundll32Target(t *testing.T) {
+	isFile := existingFiles(`C:\Program Files\Foo\foo.cpl`)
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{"empty", "", ""},
+		{"bare name", "shell32.dll,Control_RunDLL", "shell32.dll"},
+		{"space before entry", "foo.dll Entry", "foo.dll"},
+		{"comma in arguments", "foo.dll Entry a,b", "foo.dll"},
+		{"quoted", `"C:\Program Files\Foo\foo.dll",Entry arg`, `C:\Program Files\Foo\foo.dll`},
+		{"quoted comma inside", `"C:\Program Files\Foo\foo.dll,Entry"`, `C:\Program Files\Foo\foo.dll`},
+		{"unquoted with spaces", `C:\Program Files\Foo\foo.dll,Entry arg`, `C:\Program Files\Foo\foo.dll`},
+		{"unquoted existing file", `C:\Program Files\Foo\foo.cpl,Entry`, `C:\Program Files\Foo\foo.cpl`},
+		{"missing closing quote", `"C:\Program Files\Foo\foo.dll,Entry`, `C:\Program Files\Foo\foo.dll`},
+		{"nt path", `\??\C:\Windows\foo.dll,Entry`, `\??\C:\Windows\foo.dll`},
+		{"system root", `\SystemRoot\System32\foo.dll,Entry`, `\SystemRoot\System32\foo.dll`},
+	}
+	for _, tt := range tests {
+		if got := rundll32Target(tt.args, isFile); got != tt.want {
+			t.Errorf("%s: rundll32Target(%q) = %q, want %q", tt.name, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestNTPathToWin32(t *testing.T) {
+	systemRoot := func() string { return `C:\Windows` }
+	tests := []struct {
+		path string
+		want string
+	}{
+		{`\??\C:\Windows\System32\drivers\foo.sys`, `C:\Windows\System32\drivers\foo.sys`},
+		{`\SystemRoot\System32\drivers\acpi.sys`, `C:\Windows\System32\drivers\acpi.sys`},
+		{`\systemroot\System32\drivers\acpi.sys`, `C:\Windows\System32\drivers\acpi.sys`},
+		{`System32\drivers\acpi.sys`, `C:\Windows\System32\drivers\acpi.sys`},
+		{`C:\Program Files\Foo\foo.exe`, `C:\Program Files\Foo\foo.exe`},
+		{`\SystemRoot\`, `\SystemRoot\`},
+	}
+	for _, tt := range tests {
+		if got := ntPathToWin32(tt.path, systemRoot); got != tt.want {
+			t.Errorf("ntPathToWin32(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
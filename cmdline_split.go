@@ -0,0 +1,67 @@
+package fileversion
+
+import "strings"
+
+// splitCommandLine returns the executable and the rest of the command line.
+// isFile reports whether an unquoted prefix names an existing file.
+func splitCommandLine(cmd string, isFile func(string) bool) (path, args string) {
+	if strings.HasPrefix(cmd, `"`) {
+		if end := strings.IndexByte(cmd[1:], '"'); end >= 0 {
+			return cmd[1 : end+1], strings.TrimSpace(cmd[end+2:])
+		}
+		return cmd[1:], ""
+	}
+	// Try the prefixes ending at each space: `C:\Program Files\a.exe -x`.
+	for i := 0; i < len(cmd); i++ {
+		if cmd[i] != ' ' {
+			continue
+		}
+		candidate := cmd[:i]
+		if strings.HasSuffix(strings.ToLower(candidate), ".exe") || isFile(candidate) {
+			return candidate, strings.TrimSpace(cmd[i+1:])
+		}
+	}
+	// Neither matched: the first token is the executable unless the whole
+	// line is a path.
+	if i := strings.IndexByte(cmd, ' '); i >= 0 && !isFile(cmd) {
+		return cmd[:i], strings.TrimSpace(cmd[i+1:])
+	}
+	return cmd, ""
+}
+
+// rundll32Target returns the DLL of rundll32 arguments like `foo.dll,Entry`.
+func rundll32Target(args string, isFile func(string) bool) string {
+	if args == "" {
+		return ""
+	}
+	if !strings.HasPrefix(args, `"`) {
+		// rundll32 takes the DLL up to the comma before the entry point, so
+		// an unquoted path may contain spaces: `C:\Program Files\a.dll,Entry`.
+		if i := strings.IndexByte(args, ','); i >= 0 {
+			candidate := args[:i]
+			if !strings.Contains(candidate, " ") || strings.HasSuffix(strings.ToLower(candidate), ".dll") || isFile(candidate) {
+				return strings.TrimSpace(candidate)
+			}
+		}
+	}
+	dll, _ := splitCommandLine(args, isFile)
+	if i := strings.IndexByte(dll, ','); i >= 0 {
+		dll = dll[:i]
+	}
+	return strings.TrimSpace(dll)
+}
+
+// ntPathToWin32 converts `\??\C:\x` and `\SystemRoot\x` paths to Win32 ones.
+// systemRoot returns the Windows directory.
+func ntPathToWin32(path string, systemRoot func() string) string {
+	switch {
+	case strings.HasPrefix(path, `\??\`):
+		return path[len(`\??\`):]
+	case len(path) > len(`\SystemRoot\`) && strings.EqualFold(path[:len(`\SystemRoot\`)], `\SystemRoot\`):
+		return systemRoot() + `\` + path[len(`\SystemRoot\`):]
+	case len(path) > len(`System32\`) && strings.EqualFold(path[:len(`System32\`)], `System32\`):
+		// Drivers ImagePath is often relative to the Windows directory.
+		return systemRoot() + `\` + path
+	}
+	return path
+}
@@ -0,0 +1,57 @@
+package fileversion
+
+// compactInfo is an eagerly parsed version-information resource which
+// replaces the raw data of an Info returned by Compact.
+type compactInfo struct {
+	properties []Property
+	values     map[PropertyKey]string
+	tables     map[Locale]bool
+	// stringTables are the tables keys in the resource order.
+	stringTables []Locale
+	vars         map[string][]byte
+	fixed        FixedFileInfo
+	fixedErr     error
+
+	translations    []Locale
+	translationsErr error
+}
+
+// Compact parses all the string properties and the fixed part of the resource
+// and returns an Info which doesn't reference the raw version-information
+// data. It's intended for long-lived collections of Info values: the raw
+// resource is usually several times bigger than the properties.
+//
+// The returned Info behaves the same way as f except that the values are
+// served from the parsed copy. If the resource can't be parsed f is returned
+// as is with the error.
+func (f Info) Compact() (Info, error) {
+	if f.compact != nil {
+		return f, nil
+	}
+	properties, err := f.Properties()
+	if err != nil {
+		return f, err
+	}
+	c := &compactInfo{
+		properties: properties,
+		values:     make(map[PropertyKey]string, len(properties)),
+		tables:     make(map[Locale]bool),
+	}
+	c.fixed, c.fixedErr = f.FixedInfoE()
+	c.translations, c.translationsErr = f.getLocales()
+	for _, p := range properties {
+		if _, ok := c.values[p.PropertyKey]; !ok {
+			c.values[p.PropertyKey] = p.Value
+		}
+	}
+	root, _ := f.rootBlock()
+	c.stringTables = stringTableLocales(root)
+	c.vars = varValues(root)
+	for _, locale := range c.stringTables {
+		c.tables[locale] = true
+	}
+	f.compact = c
+	f.data = nil
+	f.tree = nil
+	return f, nil
+}
@@ -0,0 +1,72 @@
+package fileversion
+
+import (
+	"strings"
+	"unicode"
+)
+
+// confusables maps the characters commonly used to spoof Latin text to the
+// ASCII characters they look like. It's a subset of the Unicode confusables
+// (https://www.unicode.org/Public/security/latest/confusables.txt) covering
+// the Cyrillic, Greek and Armenian look-alikes; fullwidth forms are mapped
+// by ConfusableSkeleton arithmetically.
+//
+//nolint:gochecknoglobals
+var confusables = map[rune]rune{
+	// Cyrillic.
+	'а': 'a', 'А': 'A', 'В': 'B', 'с': 'c', 'С': 'C', 'е': 'e', 'Е': 'E', 'һ': 'h', 'Н': 'H',
+	'і': 'i', 'І': 'I', 'ј': 'j', 'Ј': 'J', 'К': 'K', 'М': 'M', 'о': 'o', 'О': 'O', 'р': 'p',
+	'Р': 'P', 'ԛ': 'q', 'ѕ': 's', 'Ѕ': 'S', 'Т': 'T', 'ԁ': 'd', 'у': 'y', 'Ү': 'Y', 'х': 'x',
+	'Х': 'X', 'ԝ': 'w', 'Ԝ': 'W', 'ӏ': 'l', 'Ӏ': 'I',
+	// Greek.
+	'α': 'a', 'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'ι': 'i', 'Ι': 'I', 'Κ': 'K',
+	'Μ': 'M', 'Ν': 'N', 'ο': 'o', 'Ο': 'O', 'ρ': 'p', 'Ρ': 'P', 'Τ': 'T', 'υ': 'u', 'Υ': 'Y',
+	'Χ': 'X', 'ν': 'v',
+	// Armenian.
+	'ա': 'w', 'օ': 'o', 'ս': 'u', 'ց': 'g', 'հ': 'h', 'ո': 'n', 'Տ': 'S', 'Օ': 'O',
+	// Latin look-alikes of other letters.
+	'ı': 'i', 'ɡ': 'g', 'ℓ': 'l', 'ⅼ': 'l', 'Ⅰ': 'I',
+}
+
+// Fullwidth ASCII variants. Source:
+// https://www.unicode.org/charts/PDF/UFF00.pdf
+const (
+	fullwidthFirst = 0xff01
+	fullwidthLast  = 0xff5e
+	fullwidthShift = 0xff01 - '!'
+)
+
+// ContainsConfusables reports whether s contains characters which look like
+// ASCII letters or digits but aren't (Cyrillic "а" in "Microsoft", fullwidth
+// "Ｍ") or invisible format characters. It's meant for values expected to be
+// Latin like CompanyName: legitimate Cyrillic or Greek text is reported too.
+// Compare ConfusableSkeleton values to match the spoofed names.
+func ContainsConfusables(s string) bool {
+	for _, r := range s {
+		if _, ok := confusables[r]; ok {
+			return true
+		}
+		if (r >= fullwidthFirst && r <= fullwidthLast) || unicode.Is(unicode.Cf, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfusableSkeleton replaces the characters ContainsConfusables reports with
+// the ASCII characters they look like and drops the invisible ones, so
+// "Місrоsоft" and "Microsoft" have the same skeleton.
+func ConfusableSkeleton(s string) string {
+	return strings.Map(func(r rune) rune {
+		if ascii, ok := confusables[r]; ok {
+			return ascii
+		}
+		switch {
+		case r >= fullwidthFirst && r <= fullwidthLast:
+			return r - fullwidthShift
+		case unicode.Is(unicode.Cf, r):
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,44 @@
+package fileversion_test
+
+import (
+	"testing"
+
+	"github.com/bi-zone/go-fileversion"
+)
+
+func TestConfusables(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		contains bool
+		skeleton string
+	}{
+		{"ascii", "Microsoft Corporation", false, "Microsoft Corporation"},
+		{"empty", "", false, ""},
+		{"cyrillic", "Місrоsоft", true, "Microsoft"},
+		{"greek", "ΑΜΖ", true, "AMZ"},
+		{"fullwidth", "Ｍｉｃｒｏｓｏｆｔ", true, "Microsoft"},
+		{"fullwidth punctuation", "Ａ＆Ｂ！", true, "A&B!"},
+		{"zero width space", "Micro\u200bsoft", true, "Microsoft"},
+		{"bom", "\ufeffMicrosoft", true, "Microsoft"},
+		// Only the look-alikes (а, о and р here) are replaced.
+		{"cyrillic word", "Лаборатория", true, "Лaбopaтopия"},
+		{"cjk", "株式会社", false, "株式会社"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fileversion.ContainsConfusables(tt.s); got != tt.contains {
+				t.Errorf("ContainsConfusables(%q) = %v, want %v", tt.s, got, tt.contains)
+			}
+			if got := fileversion.ConfusableSkeleton(tt.s); got != tt.skeleton {
+				t.Errorf("ConfusableSkeleton(%q) = %q, want %q", tt.s, got, tt.skeleton)
+			}
+		})
+	}
+}
+
+func TestConfusableSkeletonMatchesSpoof(t *testing.T) {
+	if a, b := fileversion.ConfusableSkeleton("Gооgle LLC"), fileversion.ConfusableSkeleton("Google LLC"); a != b {
+		t.Errorf("skeletons differ: %q and %q", a, b)
+	}
+}
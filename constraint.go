@@ -0,0 +1,90 @@
+package fileversion
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VersionConstraint is a conjunction of version comparisons like
+// ">=10.0, <10.0.19041". A nil VersionConstraint matches any version.
+type VersionConstraint []versionCondition
+
+type versionCondition struct {
+	op      string
+	version FileVersion
+}
+
+// ParseVersionConstraint parses comma separated comparisons. Supported
+// operators are =, !=, <, <=, > and >=, a version without an operator means
+// =. Versions are parsed with ParseFileVersion, missing version components
+// are zeros.
+func ParseVersionConstraint(s string) (VersionConstraint, error) {
+	var constraint VersionConstraint
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		op := "="
+		for _, candidate := range []string{"<=", ">=", "!=", "<", ">", "="} {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				part = strings.TrimSpace(part[len(candidate):])
+				break
+			}
+		}
+		version, err := ParseFileVersion(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid constraint %q: %w", s, err)
+		}
+		constraint = append(constraint, versionCondition{op: op, version: version})
+	}
+	return constraint, nil
+}
+
+// MustParseVersionConstraint is like ParseVersionConstraint but panics if the
+// constraint can't be parsed. It's intended for rules defined in variables.
+func MustParseVersionConstraint(s string) VersionConstraint {
+	c, err := ParseVersionConstraint(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Match reports whether v satisfies all the comparisons.
+func (c VersionConstraint) Match(v FileVersion) bool {
+	for _, cond := range c {
+		cmp := compareVersions(v, cond.version)
+		var ok bool
+		switch cond.op {
+		case "=":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// compareVersions compares versions in the Major.Minor.Build.Patch order.
+func compareVersions(a, b FileVersion) int {
+	switch x, y := a.Uint64(), b.Uint64(); {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,70 @@
+package fileversion_test
+
+import (
+	"testing"
+
+	"github.com/bi-zone/go-fileversion"
+)
+
+func TestVersionConstraint(t *testing.T) {
+	v := func(major, minor, build, patch uint16) fileversion.FileVersion {
+		return fileversion.FileVersion{Major: major, Minor: minor, Build: build, Patch: patch}
+	}
+	tests := []struct {
+		constraint string
+		version    fileversion.FileVersion
+		want       bool
+	}{
+		{"", v(1, 2, 3, 4), true},
+		{" , ", v(1, 2, 3, 4), true},
+		{"10.0", v(10, 0, 0, 0), true},
+		{"10.0", v(10, 0, 0, 1), false},
+		{"=10.0.19041.1", v(10, 0, 19041, 1), true},
+		{"!=10.0.19041.1", v(10, 0, 19041, 1), false},
+		{"!=10.0.19041.1", v(10, 0, 19041, 2), true},
+		{">=10.0, <10.0.19041", v(10, 0, 17763, 1), true},
+		{">=10.0, <10.0.19041", v(10, 0, 19041, 0), false},
+		{">=10.0, <10.0.19041", v(9, 9, 9, 9), false},
+		{"> 6.1", v(6, 1, 0, 1), true},
+		{">6.1", v(6, 1, 0, 0), false},
+		{"<= 6.1", v(6, 1, 0, 0), true},
+		{"<6.1", v(6, 0, 65535, 65535), true},
+		// The build is compared before the revision.
+		{">1.0.2.0", v(1, 0, 1, 65535), false},
+		{"<=65535.65535.65535.65535", v(65535, 65535, 65535, 65535), true},
+	}
+	for _, tt := range tests {
+		c, err := fileversion.ParseVersionConstraint(tt.constraint)
+		if err != nil {
+			t.Errorf("ParseVersionConstraint(%q) error = %v", tt.constraint, err)
+			continue
+		}
+		if got := c.Match(tt.version); got != tt.want {
+			t.Errorf("%q.Match(%v) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+
+	var none fileversion.VersionConstraint
+	if !none.Match(v(1, 0, 0, 0)) {
+		t.Error("nil constraint doesn't match")
+	}
+}
+
+func TestParseVersionConstraintErrors(t *testing.T) {
+	for _, s := range []string{
+		">=",
+		"=>10.0",
+		"<>10.0",
+		"abc",
+		"10.0x",
+		"1..2",
+		"10.0.19041.1.5",
+		"65536",
+		">=10.0, <",
+		"-1",
+	} {
+		if c, err := fileversion.ParseVersionConstraint(s); err == nil {
+			t.Errorf("ParseVersionConstraint(%q) = %v, want an error", s, c)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LayerEntry is a PE image found in a container layer. Path is the path of
+// the image inside the container, HostPath is the path it's read from. Err is
+// set if the image version info can't be read.
+type LayerEntry struct {
+	Path     string
+	HostPath string
+	Info     Info
+	Err      error
+}
+
+// containerLayerFiles is the subdirectory of a windowsfilter layer holding
+// the container file system root.
+const containerLayerFiles = "Files"
+
+// ScanContainerLayer reads version info of the PE images (judged by
+// extension, see HasPEExtension) of a Windows container layer or a mounted
+// image. For a layer directory (like
+// `C:\ProgramData\docker\windowsfilter\<id>`) its `Files` subdirectory is
+// the container `C:\` drive, any other directory is taken as the drive root
+// itself. Layers hold only the files changed by the layer, so scan every
+// layer of an image to report the versions per layer.
+func ScanContainerLayer(dir string, opts ...Option) ([]LayerEntry, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("failed to open layer: %w", err)
+	}
+	root := filepath.Join(dir, containerLayerFiles)
+	if fi, err := os.Stat(root); err != nil || !fi.IsDir() {
+		root = dir
+	}
+	var entries []LayerEntry
+	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() || !fi.Mode().IsRegular() || !HasPEExtension(path) {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		entry := LayerEntry{Path: `C:\` + rel, HostPath: path}
+		entry.Info, entry.Err = New(path, opts...)
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk layer %q: %w", dir, err)
+	}
+	return entries, nil
+}
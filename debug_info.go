@@ -0,0 +1,166 @@
+package fileversion
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// GUID is a windows GUID in its in-memory layout.
+type GUID struct {
+	Data1 uint32
+	Data2 uint16
+	Data3 uint16
+	Data4 [8]byte
+}
+
+// String returns the GUID in a registry format like
+// {3F2504E0-4F89-11D3-9A0C-0305E82C3301}.
+func (g GUID) String() string {
+	return fmt.Sprintf("{%08X-%04X-%04X-%X-%X}", g.Data1, g.Data2, g.Data3, g.Data4[:2], g.Data4[2:])
+}
+
+// DebugInfo contains a CodeView (RSDS) record of the PE debug directory which
+// links the image to its PDB.
+//
+// Ref: https://docs.microsoft.com/en-us/windows/win32/debug/pe-format#debug-type
+type DebugInfo struct {
+	PDBPath string
+	GUID    GUID
+	Age     uint32
+}
+
+// SymbolServerID returns an identifier of the PDB used in symbol server paths:
+// GUID in hex without separators followed by the age.
+func (d DebugInfo) SymbolServerID() string {
+	return fmt.Sprintf("%08X%04X%04X%X%X", d.GUID.Data1, d.GUID.Data2, d.GUID.Data3, d.GUID.Data4[:], d.Age)
+}
+
+const (
+	imageDirectoryEntryDebug = 6
+	imageDebugTypeCodeView   = 2
+	codeViewRSDSSignature    = 0x53445352 // "RSDS"
+)
+
+// imageDebugDirectory is IMAGE_DEBUG_DIRECTORY structure.
+type imageDebugDirectory struct {
+	Characteristics  uint32
+	TimeDateStamp    uint32
+	MajorVersion     uint16
+	MinorVersion     uint16
+	Type             uint32
+	SizeOfData       uint32
+	AddressOfRawData uint32
+	PointerToRawData uint32
+}
+
+// DebugInfo returns a CodeView record of the file Info was created from.
+func (f Info) DebugInfo() (DebugInfo, error) {
+	file, err := pe.Open(f.path)
+	if err != nil {
+		return DebugInfo{}, fmt.Errorf("failed to parse PE headers of %q: %w", f.path, err)
+	}
+	defer file.Close()
+	return readDebugInfo(file)
+}
+
+func readDebugInfo(file *pe.File) (DebugInfo, error) {
+	dir, ok := dataDirectory(file, imageDirectoryEntryDebug)
+	if !ok || dir.VirtualAddress == 0 || dir.Size == 0 {
+		return DebugInfo{}, errors.New("image has no debug directory")
+	}
+	data, err := readRVA(file, dir.VirtualAddress, dir.Size)
+	if err != nil {
+		return DebugInfo{}, fmt.Errorf("failed to read debug directory: %w", err)
+	}
+	r := bytes.NewReader(data)
+	for {
+		var entry imageDebugDirectory
+		if err := binary.Read(r, binary.LittleEndian, &entry); err != nil {
+			break
+		}
+		if entry.Type != imageDebugTypeCodeView || entry.AddressOfRawData == 0 {
+			continue
+		}
+		record, err := readRVA(file, entry.AddressOfRawData, entry.SizeOfData)
+		if err != nil {
+			return DebugInfo{}, fmt.Errorf("failed to read CodeView record: %w", err)
+		}
+		return parseCodeView(record)
+	}
+	return DebugInfo{}, errors.New("image has no CodeView debug record")
+}
+
+func parseCodeView(record []byte) (DebugInfo, error) {
+	var header struct {
+		Signature uint32
+		GUID      GUID
+		Age       uint32
+	}
+	r := bytes.NewReader(record)
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return DebugInfo{}, fmt.Errorf("failed to read CodeView header: %w", err)
+	}
+	if header.Signature != codeViewRSDSSignature {
+		return DebugInfo{}, fmt.Errorf("unsupported CodeView signature %#x", header.Signature)
+	}
+	path := record[len(record)-r.Len():]
+	if i := bytes.IndexByte(path, 0); i >= 0 {
+		path = path[:i]
+	}
+	return DebugInfo{
+		PDBPath: string(path),
+		GUID:    header.GUID,
+		Age:     header.Age,
+	}, nil
+}
+
+// dataDirectory returns the i-th data directory of the optional header.
+func dataDirectory(file *pe.File, i int) (pe.DataDirectory, bool) {
+	var dirs []pe.DataDirectory
+	switch h := file.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		dirs = dataDirectories(h.DataDirectory[:], h.NumberOfRvaAndSizes)
+	case *pe.OptionalHeader64:
+		dirs = dataDirectories(h.DataDirectory[:], h.NumberOfRvaAndSizes)
+	}
+	if i >= len(dirs) {
+		return pe.DataDirectory{}, false
+	}
+	return dirs[i], true
+}
+
+// readRVA reads size bytes of the image starting at the relative virtual
+// address rva. Sizes come from the image itself, so the last byte of the
+// range is read first: a truncated file fails before the buffer is allocated
+// instead of making a tiny file allocate gigabytes.
+func readRVA(file *pe.File, rva, size uint32) ([]byte, error) {
+	for _, s := range file.Sections {
+		if rva < s.VirtualAddress || rva-s.VirtualAddress >= s.Size {
+			continue
+		}
+		offset := rva - s.VirtualAddress
+		if uint64(offset)+uint64(size) > uint64(s.Size) {
+			return nil, fmt.Errorf("rva range %#x+%#x crosses section %q bounds", rva, size, s.Name)
+		}
+		if size == 0 {
+			return nil, nil
+		}
+		var last [1]byte
+		if _, err := s.ReadAt(last[:], int64(offset)+int64(size)-1); err != nil {
+			return nil, fmt.Errorf("rva range %#x+%#x is out of the file: %w", rva, size, io.ErrUnexpectedEOF)
+		}
+		data := make([]byte, size)
+		if n, err := s.ReadAt(data, int64(offset)); n < len(data) {
+			if err == nil || errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, fmt.Errorf("failed to read rva range %#x+%#x: %w", rva, size, err)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("rva %#x is not mapped to any section", rva)
+}
@@ -0,0 +1,101 @@
+package fileversion
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseFileVersion parses 1 to 4 dot-separated decimal version parts in the
+// Major.Minor.Build.Revision order, e.g. "10.0.19041.1". Missing parts are
+// zero. Unlike the version strings of the resource, nothing else is allowed
+// in s.
+func ParseFileVersion(s string) (FileVersion, error) {
+	fields := strings.Split(s, ".")
+	if len(fields) > 4 {
+		return FileVersion{}, fmt.Errorf("invalid version %q: too many parts", s)
+	}
+	var parts [4]uint16
+	for i, field := range fields {
+		v, err := strconv.ParseUint(field, 10, 16)
+		if err != nil {
+			return FileVersion{}, fmt.Errorf("invalid version %q: %w", s, err)
+		}
+		parts[i] = uint16(v)
+	}
+	return FileVersion{Major: parts[0], Minor: parts[1], Build: parts[2], Patch: parts[3]}, nil
+}
+
+// MarshalText encodes the version in the Major.Minor.Build.Revision form like
+// "10.0.19041.1". Note that String keeps the historical order instead.
+func (f FileVersion) MarshalText() ([]byte, error) {
+	return []byte(rawVersion(f)), nil
+}
+
+// UnmarshalText decodes the version with ParseFileVersion.
+func (f *FileVersion) UnmarshalText(text []byte) error {
+	v, err := ParseFileVersion(string(text))
+	if err != nil {
+		return err
+	}
+	*f = v
+	return nil
+}
+
+// Value implements driver.Valuer: the version is stored as the MarshalText
+// string. Store Uint64 instead where numeric comparisons are needed, Scan
+// accepts both.
+func (f FileVersion) Value() (driver.Value, error) {
+	return rawVersion(f), nil
+}
+
+// Scan implements sql.Scanner for strings, byte slices and integers packed by
+// Uint64. Signed BIGINT columns store the versions with Major >= 0x8000 as
+// negative numbers, they are unpacked from the same bits.
+func (f *FileVersion) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return f.UnmarshalText([]byte(v))
+	case []byte:
+		return f.UnmarshalText(v)
+	case int64:
+		*f = FileVersionFromUint64(uint64(v))
+		return nil
+	default:
+		return fmt.Errorf("failed to scan %T into FileVersion", src)
+	}
+}
+
+// MarshalText encodes the locale in the 8-hex-digit form like "040904b0".
+func (l Locale) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText decodes the locale with ParseLocale.
+func (l *Locale) UnmarshalText(text []byte) error {
+	v, err := ParseLocale(string(text))
+	if err != nil {
+		return err
+	}
+	*l = v
+	return nil
+}
+
+// Value implements driver.Valuer: the locale is stored as the MarshalText
+// string.
+func (l Locale) Value() (driver.Value, error) {
+	return l.String(), nil
+}
+
+// Scan implements sql.Scanner for strings and byte slices.
+func (l *Locale) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return l.UnmarshalText([]byte(v))
+	case []byte:
+		return l.UnmarshalText(v)
+	default:
+		return fmt.Errorf("failed to scan %T into Locale", src)
+	}
+}
@@ -0,0 +1,97 @@
+package fileversion_test
+
+import (
+	"testing"
+
+	"github.com/bi-zone/go-fileversion"
+)
+
+func TestParseFileVersion(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    fileversion.FileVersion
+		wantErr bool
+	}{
+		{s: "10.0.19041.1", want: fileversion.FileVersion{Major: 10, Minor: 0, Build: 19041, Patch: 1}},
+		{s: "10", want: fileversion.FileVersion{Major: 10}},
+		{s: "6.1", want: fileversion.FileVersion{Major: 6, Minor: 1}},
+		{s: "65535.65535.65535.65535", want: fileversion.FileVersion{Major: 65535, Minor: 65535, Build: 65535, Patch: 65535}},
+		{s: "", wantErr: true},
+		{s: ".", wantErr: true},
+		{s: "1..2", wantErr: true},
+		{s: "1.2.", wantErr: true},
+		{s: ".1", wantErr: true},
+		{s: "1.2.3.4.5", wantErr: true},
+		{s: "1.2.3.4.", wantErr: true},
+		{s: "65536", wantErr: true},
+		{s: "-1", wantErr: true},
+		{s: "+1", wantErr: true},
+		{s: " 1.2", wantErr: true},
+		{s: "1,2,3,4", wantErr: true},
+		{s: "10.0.19041.1 (WinBuild.160101.0800)", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := fileversion.ParseFileVersion(tt.s)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFileVersion(%q) = %v, %v, want %v, error %v", tt.s, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestFileVersionScan(t *testing.T) {
+	high := fileversion.FileVersion{Major: 0x8001, Minor: 2, Build: 3, Patch: 4}
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    fileversion.FileVersion
+		wantErr bool
+	}{
+		{name: "string", src: "10.0.19041.1", want: fileversion.FileVersion{Major: 10, Build: 19041, Patch: 1}},
+		{name: "bytes", src: []byte("6.1"), want: fileversion.FileVersion{Major: 6, Minor: 1}},
+		{name: "packed", src: int64(0x000a_0000_4a61_0001), want: fileversion.FileVersion{Major: 10, Build: 19041, Patch: 1}},
+		{name: "zero", src: int64(0), want: fileversion.FileVersion{}},
+		{name: "negative", src: int64(high.Uint64()), want: high},
+		{name: "minus one", src: int64(-1), want: fileversion.FileVersion{Major: 65535, Minor: 65535, Build: 65535, Patch: 65535}},
+		{name: "bad string", src: "1..2", wantErr: true},
+		{name: "nil", src: nil, wantErr: true},
+		{name: "float", src: 1.5, wantErr: true},
+	}
+	for _, tt := range tests {
+		var got fileversion.FileVersion
+		err := got.Scan(tt.src)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%s: Scan(%v) = %v, %v, want %v, error %v", tt.name, tt.src, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestFileVersionText(t *testing.T) {
+	v := fileversion.FileVersion{Major: 10, Minor: 0, Build: 19041, Patch: 1}
+	text, err := v.MarshalText()
+	if err != nil || string(text) != "10.0.19041.1" {
+		t.Fatalf("MarshalText() = %q, %v, want %q", text, err, "10.0.19041.1")
+	}
+	var got fileversion.FileVersion
+	if err := got.UnmarshalText(text); err != nil || got != v {
+		t.Errorf("UnmarshalText(%q) = %v, %v, want %v", text, got, err, v)
+	}
+	if value, err := v.Value(); err != nil || value != "10.0.19041.1" {
+		t.Errorf("Value() = %v, %v, want %q", value, err, "10.0.19041.1")
+	}
+}
+
+func TestLocaleScan(t *testing.T) {
+	want := fileversion.Locale{LangID: 0x0409, CharsetID: 0x04b0}
+	for _, src := range []interface{}{"040904b0", []byte("040904B0")} {
+		var got fileversion.Locale
+		if err := got.Scan(src); err != nil || got != want {
+			t.Errorf("Scan(%v) = %v, %v, want %v", src, got, err, want)
+		}
+	}
+	for _, src := range []interface{}{"0409", "040904b0x", int64(0x040904b0), nil} {
+		var got fileversion.Locale
+		if err := got.Scan(src); err == nil {
+			t.Errorf("Scan(%v) = %v, want an error", src, got)
+		}
+	}
+}
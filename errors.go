@@ -0,0 +1,86 @@
+package fileversion
+
+import (
+	"errors"
+)
+
+// Sentinel errors returned (wrapped) by the package. Use errors.Is for
+// checking them.
+//
+//nolint:gochecknoglobals
+var (
+	// ErrNoVersionInfo means the file exists and is a valid image but has no
+	// version-information resource.
+	ErrNoVersionInfo = errors.New("file has no version-information resource")
+	// ErrNotPE means the file is not an executable image windows can read
+	// resources from.
+	ErrNotPE = errors.New("file is not a PE image")
+	// ErrBadLocale means the version-information resource has no string table
+	// for the requested locale.
+	ErrBadLocale = errors.New("no string table for the locale")
+	// ErrPropertyNotFound means the string table has no requested property.
+	ErrPropertyNotFound = errors.New("property not found")
+	// ErrNoFixedInfo means the version-information resource has no
+	// VS_FIXEDFILEINFO part.
+	ErrNoFixedInfo = errors.New("no fixed file info")
+	// ErrMalformedResource means the version-information resource is
+	// truncated or its lengths and offsets point out of the resource.
+	ErrMalformedResource = errors.New("malformed version-information resource")
+	// ErrUnsupportedFormat means the file is an executable of a legacy format
+	// (DOS, NE, LE or LX) the operation can't read, see
+	// DetectExecutableFormat.
+	ErrUnsupportedFormat = errors.New("unsupported executable format")
+	// ErrResourceTooLarge means the version-information resource is bigger
+	// than the limit set by WithMaxResourceSize, which usually indicates a
+	// corrupt or a crafted file.
+	ErrResourceTooLarge = errors.New("version-information resource is too large")
+	// ErrDeletePending means the file is deleted but still opened by some
+	// process, so it can't be opened by the path, see WithShareDelete.
+	ErrDeletePending = errors.New("file is pending deletion")
+	// ErrUsnJournalReset means the change journal was recreated or has
+	// wrapped since the position passed to UsnChanges.
+	ErrUsnJournalReset = errors.New("change journal was reset")
+)
+
+// Error describes a failure of a windows call. Kind is one of the package
+// sentinel errors (or nil if the failure is not classified) and Err is the
+// underlying windows.Errno, so all of
+//
+//	errors.Is(err, fileversion.ErrNoVersionInfo)
+//	errors.Is(err, os.ErrNotExist)
+//	errors.Is(err, windows.ERROR_RESOURCE_TYPE_NOT_FOUND)
+//
+// work for the errors returned by New. SubBlock is set for failed lookups of
+// the version-information values and contains the queried value path in the
+// VerQueryValue syntax like `\StringFileInfo\040904b0\CompanyName`.
+type Error struct {
+	Op       string
+	Path     string
+	SubBlock string
+	Kind     error
+	Err      error
+}
+
+func (e *Error) Error() string {
+	msg := e.Op + " " + e.Path
+	if e.SubBlock != "" {
+		msg += " " + e.SubBlock
+	}
+	if e.Kind != nil {
+		msg += ": " + e.Kind.Error()
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the underlying windows error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the Kind of the error.
+func (e *Error) Is(target error) bool {
+	return e.Kind != nil && target == e.Kind
+}
@@ -0,0 +1,78 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// EventLogWriter writes version info of files to the Windows event log, one
+// event per file, so SIEM collectors reading the log pick them up.
+//
+// Events are written with the given event ID and the EVENTLOG_INFORMATION_TYPE
+// type. Event strings are the "Name=Value" pairs of the standard properties
+// and the fixed versions, the first string is the file path. The source has
+// no message file registered (unless the caller registers one), so the event
+// viewer shows a "description can't be found" note followed by the strings.
+type EventLogWriter struct {
+	handle  windows.Handle
+	eventID uint32
+}
+
+// NewEventLogWriter opens the event source (e.g. the application name) in the
+// Application log of the local machine.
+func NewEventLogWriter(source string, eventID uint32) (*EventLogWriter, error) {
+	sourcePtr, err := windows.UTF16PtrFromString(source)
+	if err != nil {
+		return nil, err
+	}
+	handle, err := windows.RegisterEventSource(nil, sourcePtr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register event source %q: %w", source, err)
+	}
+	return &EventLogWriter{handle: handle, eventID: eventID}, nil
+}
+
+// Write reports a single event for info.
+func (w *EventLogWriter) Write(info Info) error {
+	data := info.FormatData()
+	values := []string{
+		"Path=" + info.path,
+		"CompanyName=" + data.CompanyName,
+		"ProductName=" + data.ProductName,
+		"ProductVersion=" + data.ProductVersion,
+		"FileDescription=" + data.FileDescription,
+		"FileVersion=" + data.FileVersion,
+		"OriginalFilename=" + data.OriginalFilename,
+		"InternalName=" + data.InternalName,
+		"FileVersionRaw=" + data.FileVersionRaw,
+		"ProductVersionRaw=" + data.ProductVersionRaw,
+	}
+	strs := make([]*uint16, len(values))
+	for i, v := range values {
+		// Event strings can't contain NUL, replace it like other invalid data.
+		p, err := windows.UTF16PtrFromString(strings.ReplaceAll(v, "\x00", " "))
+		if err != nil {
+			return err
+		}
+		strs[i] = p
+	}
+	err := windows.ReportEvent(w.handle, windows.EVENTLOG_INFORMATION_TYPE, 0, w.eventID, 0,
+		uint16(len(strs)), 0, &strs[0], nil)
+	if err != nil {
+		return fmt.Errorf("failed to report event: %w", err)
+	}
+	return nil
+}
+
+// Close deregisters the event source.
+func (w *EventLogWriter) Close() error {
+	if err := windows.DeregisterEventSource(w.handle); err != nil {
+		return fmt.Errorf("failed to deregister event source: %w", err)
+	}
+	return nil
+}
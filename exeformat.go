@@ -0,0 +1,171 @@
+package fileversion
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ExecutableFormat is a format of an executable image detected by the
+// signatures of its headers.
+type ExecutableFormat int
+
+// Executable formats.
+const (
+	// FormatUnknown is anything without an "MZ" header.
+	FormatUnknown ExecutableFormat = iota
+	// FormatMZ is a DOS-only executable without a new-style header.
+	FormatMZ
+	// FormatNE is a 16-bit Windows or OS/2 executable.
+	FormatNE
+	// FormatLE is a VxD driver or an OS/2 2.x mixed 16/32-bit executable.
+	FormatLE
+	// FormatLX is an OS/2 32-bit executable.
+	FormatLX
+	// FormatPE is a PE (32 or 64-bit Windows) image.
+	FormatPE
+)
+
+// String returns the format name.
+func (f ExecutableFormat) String() string {
+	switch f {
+	case FormatMZ:
+		return "MZ"
+	case FormatNE:
+		return "NE"
+	case FormatLE:
+		return "LE"
+	case FormatLX:
+		return "LX"
+	case FormatPE:
+		return "PE"
+	default:
+		return "unknown"
+	}
+}
+
+// dosHeaderSize is the size of IMAGE_DOS_HEADER, e_lfanew is its last field.
+const (
+	dosHeaderSize  = 0x40
+	dosLfanewField = 0x3c
+)
+
+// DetectExecutableFormat detects the format of the image read from r.
+//
+// Only the PE images are supported by NewFromReader and the other pure-Go
+// readers, they fail with ErrUnsupportedFormat for the rest. New supports NE
+// images as well, since GetFileVersionInfo reads their resources natively.
+func DetectExecutableFormat(r io.ReaderAt) ExecutableFormat {
+	header := make([]byte, dosHeaderSize)
+	if _, err := r.ReadAt(header, 0); err != nil || string(header[:2]) != "MZ" {
+		return FormatUnknown
+	}
+	signature := make([]byte, 4)
+	lfanew := int64(binary.LittleEndian.Uint32(header[dosLfanewField:]))
+	if lfanew < dosHeaderSize {
+		return FormatMZ
+	}
+	if n, _ := r.ReadAt(signature, lfanew); n < 2 {
+		return FormatMZ
+	}
+	switch {
+	case string(signature) == "PE\x00\x00":
+		return FormatPE
+	case string(signature[:2]) == "NE":
+		return FormatNE
+	case string(signature[:2]) == "LE":
+		return FormatLE
+	case string(signature[:2]) == "LX":
+		return FormatLX
+	default:
+		return FormatMZ
+	}
+}
+
+// detectFileFormat is DetectExecutableFormat for the file at path.
+func detectFileFormat(path string) ExecutableFormat {
+	file, err := os.Open(path)
+	if err != nil {
+		return FormatUnknown
+	}
+	defer file.Close()
+	return DetectExecutableFormat(file)
+}
+
+// unsupportedFormatError returns an ErrUnsupportedFormat error if r is a
+// non-PE executable, or nil otherwise.
+func unsupportedFormatError(r io.ReaderAt) error {
+	if format := DetectExecutableFormat(r); format != FormatUnknown && format != FormatPE {
+		return fmt.Errorf("%w: %s executable", ErrUnsupportedFormat, format)
+	}
+	return nil
+}
+
+// IsPE reports whether the file at path is a PE image judging by its content,
+// whatever its extension is.
+func IsPE(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to open %q: %w", path, err)
+	}
+	defer file.Close()
+	return DetectExecutableFormat(file) == FormatPE, nil
+}
+
+//nolint:gochecknoglobals
+var (
+	peExtensionsMu sync.RWMutex
+	// peExtensions are the extensions of files usually being PE images:
+	// applications, libraries, drivers, control panel applets, screensavers,
+	// ActiveX controls, DirectShow filters, codecs, MUI files, EFI
+	// applications, Python extensions and metadata files.
+	peExtensions = map[string]bool{
+		".acm":   true,
+		".ax":    true,
+		".cpl":   true,
+		".dll":   true,
+		".drv":   true,
+		".efi":   true,
+		".exe":   true,
+		".mui":   true,
+		".ocx":   true,
+		".pyd":   true,
+		".scr":   true,
+		".sys":   true,
+		".tsp":   true,
+		".winmd": true,
+	}
+)
+
+// RegisterPEExtension adds an extension (like ".node") to the ones reported
+// by HasPEExtension.
+func RegisterPEExtension(ext string) {
+	peExtensionsMu.Lock()
+	defer peExtensionsMu.Unlock()
+	peExtensions[strings.ToLower(ext)] = true
+}
+
+// PEExtensions returns the extensions reported by HasPEExtension in no
+// particular order.
+func PEExtensions() []string {
+	peExtensionsMu.RLock()
+	defer peExtensionsMu.RUnlock()
+	exts := make([]string, 0, len(peExtensions))
+	for ext := range peExtensions {
+		exts = append(exts, ext)
+	}
+	return exts
+}
+
+// HasPEExtension reports whether the path has an extension of PE images, see
+// PEExtensions. It's a cheap pre-filter for directory walks, use IsPE to
+// check renamed files.
+func HasPEExtension(path string) bool {
+	peExtensionsMu.RLock()
+	defer peExtensionsMu.RUnlock()
+	return peExtensions[strings.ToLower(filepath.Ext(path))]
+}
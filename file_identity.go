@@ -0,0 +1,49 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// IdentityOf returns the identity of the file or the directory at path
+// following symlinks and junctions. Hard links of a file share the identity,
+// so it's the key for deduplicating files and for detecting directory cycles.
+func IdentityOf(path string) (FileIdentity, error) {
+	return fileIdentity(path)
+}
+
+// fileIdentity reads the identity of the file with GetFileInformationByHandle.
+func fileIdentity(path string) (FileIdentity, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return FileIdentity{}, err
+	}
+	handle, err := windows.CreateFile(pathPtr, windows.FILE_READ_ATTRIBUTES,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil, windows.OPEN_EXISTING, windows.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return FileIdentity{}, fmt.Errorf("failed to open %q: %w", path, err)
+	}
+	defer windows.CloseHandle(handle) //nolint:errcheck
+	id, err := handleIdentity(handle)
+	if err != nil {
+		return FileIdentity{}, fmt.Errorf("failed to get identity of %q: %w", path, err)
+	}
+	return id, nil
+}
+
+// handleIdentity reads the identity of the opened file.
+func handleIdentity(handle windows.Handle) (FileIdentity, error) {
+	var info windows.ByHandleFileInformation
+	if err := windows.GetFileInformationByHandle(handle, &info); err != nil {
+		return FileIdentity{}, fmt.Errorf("failed to get file information: %w", err)
+	}
+	return FileIdentity{
+		VolumeSerialNumber: info.VolumeSerialNumber,
+		FileIndex:          uint64(info.FileIndexHigh)<<32 | uint64(info.FileIndexLow),
+	}, nil
+}
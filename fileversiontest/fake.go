@@ -0,0 +1,120 @@
+package fileversiontest
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bi-zone/go-fileversion"
+)
+
+// Fake is a fileversion.Provider with settable data. The zero value has no
+// properties and no fixed info.
+type Fake struct {
+	// Locale is the locale Strings are returned for by GetPropertyWithLocale
+	// and Properties.
+	Locale fileversion.Locale
+	// Strings are the string properties by name.
+	Strings map[string]string
+	// Fixed is returned by FixedInfo, if it's nil FixedInfoE returns an
+	// error matching fileversion.ErrNoFixedInfo.
+	Fixed *fileversion.FixedFileInfo
+	// TranslationList is returned by Translations.
+	TranslationList []fileversion.Locale
+}
+
+var _ fileversion.Provider = Fake{}
+
+// CompanyName returns CompanyName property.
+func (f Fake) CompanyName() string { return f.Strings[string(fileversion.PropCompanyName)] }
+
+// FileDescription returns FileDescription property.
+func (f Fake) FileDescription() string { return f.Strings[string(fileversion.PropFileDescription)] }
+
+// FileVersion returns FileVersion property.
+func (f Fake) FileVersion() string { return f.Strings[string(fileversion.PropFileVersion)] }
+
+// InternalName returns InternalName property.
+func (f Fake) InternalName() string { return f.Strings[string(fileversion.PropInternalName)] }
+
+// LegalCopyright returns LegalCopyright property.
+func (f Fake) LegalCopyright() string { return f.Strings[string(fileversion.PropLegalCopyright)] }
+
+// OriginalFilename returns OriginalFilename property.
+func (f Fake) OriginalFilename() string { return f.Strings[string(fileversion.PropOriginalFilename)] }
+
+// ProductName returns ProductName property.
+func (f Fake) ProductName() string { return f.Strings[string(fileversion.PropProductName)] }
+
+// ProductVersion returns ProductVersion property.
+func (f Fake) ProductVersion() string { return f.Strings[string(fileversion.PropProductVersion)] }
+
+// Comments returns Comments property.
+func (f Fake) Comments() string { return f.Strings[string(fileversion.PropComments)] }
+
+// LegalTrademarks returns LegalTrademarks property.
+func (f Fake) LegalTrademarks() string { return f.Strings[string(fileversion.PropLegalTrademarks)] }
+
+// PrivateBuild returns PrivateBuild property.
+func (f Fake) PrivateBuild() string { return f.Strings[string(fileversion.PropPrivateBuild)] }
+
+// SpecialBuild returns SpecialBuild property.
+func (f Fake) SpecialBuild() string { return f.Strings[string(fileversion.PropSpecialBuild)] }
+
+// FixedInfo returns *Fixed or a zero FixedFileInfo.
+func (f Fake) FixedInfo() fileversion.FixedFileInfo {
+	info, _ := f.FixedInfoE()
+	return info
+}
+
+// FixedInfoE returns *Fixed or fileversion.ErrNoFixedInfo.
+func (f Fake) FixedInfoE() (fileversion.FixedFileInfo, error) {
+	if f.Fixed == nil {
+		return fileversion.FixedFileInfo{}, fileversion.ErrNoFixedInfo
+	}
+	return *f.Fixed, nil
+}
+
+// Translations returns TranslationList.
+func (f Fake) Translations() ([]fileversion.Locale, error) {
+	return append([]fileversion.Locale(nil), f.TranslationList...), nil
+}
+
+// Properties returns Strings with Locale sorted by name.
+func (f Fake) Properties() ([]fileversion.Property, error) {
+	properties := make([]fileversion.Property, 0, len(f.Strings))
+	for name, value := range f.Strings {
+		properties = append(properties, fileversion.Property{
+			PropertyKey: fileversion.PropertyKey{Locale: f.Locale, Name: name},
+			Value:       value,
+		})
+	}
+	sort.Slice(properties, func(i, j int) bool {
+		return properties[i].Name < properties[j].Name
+	})
+	return properties, nil
+}
+
+// GetProperty returns the property from Strings.
+func (f Fake) GetProperty(propertyName string) (string, error) {
+	if v, ok := f.Strings[propertyName]; ok {
+		return v, nil
+	}
+	return "", fmt.Errorf("failed to get property %q: %w", propertyName, fileversion.ErrPropertyNotFound)
+}
+
+// GetPropertyEx returns the property from Strings and Locale.
+func (f Fake) GetPropertyEx(propertyName string) (string, fileversion.Locale, error) {
+	v, err := f.GetProperty(propertyName)
+	if err != nil {
+		return "", fileversion.Locale{}, err
+	}
+	return v, f.Locale, nil
+}
+
+// GetPropertyWithLocale returns the property from Strings if locale is Locale.
+func (f Fake) GetPropertyWithLocale(propertyName string, locale fileversion.Locale) (string, error) {
+	if locale != f.Locale {
+		return "", fmt.Errorf("failed to get property %q with locale %+v: %w", propertyName, locale, fileversion.ErrBadLocale)
+	}
+	return f.GetProperty(propertyName)
+}
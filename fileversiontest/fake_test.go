@@ -0,0 +1,74 @@
+package fileversiontest_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/bi-zone/go-fileversion"
+	"github.com/bi-zone/go-fileversion/fileversiontest"
+)
+
+func TestFake(t *testing.T) {
+	locale := fileversion.Locale{LangID: fileversion.LangEnglishUS, CharsetID: fileversion.CSUnicode}
+	var p fileversion.Provider = fileversiontest.Fake{
+		Locale: locale,
+		Strings: map[string]string{
+			string(fileversion.PropProductName): "Contoso",
+			string(fileversion.PropCompanyName): "Contoso Ltd.",
+		},
+		TranslationList: []fileversion.Locale{locale},
+	}
+
+	if got := p.CompanyName(); got != "Contoso Ltd." {
+		t.Errorf("CompanyName() = %q, want %q", got, "Contoso Ltd.")
+	}
+	if got := p.FileVersion(); got != "" {
+		t.Errorf("FileVersion() = %q, want empty", got)
+	}
+	value, gotLocale, err := p.GetPropertyEx(string(fileversion.PropProductName))
+	if err != nil || value != "Contoso" || gotLocale != locale {
+		t.Errorf("GetPropertyEx() = %q, %v, %v, want %q, %v, nil", value, gotLocale, err, "Contoso", locale)
+	}
+	if _, err := p.GetProperty("Missing"); !errors.Is(err, fileversion.ErrPropertyNotFound) {
+		t.Errorf("GetProperty(Missing) error = %v, want ErrPropertyNotFound", err)
+	}
+	other := fileversion.Locale{LangID: 0x0419, CharsetID: fileversion.CSUnicode}
+	if _, err := p.GetPropertyWithLocale(string(fileversion.PropProductName), other); !errors.Is(err, fileversion.ErrBadLocale) {
+		t.Errorf("GetPropertyWithLocale(%v) error = %v, want ErrBadLocale", other, err)
+	}
+	if _, err := p.FixedInfoE(); !errors.Is(err, fileversion.ErrNoFixedInfo) {
+		t.Errorf("FixedInfoE() error = %v, want ErrNoFixedInfo", err)
+	}
+
+	properties, err := p.Properties()
+	if err != nil {
+		t.Fatalf("Properties() error = %v", err)
+	}
+	want := []fileversion.Property{
+		{PropertyKey: fileversion.PropertyKey{Locale: locale, Name: "CompanyName"}, Value: "Contoso Ltd."},
+		{PropertyKey: fileversion.PropertyKey{Locale: locale, Name: "ProductName"}, Value: "Contoso"},
+	}
+	if !reflect.DeepEqual(properties, want) {
+		t.Errorf("Properties() = %+v, want %+v", properties, want)
+	}
+	translations, err := p.Translations()
+	if err != nil || !reflect.DeepEqual(translations, []fileversion.Locale{locale}) {
+		t.Errorf("Translations() = %v, %v, want [%v]", translations, err, locale)
+	}
+}
+
+func TestFakeFixedInfo(t *testing.T) {
+	fixed := fileversion.FixedFileInfo{
+		Signature:   fileversion.FixedFileInfoSignature,
+		FileVersion: fileversion.FileVersion{Major: 10, Minor: 0, Build: 19041, Patch: 1},
+	}
+	f := fileversiontest.Fake{Fixed: &fixed}
+	got, err := f.FixedInfoE()
+	if err != nil || got != fixed {
+		t.Errorf("FixedInfoE() = %+v, %v, want %+v, nil", got, err, fixed)
+	}
+	if got := f.FixedInfo().FileVersion.Parts(); got != [4]uint16{10, 0, 19041, 1} {
+		t.Errorf("FixedInfo().FileVersion.Parts() = %v", got)
+	}
+}
@@ -0,0 +1,155 @@
+package fileversiontest
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+)
+
+// PE layout of generated images: the headers take the first file alignment
+// unit and the only .rsrc section follows them.
+const (
+	fileAlignment    = 0x200
+	sectionAlignment = 0x1000
+	imageBase        = 0x10000000
+	rsrcRVA          = sectionAlignment
+	rtVersion        = 16
+
+	imageFileExecutableImage = 0x0002
+	imageFile32BitMachine    = 0x0100
+	imageFileDLL             = 0x2000
+	imageScnCntInitialized   = 0x00000040
+	imageScnMemRead          = 0x40000000
+	imageDirectoryResource   = 2
+	imageSubsystemWindowsGUI = 2
+)
+
+// PE returns a minimal resource-only 32-bit DLL containing the resources as
+// RT_VERSION resources named 1, 2 and so on. Windows loads such images as
+// data files, so they work with both fileversion.New and NewFromReader.
+func PE(resources ...Resource) []byte {
+	rsrc := resourceSection(resources)
+
+	var b bytes.Buffer
+	dos := make([]byte, 0x40)
+	copy(dos, "MZ")
+	binary.LittleEndian.PutUint32(dos[0x3c:], uint32(len(dos)))
+	b.Write(dos)
+	b.WriteString("PE\x00\x00")
+
+	var optional pe.OptionalHeader32
+	fileHeader := pe.FileHeader{
+		Machine:              pe.IMAGE_FILE_MACHINE_I386,
+		NumberOfSections:     1,
+		SizeOfOptionalHeader: uint16(binary.Size(optional)),
+		Characteristics:      imageFileExecutableImage | imageFile32BitMachine | imageFileDLL,
+	}
+	rawSize := alignUp(len(rsrc), fileAlignment)
+	optional = pe.OptionalHeader32{
+		Magic:                       0x10b,
+		SizeOfInitializedData:       uint32(rawSize),
+		BaseOfData:                  rsrcRVA,
+		ImageBase:                   imageBase,
+		SectionAlignment:            sectionAlignment,
+		FileAlignment:               fileAlignment,
+		MajorOperatingSystemVersion: 4,
+		MajorSubsystemVersion:       4,
+		SizeOfImage:                 uint32(rsrcRVA + alignUp(len(rsrc), sectionAlignment)),
+		SizeOfHeaders:               fileAlignment,
+		Subsystem:                   imageSubsystemWindowsGUI,
+		SizeOfStackReserve:          0x100000,
+		SizeOfStackCommit:           0x1000,
+		SizeOfHeapReserve:           0x100000,
+		SizeOfHeapCommit:            0x1000,
+		NumberOfRvaAndSizes:         16,
+	}
+	optional.DataDirectory[imageDirectoryResource] = pe.DataDirectory{
+		VirtualAddress: rsrcRVA,
+		Size:           uint32(len(rsrc)),
+	}
+	section := pe.SectionHeader32{
+		VirtualSize:      uint32(len(rsrc)),
+		VirtualAddress:   rsrcRVA,
+		SizeOfRawData:    uint32(rawSize),
+		PointerToRawData: fileAlignment,
+		Characteristics:  imageScnCntInitialized | imageScnMemRead,
+	}
+	copy(section.Name[:], ".rsrc")
+	for _, v := range []interface{}{fileHeader, optional, section} {
+		binary.Write(&b, binary.LittleEndian, v) //nolint:errcheck
+	}
+
+	image := make([]byte, fileAlignment+rawSize)
+	copy(image, b.Bytes())
+	copy(image[fileAlignment:], rsrc)
+	return image
+}
+
+// WriteFile writes PE(resources...) to dir/name and returns the path.
+func WriteFile(dir, name string, resources ...Resource) (string, error) {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, PE(resources...), 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// resourceSection builds the .rsrc section: the type, name and language
+// directories followed by the data entries and the data itself.
+func resourceSection(resources []Resource) []byte {
+	const (
+		dirSize       = 16
+		entrySize     = 8
+		dataEntrySize = 16
+		subdirFlag    = 0x80000000
+	)
+	n := len(resources)
+	typeDir := 0
+	nameDir := typeDir + dirSize + entrySize
+	langDirs := nameDir + dirSize + n*entrySize
+	dataEntries := langDirs + n*(dirSize+entrySize)
+	dataStart := dataEntries + n*dataEntrySize
+
+	var data bytes.Buffer
+	offsets := make([]int, n)
+	blocks := make([][]byte, n)
+	for i, r := range resources {
+		for (dataStart+data.Len())%8 != 0 {
+			data.WriteByte(0)
+		}
+		offsets[i] = dataStart + data.Len()
+		blocks[i] = r.Block()
+		data.Write(blocks[i])
+	}
+
+	section := make([]byte, dataStart+data.Len())
+	le := binary.LittleEndian
+	directory := func(offset, ids int) {
+		le.PutUint16(section[offset+14:], uint16(ids))
+	}
+	entry := func(offset int, id, target uint32) {
+		le.PutUint32(section[offset:], id)
+		le.PutUint32(section[offset+4:], target)
+	}
+
+	directory(typeDir, 1)
+	entry(typeDir+dirSize, rtVersion, subdirFlag|uint32(nameDir))
+	directory(nameDir, n)
+	for i, r := range resources {
+		langDir := langDirs + i*(dirSize+entrySize)
+		dataEntry := dataEntries + i*dataEntrySize
+		entry(nameDir+dirSize+i*entrySize, uint32(i+1), subdirFlag|uint32(langDir))
+		directory(langDir, 1)
+		entry(langDir+dirSize, uint32(r.Lang), uint32(dataEntry))
+		le.PutUint32(section[dataEntry:], uint32(rsrcRVA+offsets[i]))
+		le.PutUint32(section[dataEntry+4:], uint32(len(blocks[i])))
+	}
+	copy(section[dataStart:], data.Bytes())
+	return section
+}
+
+func alignUp(n, alignment int) int {
+	return (n + alignment - 1) / alignment * alignment
+}
@@ -0,0 +1,221 @@
+package fileversiontest_test
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/bi-zone/go-fileversion"
+	"github.com/bi-zone/go-fileversion/fileversiontest"
+)
+
+//nolint:gochecknoglobals
+var (
+	englishUS = fileversion.Locale{LangID: fileversion.LangEnglishUS, CharsetID: fileversion.CSUnicode}
+	russian   = fileversion.Locale{LangID: 0x0419, CharsetID: fileversion.CSUnicode}
+)
+
+// sampleResource is a resource with fixed info and two string tables.
+func sampleResource() fileversiontest.Resource {
+	return fileversiontest.Resource{
+		Lang: fileversion.LangEnglishUS,
+		Fixed: fileversion.FixedFileInfo{
+			FileVersion:    fileversion.FileVersion{Major: 1, Minor: 2, Build: 3, Patch: 4},
+			ProductVersion: fileversion.FileVersion{Major: 5, Minor: 6, Build: 7, Patch: 8},
+			FileType:       1,
+		},
+		StringTables: []fileversiontest.StringTable{
+			{Locale: englishUS, Strings: []fileversiontest.String{
+				{Name: "CompanyName", Value: "Contoso Ltd."},
+				{Name: "FileVersion", Value: "1.2.3.4"},
+			}},
+			{Locale: russian, Strings: []fileversiontest.String{
+				{Name: "CompanyName", Value: "Контосо"},
+			}},
+		},
+	}
+}
+
+func TestResourceBlock(t *testing.T) {
+	root, err := fileversion.ParseVersionBlock(sampleResource().Block())
+	if err != nil {
+		t.Fatalf("ParseVersionBlock() error = %v", err)
+	}
+	if root.Key != "VS_VERSION_INFO" || len(root.Children) != 2 {
+		t.Fatalf("root = %q with %d children, want VS_VERSION_INFO with 2", root.Key, len(root.Children))
+	}
+
+	var fixed fileversion.FixedFileInfo
+	if err := fixed.UnmarshalBinary(root.Value); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	want := sampleResource().Fixed
+	want.Signature = fileversion.FixedFileInfoSignature
+	want.StrucVersion = 0x00010000
+	if fixed != want {
+		t.Errorf("fixed info = %+v, want %+v", fixed, want)
+	}
+
+	stringFileInfo := root.Children[0]
+	if stringFileInfo.Key != "StringFileInfo" || len(stringFileInfo.Children) != 2 {
+		t.Fatalf("StringFileInfo = %q with %d children", stringFileInfo.Key, len(stringFileInfo.Children))
+	}
+	tables := map[string]map[string]string{}
+	for _, table := range stringFileInfo.Children {
+		strs := map[string]string{}
+		for _, s := range table.Children {
+			if !s.IsText {
+				t.Errorf("string %q is not a text block", s.Key)
+			}
+			strs[s.Key] = s.Text()
+		}
+		tables[table.Key] = strs
+	}
+	wantTables := map[string]map[string]string{
+		"040904b0": {"CompanyName": "Contoso Ltd.", "FileVersion": "1.2.3.4"},
+		"041904b0": {"CompanyName": "Контосо"},
+	}
+	if !reflect.DeepEqual(tables, wantTables) {
+		t.Errorf("string tables = %v, want %v", tables, wantTables)
+	}
+
+	varFileInfo := root.Children[1]
+	if varFileInfo.Key != "VarFileInfo" || len(varFileInfo.Children) != 1 {
+		t.Fatalf("VarFileInfo = %q with %d children", varFileInfo.Key, len(varFileInfo.Children))
+	}
+	var translations []fileversion.Locale
+	value := varFileInfo.Children[0].Value
+	for ; len(value) >= 4; value = value[4:] {
+		translations = append(translations, fileversion.Locale{
+			LangID:    fileversion.LangID(binary.LittleEndian.Uint16(value)),
+			CharsetID: fileversion.CharsetID(binary.LittleEndian.Uint16(value[2:])),
+		})
+	}
+	if want := []fileversion.Locale{englishUS, russian}; !reflect.DeepEqual(translations, want) {
+		t.Errorf("translations = %v, want %v", translations, want)
+	}
+}
+
+func TestResourceBlockOmissions(t *testing.T) {
+	r := fileversiontest.Resource{
+		NoFixedInfo:  true,
+		StringTables: []fileversiontest.StringTable{{Locale: englishUS}},
+		Translations: []fileversion.Locale{},
+	}
+	root, err := fileversion.ParseVersionBlock(r.Block())
+	if err != nil {
+		t.Fatalf("ParseVersionBlock() error = %v", err)
+	}
+	if len(root.Value) != 0 {
+		t.Errorf("fixed info of %d bytes is written", len(root.Value))
+	}
+	if len(root.Children) != 1 || root.Children[0].Key != "StringFileInfo" {
+		t.Errorf("children = %+v, want only StringFileInfo", root.Children)
+	}
+
+	raw := []byte{1, 2, 3}
+	if got := (fileversiontest.Resource{Raw: raw}).Block(); !bytes.Equal(got, raw) {
+		t.Errorf("Block() = %v, want Raw %v", got, raw)
+	}
+}
+
+func TestPE(t *testing.T) {
+	resources := []fileversiontest.Resource{sampleResource(), {Lang: 0x0419, Fixed: sampleResource().Fixed}}
+	image := fileversiontest.PE(resources...)
+	file, err := pe.NewFile(bytes.NewReader(image))
+	if err != nil {
+		t.Fatalf("pe.NewFile() error = %v", err)
+	}
+	defer file.Close()
+
+	if file.Characteristics&pe.IMAGE_FILE_DLL == 0 {
+		t.Error("image is not a DLL")
+	}
+	section := file.Section(".rsrc")
+	if section == nil {
+		t.Fatal("image has no .rsrc section")
+	}
+	header, ok := file.OptionalHeader.(*pe.OptionalHeader32)
+	if !ok {
+		t.Fatalf("optional header is %T, want *pe.OptionalHeader32", file.OptionalHeader)
+	}
+	dir := header.DataDirectory[pe.IMAGE_DIRECTORY_ENTRY_RESOURCE]
+	if dir.VirtualAddress != section.VirtualAddress {
+		t.Errorf("resource directory RVA = %#x, want .rsrc RVA %#x", dir.VirtualAddress, section.VirtualAddress)
+	}
+	data, err := section.Data()
+	if err != nil {
+		t.Fatalf("failed to read .rsrc: %v", err)
+	}
+	for i, r := range resources {
+		if !bytes.Contains(data, r.Block()) {
+			t.Errorf("resource %d block is not in .rsrc", i)
+		}
+	}
+}
+
+// checkSample checks that info has the data of sampleResource.
+func checkSample(t *testing.T, info fileversion.Info) {
+	t.Helper()
+	fixed, err := info.FixedInfoE()
+	if err != nil {
+		t.Fatalf("FixedInfoE() error = %v", err)
+	}
+	if want := sampleResource().Fixed.FileVersion; fixed.FileVersion != want {
+		t.Errorf("FileVersion = %v, want %v", fixed.FileVersion, want)
+	}
+	if want := sampleResource().Fixed.ProductVersion; fixed.ProductVersion != want {
+		t.Errorf("ProductVersion = %v, want %v", fixed.ProductVersion, want)
+	}
+	translations, err := info.Translations()
+	if err != nil || !reflect.DeepEqual(translations, []fileversion.Locale{englishUS, russian}) {
+		t.Errorf("Translations() = %v, %v, want [%v %v]", translations, err, englishUS, russian)
+	}
+	for locale, want := range map[fileversion.Locale]string{englishUS: "Contoso Ltd.", russian: "Контосо"} {
+		got, err := info.GetPropertyWithLocale("CompanyName", locale)
+		if err != nil || got != want {
+			t.Errorf("GetPropertyWithLocale(CompanyName, %v) = %q, %v, want %q", locale, got, err, want)
+		}
+	}
+	if got := info.FileVersion(); got != "1.2.3.4" {
+		t.Errorf("FileVersion() = %q, want 1.2.3.4", got)
+	}
+}
+
+func TestPENewFromReader(t *testing.T) {
+	info, err := fileversion.NewFromReader(bytes.NewReader(fileversiontest.PE(sampleResource())))
+	if err != nil {
+		t.Fatalf("NewFromReader() error = %v", err)
+	}
+	checkSample(t, info)
+}
+
+func TestPENewAllFromReader(t *testing.T) {
+	second := fileversiontest.Resource{Lang: 0x0419, Fixed: fileversion.FixedFileInfo{
+		FileVersion: fileversion.FileVersion{Major: 9},
+	}}
+	resources, err := fileversion.NewAllFromReader(bytes.NewReader(fileversiontest.PE(sampleResource(), second)))
+	if err != nil {
+		t.Fatalf("NewAllFromReader() error = %v", err)
+	}
+	if len(resources) != 2 {
+		t.Fatalf("got %d resources, want 2", len(resources))
+	}
+	checkSample(t, resources[0].Info)
+	if resources[1].Err != nil || resources[1].Lang != 0x0419 {
+		t.Fatalf("second resource = %+v", resources[1])
+	}
+	if got := resources[1].Info.FixedInfo().FileVersion; got != second.Fixed.FileVersion {
+		t.Errorf("second FileVersion = %v, want %v", got, second.Fixed.FileVersion)
+	}
+}
+
+func TestPENoVersionInfo(t *testing.T) {
+	_, err := fileversion.NewFromReader(bytes.NewReader(fileversiontest.PE()))
+	if !errors.Is(err, fileversion.ErrNoVersionInfo) {
+		t.Errorf("NewFromReader() error = %v, want ErrNoVersionInfo", err)
+	}
+}
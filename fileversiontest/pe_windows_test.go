@@ -0,0 +1,20 @@
+package fileversiontest_test
+
+import (
+	"testing"
+
+	"github.com/bi-zone/go-fileversion"
+	"github.com/bi-zone/go-fileversion/fileversiontest"
+)
+
+func TestPENew(t *testing.T) {
+	path, err := fileversiontest.WriteFile(t.TempDir(), "sample.dll", sampleResource())
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	info, err := fileversion.New(path)
+	if err != nil {
+		t.Fatalf("New(%q) error = %v", path, err)
+	}
+	checkSample(t, info)
+}
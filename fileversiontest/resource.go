@@ -0,0 +1,157 @@
+// Package fileversiontest generates synthetic PE files with known
+// version-information resources, so code using fileversion can be tested
+// without committing real binaries. The package builds on every platform and
+// the generated files can be read with fileversion.NewFromReader anywhere.
+package fileversiontest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode/utf16"
+
+	"github.com/bi-zone/go-fileversion"
+)
+
+// String is a single string property of a StringTable.
+type String struct {
+	Name  string
+	Value string
+}
+
+// StringTable is a StringFileInfo child with the properties of one locale.
+// Strings are written in the given order.
+type StringTable struct {
+	Locale  fileversion.Locale
+	Strings []String
+}
+
+// Resource describes a single RT_VERSION resource.
+type Resource struct {
+	// Lang is the language of the resource directory entry.
+	Lang fileversion.LangID
+	// Fixed is written as VS_FIXEDFILEINFO. Zero Signature and StrucVersion
+	// are replaced with the valid values.
+	Fixed fileversion.FixedFileInfo
+	// NoFixedInfo omits VS_FIXEDFILEINFO.
+	NoFixedInfo  bool
+	StringTables []StringTable
+	// Translations is written to `\VarFileInfo\Translation`. nil means the
+	// locales of StringTables, an empty non-nil slice omits VarFileInfo.
+	Translations []fileversion.Locale
+	// Raw replaces the encoded VS_VERSIONINFO, e.g. with a corrupt one.
+	Raw []byte
+}
+
+// Block returns the encoded VS_VERSIONINFO (or Raw if it's set).
+func (r Resource) Block() []byte {
+	if r.Raw != nil {
+		return r.Raw
+	}
+	var fixed []byte
+	if !r.NoFixedInfo {
+		fixed = encodeFixedInfo(r.Fixed)
+	}
+
+	var children [][]byte
+	if len(r.StringTables) != 0 {
+		tables := make([][]byte, 0, len(r.StringTables))
+		for _, t := range r.StringTables {
+			strs := make([][]byte, 0, len(t.Strings))
+			for _, s := range t.Strings {
+				strs = append(strs, encodeBlock(s.Name, true, encodeText(s.Value), nil))
+			}
+			tables = append(tables, encodeBlock(t.Locale.String(), true, nil, strs))
+		}
+		children = append(children, encodeBlock("StringFileInfo", true, nil, tables))
+	}
+
+	translations := r.Translations
+	if translations == nil {
+		for _, t := range r.StringTables {
+			translations = append(translations, t.Locale)
+		}
+	}
+	if len(translations) != 0 {
+		var value bytes.Buffer
+		for _, l := range translations {
+			binary.Write(&value, binary.LittleEndian, l) //nolint:errcheck
+		}
+		v := encodeBlock("Translation", false, value.Bytes(), nil)
+		children = append(children, encodeBlock("VarFileInfo", true, nil, [][]byte{v}))
+	}
+	return encodeBlock("VS_VERSION_INFO", false, fixed, children)
+}
+
+// encodeBlock encodes a version-information block. For text blocks value
+// must be a zero-terminated UTF16 string.
+func encodeBlock(key string, isText bool, value []byte, children [][]byte) []byte {
+	var b bytes.Buffer
+	b.Write(make([]byte, 6)) // wLength, wValueLength and wType are set below.
+	b.Write(encodeText(key))
+	pad4(&b)
+	b.Write(value)
+	for _, c := range children {
+		pad4(&b)
+		b.Write(c)
+	}
+
+	data := b.Bytes()
+	valueLength := len(value)
+	valueType := uint16(0)
+	if isText {
+		valueLength /= 2
+		valueType = 1
+	}
+	binary.LittleEndian.PutUint16(data, uint16(len(data)))
+	binary.LittleEndian.PutUint16(data[2:], uint16(valueLength))
+	binary.LittleEndian.PutUint16(data[4:], valueType)
+	return data
+}
+
+// encodeText encodes s as a zero-terminated UTF16 string.
+func encodeText(s string) []byte {
+	u16 := append(utf16.Encode([]rune(s)), 0)
+	data := make([]byte, 2*len(u16))
+	for i, c := range u16 {
+		binary.LittleEndian.PutUint16(data[2*i:], c)
+	}
+	return data
+}
+
+func pad4(b *bytes.Buffer) {
+	for b.Len()%4 != 0 {
+		b.WriteByte(0)
+	}
+}
+
+// fixedFileInfoSize is the size of VS_FIXEDFILEINFO.
+const fixedFileInfoSize = 52
+
+func encodeFixedInfo(f fileversion.FixedFileInfo) []byte {
+	if f.Signature == 0 {
+		f.Signature = fileversion.FixedFileInfoSignature
+	}
+	if f.StrucVersion == 0 {
+		f.StrucVersion = 0x00010000
+	}
+	fields := []uint32{
+		f.Signature,
+		f.StrucVersion,
+		uint32(f.FileVersion.Major)<<16 | uint32(f.FileVersion.Minor),
+		uint32(f.FileVersion.Build)<<16 | uint32(f.FileVersion.Patch),
+		uint32(f.ProductVersion.Major)<<16 | uint32(f.ProductVersion.Minor),
+		uint32(f.ProductVersion.Build)<<16 | uint32(f.ProductVersion.Patch),
+		f.FileFlagsMask,
+		f.FileFlags,
+		f.FileOs,
+		f.FileType,
+		f.FileSubType,
+		f.FileDateMS,
+		f.FileDateLS,
+	}
+	data := make([]byte, fixedFileInfoSize)
+	for i, v := range fields {
+		binary.LittleEndian.PutUint32(data[4*i:], v)
+	}
+	return data
+}
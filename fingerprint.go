@@ -0,0 +1,59 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"sort"
+)
+
+// Fingerprint returns a hex-encoded SHA-256 digest of the canonicalized fixed
+// info and all the string tables. It doesn't depend on the resource layout
+// (padding, the order of tables and properties), so two scans can cheaply
+// tell whether the version data changed without diffing every field. The
+// digest is stable across package versions.
+//
+// Properties are hashed ordered by locale and name, duplicate properties of
+// a table keep the resource order. A missing or invalid fixed info is hashed
+// as absent.
+func (f Info) Fingerprint() (string, error) {
+	properties, err := f.Properties()
+	if err != nil {
+		return "", err
+	}
+	sort.SliceStable(properties, func(i, j int) bool {
+		a, b := properties[i], properties[j]
+		if a.Locale != b.Locale {
+			return a.Locale.String() < b.Locale.String()
+		}
+		return a.Name < b.Name
+	})
+
+	h := sha256.New()
+	if fixed, err := f.FixedInfoE(); err == nil {
+		data, _ := fixed.MarshalBinary()
+		h.Write([]byte{1}) //nolint:errcheck
+		h.Write(data)      //nolint:errcheck
+	} else {
+		h.Write([]byte{0}) //nolint:errcheck
+	}
+	for _, p := range properties {
+		h.Write([]byte(p.Locale.String())) //nolint:errcheck
+		writeHashString(h, p.Name)
+		writeHashString(h, p.Value)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeHashString writes a length-prefixed string, so the concatenation of
+// the fields is unambiguous.
+func writeHashString(h hash.Hash, s string) {
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(s)))
+	h.Write(n[:])      //nolint:errcheck
+	h.Write([]byte(s)) //nolint:errcheck
+}
@@ -0,0 +1,92 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// FormatData is the data Format exposes to templates.
+type FormatData struct {
+	// Path and FileID (FileIdentity in the String form) are empty for Info
+	// values not backed by a file.
+	Path   string
+	FileID string
+
+	Comments         string
+	CompanyName      string
+	FileDescription  string
+	FileVersion      string
+	InternalName     string
+	LegalCopyright   string
+	LegalTrademarks  string
+	OriginalFilename string
+	PrivateBuild     string
+	ProductName      string
+	ProductVersion   string
+	SpecialBuild     string
+
+	// FileVersionRaw and ProductVersionRaw are the binary versions from the
+	// fixed info formatted the way Explorer shows them.
+	FileVersionRaw    string
+	ProductVersionRaw string
+	FixedInfo         FixedFileInfo
+	Locales           []Locale
+	// Properties contains all the string properties of the resource including
+	// non-standard ones, chosen like GetProperty does.
+	Properties map[string]string
+}
+
+// FormatData collects the data available to Format templates.
+func (f Info) FormatData() FormatData {
+	fixed := f.FixedInfo()
+	data := FormatData{
+		Comments:          f.Comments(),
+		CompanyName:       f.CompanyName(),
+		FileDescription:   f.FileDescription(),
+		FileVersion:       f.FileVersion(),
+		InternalName:      f.InternalName(),
+		LegalCopyright:    f.LegalCopyright(),
+		LegalTrademarks:   f.LegalTrademarks(),
+		OriginalFilename:  f.OriginalFilename(),
+		PrivateBuild:      f.PrivateBuild(),
+		ProductName:       f.ProductName(),
+		ProductVersion:    f.ProductVersion(),
+		SpecialBuild:      f.SpecialBuild(),
+		FileVersionRaw:    rawVersion(fixed.FileVersion),
+		ProductVersionRaw: rawVersion(fixed.ProductVersion),
+		FixedInfo:         fixed,
+		Locales:           f.Locales,
+		Properties:        make(map[string]string),
+		Path:              f.path,
+	}
+	if id, ok := f.Identity(); ok {
+		data.FileID = id.String()
+	}
+	if properties, err := f.Properties(); err == nil {
+		for _, name := range propertyNames(properties) {
+			if v, err := f.GetProperty(name); err == nil {
+				data.Properties[name] = v
+			}
+		}
+	}
+	return data
+}
+
+// Format executes a text/template on the FormatData of the Info, e.g.
+//
+//	f.Format("{{.ProductName}} {{.FileVersionRaw}}")
+func (f Info) Format(tmpl string) (string, error) {
+	t, err := template.New("fileversion").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, f.FormatData()); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+	return sb.String(), nil
+}
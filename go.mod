@@ -1,5 +1,5 @@
 module github.com/bi-zone/go-fileversion
 
-go 1.12
+go 1.17
 
-require golang.org/x/xerrors v0.0.0-20190717185122-a985d3407aa7
+require golang.org/x/sys v0.5.0
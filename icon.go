@@ -0,0 +1,298 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// Icons returns all the icon groups of the file Info was created from. Every
+// group is encoded as a standalone ICO file containing all its images.
+//
+// See ExtractIcon for querying a single image of the main application icon.
+func (f Info) Icons() ([][]byte, error) {
+	icons, err := extractIcons(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract icons: %w", err)
+	}
+	return icons, nil
+}
+
+// ExtractIcon returns the application icon (the first icon group in the
+// resource section) of the given file encoded as an ICO file.
+//
+// If size is positive only the image with dimensions closest to size is
+// included into the result, otherwise the whole group is returned. Modern
+// icons often store large images as PNG, such images are kept as is inside
+// the ICO container. Use ExtractIconPNG to get a single image as PNG.
+func ExtractIcon(path string, size int) ([]byte, error) {
+	module, err := loadResourceModule(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %q as a resource module: %w", path, err)
+	}
+	defer windows.FreeLibrary(module) //nolint:errcheck
+
+	names, err := resourceNames(path, rtGroupIcon)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enumerate icon groups: %w", err)
+	}
+	if len(names) == 0 {
+		return nil, errors.New("file has no icons")
+	}
+	icon, err := buildIcon(module, names[0], size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build icon: %w", err)
+	}
+	return icon, nil
+}
+
+// ExtractIconPNG returns the image of the application icon with dimensions
+// closest to size (the largest one if size isn't positive) encoded as PNG.
+// Images already stored as PNG are returned as is, the bitmap ones are
+// converted keeping the transparency.
+func ExtractIconPNG(path string, size int) ([]byte, error) {
+	module, err := loadResourceModule(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %q as a resource module: %w", path, err)
+	}
+	defer windows.FreeLibrary(module) //nolint:errcheck
+
+	names, err := resourceNames(path, rtGroupIcon)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enumerate icon groups: %w", err)
+	}
+	if len(names) == 0 {
+		return nil, errors.New("file has no icons")
+	}
+	if size <= 0 {
+		size = 256
+	}
+	_, images, err := loadIconGroup(module, names[0], size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load icon: %w", err)
+	}
+	icon, err := iconPNG(images[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert icon to PNG: %w", err)
+	}
+	return icon, nil
+}
+
+func extractIcons(path string) ([][]byte, error) {
+	module, err := loadResourceModule(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %q as a resource module: %w", path, err)
+	}
+	defer windows.FreeLibrary(module) //nolint:errcheck
+
+	names, err := resourceNames(path, rtGroupIcon)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enumerate icon groups: %w", err)
+	}
+	icons := make([][]byte, 0, len(names))
+	for _, name := range names {
+		icon, err := buildIcon(module, name, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build icon %v: %w", name, err)
+		}
+		icons = append(icons, icon)
+	}
+	return icons, nil
+}
+
+// Resource types and LoadLibraryEx flags. Source:
+// https://docs.microsoft.com/en-us/windows/win32/menurc/resource-types
+const (
+	rtIcon      = 3
+	rtGroupIcon = 14
+
+	loadLibraryAsDatafile      = 0x00000002
+	loadLibraryAsImageResource = 0x00000020
+)
+
+//nolint:gochecknoglobals
+var kernel32 = windows.NewLazySystemDLL("kernel32.dll")
+
+// resourceName is either an integer resource ID or a string name.
+type resourceName struct {
+	id   uintptr
+	name string
+}
+
+// resource returns the name in the form accepted by the resource API.
+func (r resourceName) resource() windows.ResourceIDOrString {
+	if r.name == "" {
+		return windows.ResourceID(uint16(r.id))
+	}
+	return r.name
+}
+
+func loadResourceModule(path string) (windows.Handle, error) {
+	return windows.LoadLibraryEx(path, 0, loadLibraryAsDatafile|loadLibraryAsImageResource)
+}
+
+// resourceNames lists names of all the resources of the given type in the
+// directory order, the same order EnumResourceNames uses. The directory is
+// read by the pure Go walker, so names come as Go strings and no Windows
+// callback is needed.
+func resourceNames(path string, resType uint16) ([]resourceName, error) {
+	entries, err := ListResources(path)
+	if err != nil {
+		return nil, err
+	}
+	var names []resourceName
+	for _, e := range entries {
+		if e.Type.Name != "" || e.Type.ID != resType {
+			continue
+		}
+		name := resourceName{id: uintptr(e.Name.ID), name: e.Name.Name}
+		// Every language of a resource is a separate entry.
+		if n := len(names); n > 0 && names[n-1] == name {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// loadResource returns a raw resource data. The memory is owned by the module
+// so it's copied out.
+func loadResource(module windows.Handle, name resourceName, resType uint16) ([]byte, error) {
+	resInfo, err := windows.FindResource(module, name.resource(), windows.ResourceID(resType))
+	if err != nil {
+		return nil, err
+	}
+	data, err := windows.LoadResourceData(module, resInfo)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Icon group and ICO file layouts. Source:
+// https://devblogs.microsoft.com/oldnewthing/20120720-00/?p=7083
+type iconDir struct {
+	Reserved uint16
+	Type     uint16
+	Count    uint16
+}
+
+type grpIconDirEntry struct {
+	Width      uint8
+	Height     uint8
+	ColorCount uint8
+	Reserved   uint8
+	Planes     uint16
+	BitCount   uint16
+	BytesInRes uint32
+	ID         uint16
+}
+
+type iconDirEntry struct {
+	Width       uint8
+	Height      uint8
+	ColorCount  uint8
+	Reserved    uint8
+	Planes      uint16
+	BitCount    uint16
+	BytesInRes  uint32
+	ImageOffset uint32
+}
+
+// dimension returns a real image size: 0 in the directory means 256 pixels.
+func (e grpIconDirEntry) dimension() int {
+	if e.Width == 0 {
+		return 256
+	}
+	return int(e.Width)
+}
+
+// buildIcon assembles an ICO file from RT_GROUP_ICON resource and RT_ICON
+// images it references.
+func buildIcon(module windows.Handle, name resourceName, size int) ([]byte, error) {
+	entries, images, err := loadIconGroup(module, name, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	dir := iconDir{Type: 1, Count: uint16(len(entries))}
+	_ = binary.Write(&buf, binary.LittleEndian, dir)
+	offset := uint32(binary.Size(dir)) + uint32(len(entries))*uint32(binary.Size(iconDirEntry{}))
+	for i, e := range entries {
+		_ = binary.Write(&buf, binary.LittleEndian, iconDirEntry{
+			Width:       e.Width,
+			Height:      e.Height,
+			ColorCount:  e.ColorCount,
+			Planes:      e.Planes,
+			BitCount:    e.BitCount,
+			BytesInRes:  uint32(len(images[i])),
+			ImageOffset: offset,
+		})
+		offset += uint32(len(images[i]))
+	}
+	for _, img := range images {
+		buf.Write(img)
+	}
+	return buf.Bytes(), nil
+}
+
+// loadIconGroup loads the entries of the RT_GROUP_ICON resource and the RT_ICON
+// images they reference. If size is positive only the image with dimensions
+// closest to size is loaded.
+func loadIconGroup(module windows.Handle, name resourceName, size int) ([]grpIconDirEntry, [][]byte, error) {
+	group, err := loadResource(module, name, rtGroupIcon)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load icon group: %w", err)
+	}
+	r := bytes.NewReader(group)
+	var dir iconDir
+	if err := binary.Read(r, binary.LittleEndian, &dir); err != nil {
+		return nil, nil, fmt.Errorf("failed to read icon group header: %w", err)
+	}
+	entries := make([]grpIconDirEntry, dir.Count)
+	if err := binary.Read(r, binary.LittleEndian, entries); err != nil {
+		return nil, nil, fmt.Errorf("failed to read icon group entries: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, nil, errors.New("empty icon group")
+	}
+	if size > 0 {
+		entries = []grpIconDirEntry{closestIconEntry(entries, size)}
+	}
+
+	images := make([][]byte, len(entries))
+	for i, e := range entries {
+		images[i], err = loadResource(module, resourceName{id: uintptr(e.ID)}, rtIcon)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load icon image %d: %w", e.ID, err)
+		}
+	}
+	return entries, images, nil
+}
+
+// closestIconEntry chooses an image with the size closest to the requested one
+// preferring the deepest color for equal sizes.
+func closestIconEntry(entries []grpIconDirEntry, size int) grpIconDirEntry {
+	best := entries[0]
+	for _, e := range entries[1:] {
+		d, bestD := abs(e.dimension()-size), abs(best.dimension()-size)
+		if d < bestD || (d == bestD && e.BitCount > best.BitCount) {
+			best = e
+		}
+	}
+	return best
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,155 @@
+package fileversion
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// pngSignature starts the icon images stored as PNG, usually the 256 pixel
+// ones.
+//
+//nolint:gochecknoglobals
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// iconPNG returns an RT_ICON image as PNG: PNG images are returned as is,
+// the DIB ones are converted.
+func iconPNG(img []byte) ([]byte, error) {
+	if bytes.HasPrefix(img, pngSignature) {
+		return img, nil
+	}
+	m, err := decodeIconDIB(img)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, m); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// bitmapInfoHeader is BITMAPINFOHEADER. Source:
+// https://learn.microsoft.com/en-us/windows/win32/api/wingdi/ns-wingdi-bitmapinfoheader
+type bitmapInfoHeader struct {
+	Size          uint32
+	Width         int32
+	Height        int32
+	Planes        uint16
+	BitCount      uint16
+	Compression   uint32
+	SizeImage     uint32
+	XPelsPerMeter int32
+	YPelsPerMeter int32
+	ClrUsed       uint32
+	ClrImportant  uint32
+}
+
+// DIB compressions valid in icons.
+const (
+	biRGB       = 0
+	biBitfields = 3
+)
+
+// maxIconDimension limits the allocation for malformed icons, Windows icons
+// are at most 256 pixels.
+const maxIconDimension = 1024
+
+// decodeIconDIB decodes an icon image stored as a DIB: the header, the
+// palette, the color (XOR) bitmap and the transparency (AND) mask, both
+// bottom-up. The header height covers both bitmaps. 32-bit images carry the
+// alpha channel, the mask is used only if the alpha is all zeros.
+func decodeIconDIB(data []byte) (*image.NRGBA, error) {
+	var header bitmapInfoHeader
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("failed to read bitmap header: %w", err)
+	}
+	width, height := int(header.Width), int(header.Height)/2
+	switch {
+	case header.Size < uint32(binary.Size(header)) || uint64(header.Size) > uint64(len(data)):
+		return nil, fmt.Errorf("invalid bitmap header size %d", header.Size)
+	case width <= 0 || height <= 0 || width > maxIconDimension || height > maxIconDimension:
+		return nil, fmt.Errorf("invalid icon dimensions %dx%d", header.Width, header.Height)
+	case header.Compression != biRGB && !(header.Compression == biBitfields && header.BitCount == 32):
+		return nil, fmt.Errorf("unsupported bitmap compression %d", header.Compression)
+	}
+
+	bpp := int(header.BitCount)
+	var palette []color.NRGBA
+	switch bpp {
+	case 1, 4, 8:
+		colors := int(header.ClrUsed)
+		if colors == 0 || colors > 1<<bpp {
+			colors = 1 << bpp
+		}
+		raw := data[header.Size:]
+		if len(raw) < 4*colors {
+			return nil, errors.New("truncated bitmap palette")
+		}
+		palette = make([]color.NRGBA, colors)
+		for i := range palette {
+			palette[i] = color.NRGBA{R: raw[4*i+2], G: raw[4*i+1], B: raw[4*i], A: 0xff}
+		}
+	case 24, 32:
+	default:
+		return nil, fmt.Errorf("unsupported bit count %d", bpp)
+	}
+
+	pixels := data[int(header.Size)+4*len(palette):]
+	stride := (width*bpp + 31) / 32 * 4
+	maskStride := (width + 31) / 32 * 4
+	if len(pixels) < stride*height {
+		return nil, errors.New("truncated bitmap")
+	}
+	var mask []byte
+	if rest := pixels[stride*height:]; len(rest) >= maskStride*height {
+		mask = rest[:maskStride*height]
+	} else if bpp != 32 {
+		return nil, errors.New("truncated bitmap mask")
+	}
+
+	m := image.NewNRGBA(image.Rect(0, 0, width, height))
+	hasAlpha := false
+	for y := 0; y < height; y++ {
+		row := pixels[(height-1-y)*stride:]
+		for x := 0; x < width; x++ {
+			var c color.NRGBA
+			switch bpp {
+			case 32:
+				c = color.NRGBA{R: row[4*x+2], G: row[4*x+1], B: row[4*x], A: row[4*x+3]}
+				hasAlpha = hasAlpha || c.A != 0
+			case 24:
+				c = color.NRGBA{R: row[3*x+2], G: row[3*x+1], B: row[3*x], A: 0xff}
+			default:
+				// The pixels are packed from the most significant bits.
+				perByte := 8 / bpp
+				shift := uint(8 - bpp*(x%perByte+1))
+				index := int(row[x/perByte]>>shift) & (1<<bpp - 1)
+				if index >= len(palette) {
+					return nil, fmt.Errorf("invalid palette index %d", index)
+				}
+				c = palette[index]
+			}
+			m.SetNRGBA(x, y, c)
+		}
+	}
+	if bpp == 32 && hasAlpha {
+		return m, nil
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := m.NRGBAAt(x, y)
+			c.A = 0xff
+			// A 32-bit image without the mask is opaque.
+			if mask != nil && mask[(height-1-y)*maskStride+x/8]>>(7-uint(x%8))&1 != 0 {
+				c.A = 0
+			}
+			m.SetNRGBA(x, y, c)
+		}
+	}
+	return m, nil
+}
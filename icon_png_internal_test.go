@@ -0,0 +1,182 @@
+package fileversion
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// testDIB builds an icon DIB of the given size from the palette, the color
+// bitmap rows and the mask rows, bottom-up and already padded.
+func testDIB(width, height, bpp int, palette []color.NRGBA, pixels, mask []byte) []byte {
+	var buf bytes.Buffer
+	header := bitmapInfoHeader{
+		Width:    int32(width),
+		Height:   int32(2 * height),
+		Planes:   1,
+		BitCount: uint16(bpp),
+	}
+	header.Size = uint32(binary.Size(header))
+	_ = binary.Write(&buf, binary.LittleEndian, header)
+	for _, c := range palette {
+		buf.Write([]byte{c.B, c.G, c.R, 0})
+	}
+	buf.Write(pixels)
+	buf.Write(mask)
+	return buf.Bytes()
+}
+
+func decodePNG(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	m, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("png.Decode: %s", err)
+	}
+	return m
+}
+
+func checkPixels(t *testing.T, m image.Image, want [][]color.NRGBA) {
+	t.Helper()
+	if got := m.Bounds(); got != image.Rect(0, 0, len(want[0]), len(want)) {
+		t.Fatalf("bounds = %v, want %dx%d", got, len(want[0]), len(want))
+	}
+	for y, row := range want {
+		for x, c := range row {
+			if got := color.NRGBAModel.Convert(m.At(x, y)); got != c {
+				t.Errorf("pixel (%d, %d) = %v, want %v", x, y, got, c)
+			}
+		}
+	}
+}
+
+func TestIconPNGPassthrough(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	got, err := iconPNG(buf.Bytes())
+	if err != nil {
+		t.Fatalf("iconPNG: %s", err)
+	}
+	if !bytes.Equal(got, buf.Bytes()) {
+		t.Error("PNG image was re-encoded")
+	}
+}
+
+func TestIconPNG32Bit(t *testing.T) {
+	red := color.NRGBA{R: 0xff, A: 0xff}
+	half := color.NRGBA{G: 0xff, A: 0x80}
+	// Bottom row first: half-transparent green, then red and transparent.
+	pixels := []byte{
+		0, 0xff, 0, 0x80, 0, 0xff, 0, 0x80,
+		0, 0, 0xff, 0xff, 0, 0, 0, 0,
+	}
+	// The mask is set everywhere but ignored for the alpha channel.
+	mask := []byte{0xc0, 0, 0, 0, 0xc0, 0, 0, 0}
+	got, err := iconPNG(testDIB(2, 2, 32, nil, pixels, mask))
+	if err != nil {
+		t.Fatalf("iconPNG: %s", err)
+	}
+	checkPixels(t, decodePNG(t, got), [][]color.NRGBA{
+		{red, {}},
+		{half, half},
+	})
+}
+
+func TestIconPNG32BitWithoutAlpha(t *testing.T) {
+	blue := color.NRGBA{B: 0xff, A: 0xff}
+	pixels := []byte{0xff, 0, 0, 0, 0xff, 0, 0, 0}
+	tests := []struct {
+		name string
+		mask []byte
+		want [][]color.NRGBA
+	}{
+		{"mask", []byte{0x40, 0, 0, 0}, [][]color.NRGBA{{blue, {B: 0xff}}}},
+		{"no mask", nil, [][]color.NRGBA{{blue, blue}}},
+	}
+	for _, tt := range tests {
+		got, err := iconPNG(testDIB(2, 1, 32, nil, pixels, tt.mask))
+		if err != nil {
+			t.Fatalf("%s: iconPNG: %s", tt.name, err)
+		}
+		checkPixels(t, decodePNG(t, got), tt.want)
+	}
+}
+
+func TestIconPNG24Bit(t *testing.T) {
+	white := color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	gray := color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}
+	// 3 pixels take 9 bytes, padded to 12.
+	pixels := []byte{0xff, 0xff, 0xff, 0x80, 0x80, 0x80, 0xff, 0xff, 0xff, 0, 0, 0}
+	mask := []byte{0x20, 0, 0, 0}
+	got, err := iconPNG(testDIB(3, 1, 24, nil, pixels, mask))
+	if err != nil {
+		t.Fatalf("iconPNG: %s", err)
+	}
+	checkPixels(t, decodePNG(t, got), [][]color.NRGBA{{white, gray, {R: 0xff, G: 0xff, B: 0xff}}})
+}
+
+func TestIconPNGPaletted(t *testing.T) {
+	black := color.NRGBA{A: 0xff}
+	white := color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	palette := []color.NRGBA{black, white}
+	// Bottom row 10, top row 01; the top right pixel is transparent.
+	pixels := []byte{0x80, 0, 0, 0, 0x40, 0, 0, 0}
+	mask := []byte{0, 0, 0, 0, 0x40, 0, 0, 0}
+	got, err := iconPNG(testDIB(2, 2, 1, palette, pixels, mask))
+	if err != nil {
+		t.Fatalf("iconPNG: %s", err)
+	}
+	checkPixels(t, decodePNG(t, got), [][]color.NRGBA{
+		{black, {R: 0xff, G: 0xff, B: 0xff}},
+		{white, black},
+	})
+}
+
+func TestIconPNGMalformed(t *testing.T) {
+	palette := []color.NRGBA{{}, {}}
+	valid := testDIB(2, 2, 1, palette, make([]byte, 8), make([]byte, 8))
+	withHeader := func(fn func(h *bitmapInfoHeader)) []byte {
+		var h bitmapInfoHeader
+		_ = binary.Read(bytes.NewReader(valid), binary.LittleEndian, &h)
+		fn(&h)
+		var buf bytes.Buffer
+		_ = binary.Write(&buf, binary.LittleEndian, h)
+		buf.Write(valid[binary.Size(h):])
+		return buf.Bytes()
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"truncated header", valid[:20]},
+		{"truncated palette", valid[:binary.Size(bitmapInfoHeader{})+4]},
+		{"truncated bitmap", valid[:len(valid)-12]},
+		{"truncated mask", valid[:len(valid)-4]},
+		{"header size", withHeader(func(h *bitmapInfoHeader) { h.Size = 1 << 20 })},
+		{"zero width", withHeader(func(h *bitmapInfoHeader) { h.Width = 0 })},
+		{"negative height", withHeader(func(h *bitmapInfoHeader) { h.Height = -4 })},
+		{"huge", withHeader(func(h *bitmapInfoHeader) { h.Width = 1 << 20 })},
+		{"compression", withHeader(func(h *bitmapInfoHeader) { h.Compression = 1 })},
+		{"bit count", withHeader(func(h *bitmapInfoHeader) { h.BitCount = 16 })},
+	}
+	for _, tt := range tests {
+		if _, err := iconPNG(tt.data); err == nil {
+			t.Errorf("%s: iconPNG succeeded", tt.name)
+		}
+	}
+}
+
+func TestIconPNGPaletteIndex(t *testing.T) {
+	// ClrUsed limits the palette to a single color, the pixel takes the
+	// second one.
+	data := testDIB(1, 1, 1, []color.NRGBA{{}}, []byte{0x80, 0, 0, 0}, make([]byte, 4))
+	binary.LittleEndian.PutUint32(data[32:], 1)
+	if _, err := iconPNG(data); err == nil {
+		t.Error("iconPNG succeeded")
+	}
+}
@@ -0,0 +1,34 @@
+package fileversion
+
+import "fmt"
+
+// FileIdentity identifies a file on the machine regardless of its name: the
+// serial number of the volume and the file index (ID) on the volume. It stays
+// the same when the file is renamed or moved within the volume, but files on
+// FAT volumes may get a new index when they are modified.
+type FileIdentity struct {
+	VolumeSerialNumber uint32
+	FileIndex          uint64
+}
+
+// String returns the identity as "vvvvvvvv-iiiiiiiiiiiiiiii" hex digits.
+func (id FileIdentity) String() string {
+	return fmt.Sprintf("%08x-%016x", id.VolumeSerialNumber, id.FileIndex)
+}
+
+// Path returns the path the Info was created from (after normalization, see
+// WithPathNormalization). It's empty for Info values not backed by a file,
+// like ones created by NewFromReader.
+func (f Info) Path() string {
+	return f.path
+}
+
+// Identity returns the identity of the file captured by New, NewWithLocale
+// and NewFromHandle. It's false for Info values not backed by a file and if the
+// file couldn't be opened for reading its attributes.
+func (f Info) Identity() (FileIdentity, bool) {
+	if f.identity == nil {
+		return FileIdentity{}, false
+	}
+	return *f.identity, true
+}
@@ -0,0 +1,89 @@
+package fileversion
+
+import (
+	"errors"
+	"fmt"
+)
+
+// rootBlock returns the parsed version-information resource.
+func (f Info) rootBlock() (versionBlock, error) {
+	if f.tree != nil {
+		return f.tree.root, f.tree.err
+	}
+	return parseRootBlock(f.data)
+}
+
+// parseRootBlock parses the whole version-information resource.
+func parseRootBlock(data []byte) (versionBlock, error) {
+	if len(data) == 0 {
+		return versionBlock{}, errors.New("empty version-information resource")
+	}
+	root, _, err := parseVersionBlock(data)
+	if err != nil {
+		return versionBlock{}, fmt.Errorf("failed to parse version-information resource: %w: %v", ErrMalformedResource, err)
+	}
+	return root, nil
+}
+
+// Properties returns all the string properties of all the string tables in
+// the order they are stored in the resource. String tables with malformed
+// locale keys are skipped.
+func (f Info) Properties() ([]Property, error) {
+	if f.compact != nil {
+		properties := append([]Property(nil), f.compact.properties...)
+		for i := range properties {
+			properties[i].Value = f.normalize(properties[i].Value)
+		}
+		return properties, nil
+	}
+	root, err := f.rootBlock()
+	if err != nil {
+		return nil, err
+	}
+	var properties []Property
+	stringFileInfo, _ := root.child("StringFileInfo")
+	for _, table := range stringFileInfo.children {
+		locale, ok := parseLocaleKey(table.key)
+		if !ok {
+			continue
+		}
+		for _, s := range table.children {
+			properties = append(properties, Property{
+				PropertyKey: PropertyKey{Locale: locale, Name: s.key},
+				Value:       f.normalize(s.text()),
+			})
+		}
+	}
+	return properties, nil
+}
+
+// ActualStringTables returns the locales of the string tables present in
+// StringFileInfo in the resource order. They may differ from the declared
+// Translations, e.g. when a localized table is declared as 0409; Validate
+// reports such mismatches.
+func (f Info) ActualStringTables() ([]Locale, error) {
+	if f.compact != nil {
+		return append([]Locale(nil), f.compact.stringTables...), nil
+	}
+	root, err := f.rootBlock()
+	if err != nil {
+		return nil, err
+	}
+	return stringTableLocales(root), nil
+}
+
+// VarValues returns the raw values of all the Var blocks of VarFileInfo by
+// their keys, e.g. "Translation". The standard Translation value is decoded by
+// Translations, VarValues exposes the custom ones some vendors add. The
+// values are copies and may be modified. The map is empty if the resource
+// has no VarFileInfo; of duplicate keys the first one is kept.
+func (f Info) VarValues() (map[string][]byte, error) {
+	if f.compact != nil {
+		return copyVarValues(f.compact.vars), nil
+	}
+	root, err := f.rootBlock()
+	if err != nil {
+		return nil, err
+	}
+	return copyVarValues(varValues(root)), nil
+}
@@ -0,0 +1,92 @@
+package fileversion
+
+import (
+	"path"
+	"strings"
+)
+
+// InstalledProduct is an Add/Remove Programs entry read from an Uninstall
+// registry key.
+type InstalledProduct struct {
+	// Key is the full registry key of the entry, e.g.
+	// `HKLM\Software\Microsoft\Windows\CurrentVersion\Uninstall\{GUID}`.
+	Key             string
+	DisplayName     string
+	DisplayVersion  string
+	Publisher       string
+	InstallLocation string
+}
+
+// InstalledProduct returns the entry of products the file belongs to.
+//
+// A product matches if the file is located under its InstallLocation or if
+// its Publisher matches CompanyName (compared like Inventory does) and its
+// DisplayName contains ProductName ignoring case. The location match wins,
+// otherwise the first matching entry is returned.
+func (f Info) InstalledProduct(products []InstalledProduct) (InstalledProduct, bool) {
+	if f.path != "" {
+		file := cleanWindowsPath(f.path)
+		for _, p := range products {
+			if location, ok := installLocation(p.InstallLocation); ok && strings.HasPrefix(file, location+"/") {
+				return p, true
+			}
+		}
+	}
+	company := NormalizeCompanyName(f.CompanyName())
+	product := strings.ToLower(collapseSpaces(f.ProductName()))
+	if company == "" || product == "" {
+		return InstalledProduct{}, false
+	}
+	for _, p := range products {
+		if NormalizeCompanyName(p.Publisher) == company &&
+			strings.Contains(strings.ToLower(collapseSpaces(p.DisplayName)), product) {
+			return p, true
+		}
+	}
+	return InstalledProduct{}, false
+}
+
+// installLocation returns the cleaned InstallLocation, see cleanWindowsPath.
+// Installers often store it quoted. Volume roots like `C:\` are ignored,
+// they would match every file on the volume.
+func installLocation(location string) (string, bool) {
+	location = strings.TrimSpace(strings.Trim(strings.TrimSpace(location), `"`))
+	if location == "" {
+		return "", false
+	}
+	cleaned := cleanWindowsPath(location)
+	switch rest := strings.TrimPrefix(cleaned, windowsVolume(cleaned)); rest {
+	case "", "/":
+		return "", false
+	}
+	return cleaned, true
+}
+
+// cleanWindowsPath lower-cases the Windows path and cleans it with forward
+// slashes, so the paths compare the same way on every platform.
+func cleanWindowsPath(p string) string {
+	p = strings.ToLower(strings.ReplaceAll(p, `\`, "/"))
+	unc := strings.HasPrefix(p, "//")
+	p = path.Clean(p)
+	if unc {
+		// path.Clean drops the second slash of `//server/share`.
+		p = "/" + p
+	}
+	return p
+}
+
+// windowsVolume returns the drive (`c:`) or the UNC share (`//server/share`)
+// of a path cleaned by cleanWindowsPath.
+func windowsVolume(p string) string {
+	if len(p) >= 2 && p[1] == ':' {
+		return p[:2]
+	}
+	if strings.HasPrefix(p, "//") {
+		parts := strings.SplitN(p[2:], "/", 3)
+		if len(parts) >= 2 {
+			return "//" + parts[0] + "/" + parts[1]
+		}
+		return p
+	}
+	return ""
+}
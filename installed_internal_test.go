@@ -0,0 +1,45 @@
+package fileversion
+
+import "testing"
+
+func TestInstalledProductLocation(t *testing.T) {
+	info := Info{path: `C:\Program Files\Foo\bin\foo.exe`}
+	tests := []struct {
+		name     string
+		location string
+		want     bool
+	}{
+		{"plain", `C:\Program Files\Foo`, true},
+		{"trailing separator", `C:\Program Files\Foo\`, true},
+		{"quoted", `"C:\Program Files\Foo\"`, true},
+		{"quoted with spaces", ` "c:\program files\foo" `, true},
+		{"file itself", `C:\Program Files\Foo\bin\foo.exe`, false},
+		{"sibling prefix", `C:\Program Files\Fo`, false},
+		{"other directory", `C:\Program Files\Bar`, false},
+		{"volume root", `C:\`, false},
+		{"quoted volume root", `"C:\"`, false},
+		{"drive", `C:`, false},
+		{"share root", `\\server\share\`, false},
+		{"empty", `""`, false},
+	}
+	for _, tt := range tests {
+		products := []InstalledProduct{{DisplayName: tt.name, InstallLocation: tt.location}}
+		if _, got := info.InstalledProduct(products); got != tt.want {
+			t.Errorf("%s: InstalledProduct() with InstallLocation %q = %v, want %v", tt.name, tt.location, got, tt.want)
+		}
+	}
+
+	// A volume root listed first doesn't hide the real location.
+	products := []InstalledProduct{
+		{DisplayName: "Root", InstallLocation: `C:\`},
+		{DisplayName: "Foo", InstallLocation: `"C:\Program Files\Foo"`},
+	}
+	if p, ok := info.InstalledProduct(products); !ok || p.DisplayName != "Foo" {
+		t.Errorf("InstalledProduct() = %+v, %v, want Foo", p, ok)
+	}
+
+	share := Info{path: `\\server\share\Foo\foo.exe`}
+	if p, ok := share.InstalledProduct([]InstalledProduct{{DisplayName: "Foo", InstallLocation: `\\Server\Share\Foo`}}); !ok {
+		t.Errorf("InstalledProduct() on a share = %+v, %v, want a match", p, ok)
+	}
+}
@@ -0,0 +1,172 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+)
+
+// InstallerKind is an installer engine which built a setup executable.
+type InstallerKind int
+
+// Supported installer engines.
+const (
+	InstallerNone InstallerKind = iota
+	InstallerNSIS
+	InstallerInnoSetup
+)
+
+// String returns the engine name.
+func (k InstallerKind) String() string {
+	switch k {
+	case InstallerNSIS:
+		return "NSIS"
+	case InstallerInnoSetup:
+		return "Inno Setup"
+	default:
+		return "none"
+	}
+}
+
+// InstallerInfo describes a setup executable. The stub resource of a setup
+// usually describes the installer itself, AppName and AppVersion are the
+// installed application name and version where the engine records them.
+type InstallerInfo struct {
+	Kind InstallerKind
+	// EngineVersion is the version of the engine, e.g. "6.2.0". It's empty
+	// if the engine data doesn't record it.
+	EngineVersion string
+	AppName       string
+	AppVersion    string
+}
+
+// Installer detection constants. Sources:
+// https://github.com/kichik/nsis/blob/master/Source/exehead/fileform.h
+// https://github.com/jrsoftware/issrc/blob/main/Projects/Src/Shared.Struct.pas
+const (
+	// nsisHeaderAlignment is the alignment of the NSIS first header in the
+	// overlay, nsisMaxSearch limits the overlay scan.
+	nsisHeaderAlignment = 512
+	nsisMaxSearch       = 1 << 20
+	// innoOffsetTableID is the RT_RCDATA resource ID of the Inno Setup
+	// loader offset table.
+	innoOffsetTableID = 11111
+	rtRCData          = 10
+)
+
+//nolint:gochecknoglobals
+var (
+	nsisSignature          = []byte("\xef\xbe\xad\xdeNullsoftInst")
+	innoOffsetTablePrefix  = []byte("rDlPtS")
+	innoSetupDataSignature = regexp.MustCompile(`^Inno Setup Setup Data \(([0-9.]+)\)`)
+)
+
+// Installer detects NSIS and Inno Setup installers and returns the installed
+// application name and version. Both engines compress their own metadata, so
+// AppName and AppVersion are taken from the version info the engines write
+// to the stub (Inno Setup defaults VersionInfoProductName and
+// VersionInfoProductTextVersion to AppName and AppVersion, NSIS scripts set
+// them with VIAddVersionKey). The Kind is InstallerNone for other files.
+func (f Info) Installer() (InstallerInfo, error) {
+	raw, err := os.Open(f.path)
+	if err != nil {
+		return InstallerInfo{}, fmt.Errorf("failed to open %q: %w", f.path, err)
+	}
+	defer raw.Close()
+
+	file, err := pe.NewFile(raw)
+	if err != nil {
+		return InstallerInfo{}, fmt.Errorf("failed to parse PE headers of %q: %w", f.path, err)
+	}
+	defer file.Close()
+
+	info := InstallerInfo{}
+	switch {
+	case isNSIS(raw, overlayOffset(file)):
+		info.Kind = InstallerNSIS
+	default:
+		version, ok, err := innoSetupVersion(raw, file)
+		if err != nil {
+			return InstallerInfo{}, err
+		}
+		if !ok {
+			return InstallerInfo{}, nil
+		}
+		info.Kind = InstallerInnoSetup
+		info.EngineVersion = version
+	}
+	info.AppName = f.ProductName()
+	if info.AppVersion = f.ProductVersion(); info.AppVersion == "" {
+		info.AppVersion = f.FileVersion()
+	}
+	return info, nil
+}
+
+// overlayOffset returns the offset of the data appended to the image.
+func overlayOffset(file *pe.File) int64 {
+	var end int64
+	for _, s := range file.Sections {
+		if e := int64(s.Offset) + int64(s.Size); e > end {
+			end = e
+		}
+	}
+	return end
+}
+
+// isNSIS looks for the NSIS first header in the overlay.
+func isNSIS(r io.ReaderAt, overlay int64) bool {
+	start := (overlay + nsisHeaderAlignment - 1) / nsisHeaderAlignment * nsisHeaderAlignment
+	buf := make([]byte, 4+len(nsisSignature))
+	for offset := start; offset < start+nsisMaxSearch; offset += nsisHeaderAlignment {
+		if _, err := r.ReadAt(buf, offset); err != nil {
+			return false
+		}
+		// The signature follows the flags field.
+		if bytes.Equal(buf[4:], nsisSignature) {
+			return true
+		}
+	}
+	return false
+}
+
+// innoSetupVersion reads the Inno Setup loader offset table and the engine
+// version from the beginning of the setup data it points to.
+func innoSetupVersion(r io.ReaderAt, file *pe.File) (string, bool, error) {
+	entries, err := readResources(file, fileRVAReader(file))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read resources: %w", err)
+	}
+	for _, e := range entries {
+		if e.Type != (ResourceID{ID: rtRCData}) || e.Name != (ResourceID{ID: innoOffsetTableID}) {
+			continue
+		}
+		table, err := readRVA(file, e.rva, e.Size)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read Inno Setup offset table: %w", err)
+		}
+		if !bytes.HasPrefix(table, innoOffsetTablePrefix) {
+			return "", false, nil
+		}
+		// ID[12], Version, TotalSize, OffsetEXE, UncompressedSizeEXE, CRCEXE,
+		// Offset0 of the 32-bit table layouts. Newer layouts aren't decoded,
+		// the engine version is left empty for them.
+		const offset0Field = 12 + 5*4
+		if len(table) < offset0Field+4 {
+			return "", true, nil
+		}
+		header := make([]byte, 64)
+		n, _ := r.ReadAt(header, int64(binary.LittleEndian.Uint32(table[offset0Field:])))
+		if m := innoSetupDataSignature.FindSubmatch(header[:n]); m != nil {
+			return string(m[1]), true, nil
+		}
+		return "", true, nil
+	}
+	return "", false, nil
+}
@@ -0,0 +1,247 @@
+package fileversion
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Product is a single entry of a software inventory: a set of files sharing
+// the same vendor, product name and product version.
+type Product struct {
+	CompanyName    string
+	ProductName    string
+	ProductVersion string
+	Files          []string
+}
+
+// Inventory aggregates Info values to a deduplicated list of products.
+//
+// Files are grouped by (CompanyName, ProductName, ProductVersion). Vendor
+// names are compared in the form returned by NormalizeCompanyName, so
+// "Microsoft Corporation" and "Microsoft Corp." or names with a different
+// letter case and spacing end up in the same group. A file added again
+// (by a path equal ignoring case) is counted once. The zero value is not
+// usable, create it with NewInventory.
+type Inventory struct {
+	products map[productKey]*Product
+	// paths are the added files, see cleanWindowsPath.
+	paths map[string]bool
+}
+
+type productKey struct {
+	company string
+	product string
+	version string
+}
+
+// NewInventory creates an empty Inventory.
+func NewInventory() *Inventory {
+	return &Inventory{products: make(map[productKey]*Product), paths: make(map[string]bool)}
+}
+
+// Add adds a file described by info to the inventory. Files without both
+// CompanyName and ProductName are ignored since they can't be attributed to
+// any product, as are the files already added.
+func (inv *Inventory) Add(info Info) {
+	if info.path != "" {
+		file := cleanWindowsPath(info.path)
+		if inv.paths[file] {
+			return
+		}
+		inv.paths[file] = true
+	}
+	company := info.CompanyName()
+	product := info.ProductName()
+	if company == "" && product == "" {
+		return
+	}
+	version := strings.TrimSpace(info.ProductVersion())
+	key := productKey{
+		company: NormalizeCompanyName(company),
+		product: strings.ToLower(collapseSpaces(product)),
+		version: version,
+	}
+	p, ok := inv.products[key]
+	if !ok {
+		p = &Product{
+			CompanyName:    collapseSpaces(company),
+			ProductName:    collapseSpaces(product),
+			ProductVersion: version,
+		}
+		inv.products[key] = p
+	}
+	if info.path != "" {
+		p.Files = append(p.Files, info.path)
+	}
+}
+
+// Products returns the aggregated products sorted by company, product name
+// and version.
+func (inv *Inventory) Products() []Product {
+	products := make([]Product, 0, len(inv.products))
+	for _, p := range inv.products {
+		product := *p
+		product.Files = append([]string(nil), p.Files...)
+		products = append(products, product)
+	}
+	sort.Slice(products, func(i, j int) bool {
+		a, b := products[i], products[j]
+		if a.CompanyName != b.CompanyName {
+			return a.CompanyName < b.CompanyName
+		}
+		if a.ProductName != b.ProductName {
+			return a.ProductName < b.ProductName
+		}
+		return a.ProductVersion < b.ProductVersion
+	})
+	return products
+}
+
+// Vendor name normalization tables. companySuffixes are legal-form suffixes
+// dropped while comparing vendor names, companyAliases map normalized
+// variants to the canonical normalized names. ambiguousCompanySuffixes are
+// also real name words ("Acme Co", "Foo SA"), they are dropped only when
+// written with punctuation like "(R)", ", Co." or "S.A.".
+//
+//nolint:gochecknoglobals
+var (
+	companyTablesMu sync.RWMutex
+	companySuffixes = map[string]bool{
+		"corporation": true, "corp": true, "incorporated": true, "inc": true, "limited": true, "ltd": true,
+		"llc": true, "gmbh": true, "co": true, "company": true, "ag": true, "sa": true, "srl": true, "bv": true,
+		"plc": true, "r": true, "tm": true,
+	}
+	ambiguousCompanySuffixes = map[string]bool{"co": true, "sa": true, "r": true, "tm": true}
+	companyAliases           = map[string]string{
+		"adobe systems":      "adobe",
+		"apple computer":     "apple",
+		"mozilla foundation": "mozilla",
+		"oracle america":     "oracle",
+		"sun microsystems":   "oracle",
+	}
+)
+
+// NormalizeCompanyName returns the form of the vendor name Inventory groups
+// files by: lowercased, without punctuation, trademark signs, a leading
+// "The" and legal-form suffixes ("Microsoft Corporation", "Microsoft Corp."
+// and "microsoft" are all "microsoft"), and mapped through the aliases (see
+// RegisterCompanyAlias), so "Adobe Systems Incorporated" is "adobe". The
+// suffixes that are also name words are dropped only when written with
+// punctuation: "Acme, Co." and "Foo S.A." are "acme" and "foo", but "Acme Co"
+// and "Foo SA" are kept as they are.
+func NormalizeCompanyName(name string) string {
+	companyTablesMu.RLock()
+	defer companyTablesMu.RUnlock()
+	key := stripCompanyName(name)
+	if canonical, ok := companyAliases[key]; ok {
+		return canonical
+	}
+	return key
+}
+
+// RegisterCompanyAlias makes NormalizeCompanyName return the normalized
+// canonical name for the vendor name alias, e.g.
+//
+//	fileversion.RegisterCompanyAlias("Contoso Europe B.V.", "Contoso")
+//
+// Both names are normalized first, so the legal forms don't matter.
+func RegisterCompanyAlias(alias, canonical string) {
+	companyTablesMu.Lock()
+	defer companyTablesMu.Unlock()
+	companyAliases[stripCompanyName(alias)] = stripCompanyName(canonical)
+}
+
+// RegisterCompanySuffix adds legal-form suffixes NormalizeCompanyName drops,
+// like "kk" or "oy". The suffixes are compared in lower case without dots.
+func RegisterCompanySuffix(suffixes ...string) {
+	companyTablesMu.Lock()
+	defer companyTablesMu.Unlock()
+	for _, suffix := range suffixes {
+		if suffix = stripCompanyPunctuation(suffix); suffix != "" {
+			companySuffixes[suffix] = true
+		}
+	}
+}
+
+// stripCompanyName normalizes the name without the aliases. The tables must
+// be locked by the caller.
+func stripCompanyName(name string) string {
+	words := companyWords(name)
+	if len(words) > 1 && words[0].text == "the" {
+		words = words[1:]
+	}
+	for len(words) > 1 {
+		last := words[len(words)-1]
+		if !companySuffixes[last.text] || ambiguousCompanySuffixes[last.text] && !last.punctuated {
+			break
+		}
+		words = words[:len(words)-1]
+	}
+	texts := make([]string, len(words))
+	for i, w := range words {
+		texts[i] = w.text
+	}
+	return strings.Join(texts, " ")
+}
+
+// companyWord is a word of a vendor name. punctuated is set if it's written
+// with dots, in parentheses or after a comma.
+type companyWord struct {
+	text       string
+	punctuated bool
+}
+
+// companyWords splits the name like stripCompanyPunctuation does.
+func companyWords(name string) []companyWord {
+	var words []companyWord
+	var word strings.Builder
+	// punctuated marks the word being read.
+	punctuated := false
+	end := func() {
+		if word.Len() != 0 {
+			words = append(words, companyWord{text: word.String(), punctuated: punctuated})
+			word.Reset()
+			punctuated = false
+		}
+	}
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r == '.' || r == ')':
+			switch {
+			case word.Len() != 0:
+				punctuated = true
+			case len(words) != 0:
+				// The dot of "(Co).".
+				words[len(words)-1].punctuated = true
+			}
+			if r == ')' {
+				end()
+			}
+		case r == ',' || r == '(':
+			end()
+			punctuated = true
+		case r == '®' || r == '™' || r == '©' || unicode.IsSpace(r):
+			end()
+		default:
+			word.WriteRune(r)
+		}
+	}
+	end()
+	return words
+}
+
+// stripCompanyPunctuation lowercases the name and replaces the punctuation
+// with spaces. Dots are dropped, so "B.V." is "bv".
+func stripCompanyPunctuation(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.':
+			return -1
+		case ',', '(', ')', '®', '™', '©':
+			return ' '
+		}
+		return r
+	}, strings.ToLower(name))
+}
@@ -0,0 +1,44 @@
+package fileversion
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testInfo returns an Info of the file with the properties.
+func testInfo(path, company, product, version string) Info {
+	locale := Locale{LangID: LangEnglishUS, CharsetID: CSUnicode}
+	return Info{
+		path:    path,
+		Locales: []Locale{locale},
+		compact: &compactInfo{values: map[PropertyKey]string{
+			{Locale: locale, Name: string(PropCompanyName)}:    company,
+			{Locale: locale, Name: string(PropProductName)}:    product,
+			{Locale: locale, Name: string(PropProductVersion)}: version,
+		}},
+	}
+}
+
+func TestInventoryAdd(t *testing.T) {
+	inv := NewInventory()
+	inv.Add(testInfo(`C:\Program Files\Foo\foo.exe`, "Foo Corporation", "Foo", "1.0"))
+	// The same file by another spelling of the path.
+	inv.Add(testInfo(`c:\program files\FOO\.\foo.exe`, "Foo Corporation", "Foo", "1.0"))
+	inv.Add(testInfo(`C:\Program Files\Foo\bin\..\foo.exe`, "Foo Corp.", "Foo", "1.0"))
+	inv.Add(testInfo(`C:\Program Files\Foo\foo.dll`, "foo corp", "FOO", "1.0"))
+	// Files without a path are not deduplicated.
+	inv.Add(testInfo("", "Foo Corporation", "Foo", "1.0"))
+	inv.Add(testInfo("", "Foo Corporation", "Foo", "1.0"))
+	// Not attributable.
+	inv.Add(testInfo(`C:\Windows\anon.exe`, "", "", "1.0"))
+
+	want := []Product{{
+		CompanyName:    "Foo Corporation",
+		ProductName:    "Foo",
+		ProductVersion: "1.0",
+		Files:          []string{`C:\Program Files\Foo\foo.exe`, `C:\Program Files\Foo\foo.dll`},
+	}}
+	if got := inv.Products(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Products() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,45 @@
+package fileversion_test
+
+import (
+	"testing"
+
+	"github.com/bi-zone/go-fileversion"
+)
+
+func TestNormalizeCompanyName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Microsoft Corporation", "microsoft"},
+		{"Microsoft Corp.", "microsoft"},
+		{"  MICROSOFT   corp ", "microsoft"},
+		{"The Document Foundation", "document foundation"},
+		{"The", "the"},
+		{"Adobe Systems Incorporated", "adobe"},
+		{"Google LLC", "google"},
+		{"Contoso Europe B.V.", "contoso europe"},
+		{"Foo Co., Ltd.", "foo"},
+		{"Foo GmbH & Co. KG", "foo gmbh & co kg"},
+		{"Contoso(R)", "contoso"},
+		{"Contoso (r)", "contoso"},
+		{"Contoso™", "contoso"},
+		{"Contoso (TM)", "contoso"},
+		{"Contoso, Inc.", "contoso"},
+		// The ambiguous suffixes need the punctuation.
+		{"Acme Co", "acme co"},
+		{"Acme Co.", "acme"},
+		{"Acme, Co", "acme"},
+		{"Foo SA", "foo sa"},
+		{"Foo S.A.", "foo"},
+		{"Studio R", "studio r"},
+		{"Foo TM", "foo tm"},
+		{"Corporation", "corporation"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := fileversion.NormalizeCompanyName(tt.name); got != tt.want {
+			t.Errorf("NormalizeCompanyName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
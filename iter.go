@@ -0,0 +1,44 @@
+//go:build go1.23
+// +build go1.23
+
+package fileversion
+
+import "iter"
+
+// All returns an iterator over all the string properties of the resource.
+// Every property is yielded once with the value chosen like GetProperty does.
+// Properties are yielded in the resource order, nothing is yielded if the
+// resource can't be parsed.
+func (f Info) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		properties, err := f.Properties()
+		if err != nil {
+			return
+		}
+		for _, name := range propertyNames(properties) {
+			value, err := f.GetProperty(name)
+			if err != nil {
+				continue
+			}
+			if !yield(name, value) {
+				return
+			}
+		}
+	}
+}
+
+// AllWithLocales returns an iterator over every translation of every string
+// property in the resource order. See Properties.
+func (f Info) AllWithLocales() iter.Seq2[PropertyKey, string] {
+	return func(yield func(PropertyKey, string) bool) {
+		properties, err := f.Properties()
+		if err != nil {
+			return
+		}
+		for _, p := range properties {
+			if !yield(p.PropertyKey, p.Value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package fileversion
+
+import (
+	"fmt"
+)
+
+// String returns the locale in the 8-hex-digit form used for string table
+// names, e.g. "040904b0".
+func (l Locale) String() string {
+	return fmt.Sprintf("%04x%04x", uint16(l.LangID), uint16(l.CharsetID))
+}
+
+// ParseLocale parses an 8-hex-digit translation string like "040904B0" (the
+// form used by string table names, registry values and resource scripts).
+func ParseLocale(s string) (Locale, error) {
+	locale, ok := parseLocaleKey(s)
+	if !ok {
+		return Locale{}, fmt.Errorf("invalid locale %q: want 8 hex digits", s)
+	}
+	return locale, nil
+}
+
+// Tag returns a BCP-47 language tag of the locale language, e.g. "en-US".
+// LangNeutral is reported as "und". An empty string is returned for the
+// languages not known by the package (or by Windows, see LangID.String).
+func (l Locale) Tag() string {
+	lang, _ := lookupLanguage(l.LangID)
+	return lang.tag
+}
+
+// language describes a LangID.
+type language struct {
+	tag  string
+	name string
+}
+
+// languages maps the languages listed in `langID` section of
+// https://docs.microsoft.com/en-us/windows/win32/menurc/versioninfo-resource
+// and other common ones from
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-lcid
+// to BCP-47 tags and English names.
+//
+// It's deliberately a subset: the full LCID list has several hundred
+// entries, mostly for locales no version resource is written in. On
+// Windows the rest are resolved with LCIDToLocaleName and GetLocaleInfoEx,
+// on the other platforms they are reported by LangID.String as hex codes.
+//
+//nolint:gochecknoglobals
+var languages = map[LangID]language{
+	0x0000: {"und", "Neutral"},
+	0x0401: {"ar-SA", "Arabic (Saudi Arabia)"},
+	0x0402: {"bg-BG", "Bulgarian (Bulgaria)"},
+	0x0403: {"ca-ES", "Catalan (Spain)"},
+	0x0404: {"zh-TW", "Chinese (Traditional, Taiwan)"},
+	0x0405: {"cs-CZ", "Czech (Czech Republic)"},
+	0x0406: {"da-DK", "Danish (Denmark)"},
+	0x0407: {"de-DE", "German (Germany)"},
+	0x0408: {"el-GR", "Greek (Greece)"},
+	0x0409: {"en-US", "English (United States)"},
+	0x040A: {"es-ES", "Spanish (Spain, Traditional Sort)"},
+	0x040B: {"fi-FI", "Finnish (Finland)"},
+	0x040C: {"fr-FR", "French (France)"},
+	0x040D: {"he-IL", "Hebrew (Israel)"},
+	0x040E: {"hu-HU", "Hungarian (Hungary)"},
+	0x040F: {"is-IS", "Icelandic (Iceland)"},
+	0x0410: {"it-IT", "Italian (Italy)"},
+	0x0411: {"ja-JP", "Japanese (Japan)"},
+	0x0412: {"ko-KR", "Korean (Korea)"},
+	0x0413: {"nl-NL", "Dutch (Netherlands)"},
+	0x0414: {"nb-NO", "Norwegian, Bokmal (Norway)"},
+	0x0415: {"pl-PL", "Polish (Poland)"},
+	0x0416: {"pt-BR", "Portuguese (Brazil)"},
+	0x0417: {"rm-CH", "Romansh (Switzerland)"},
+	0x0418: {"ro-RO", "Romanian (Romania)"},
+	0x0419: {"ru-RU", "Russian (Russia)"},
+	0x041A: {"hr-HR", "Croatian (Croatia)"},
+	0x041B: {"sk-SK", "Slovak (Slovakia)"},
+	0x041C: {"sq-AL", "Albanian (Albania)"},
+	0x041D: {"sv-SE", "Swedish (Sweden)"},
+	0x041E: {"th-TH", "Thai (Thailand)"},
+	0x041F: {"tr-TR", "Turkish (Turkey)"},
+	0x0420: {"ur-PK", "Urdu (Pakistan)"},
+	0x0421: {"id-ID", "Indonesian (Indonesia)"},
+	0x0422: {"uk-UA", "Ukrainian (Ukraine)"},
+	0x0423: {"be-BY", "Belarusian (Belarus)"},
+	0x0424: {"sl-SI", "Slovenian (Slovenia)"},
+	0x0425: {"et-EE", "Estonian (Estonia)"},
+	0x0426: {"lv-LV", "Latvian (Latvia)"},
+	0x0427: {"lt-LT", "Lithuanian (Lithuania)"},
+	0x0429: {"fa-IR", "Persian (Iran)"},
+	0x042A: {"vi-VN", "Vietnamese (Vietnam)"},
+	0x042B: {"hy-AM", "Armenian (Armenia)"},
+	0x042D: {"eu-ES", "Basque (Spain)"},
+	0x042F: {"mk-MK", "Macedonian (North Macedonia)"},
+	0x0436: {"af-ZA", "Afrikaans (South Africa)"},
+	0x0437: {"ka-GE", "Georgian (Georgia)"},
+	0x0439: {"hi-IN", "Hindi (India)"},
+	0x043E: {"ms-MY", "Malay (Malaysia)"},
+	0x043F: {"kk-KZ", "Kazakh (Kazakhstan)"},
+	0x0441: {"sw-KE", "Swahili (Kenya)"},
+	0x0443: {"uz-Latn-UZ", "Uzbek (Latin, Uzbekistan)"},
+	0x0445: {"bn-IN", "Bangla (India)"},
+	0x0449: {"ta-IN", "Tamil (India)"},
+	0x0456: {"gl-ES", "Galician (Spain)"},
+	0x0804: {"zh-CN", "Chinese (Simplified, China)"},
+	0x0807: {"de-CH", "German (Switzerland)"},
+	0x0809: {"en-GB", "English (United Kingdom)"},
+	0x080A: {"es-MX", "Spanish (Mexico)"},
+	0x080C: {"fr-BE", "French (Belgium)"},
+	0x0810: {"it-CH", "Italian (Switzerland)"},
+	0x0813: {"nl-BE", "Dutch (Belgium)"},
+	0x0814: {"nn-NO", "Norwegian, Nynorsk (Norway)"},
+	0x0816: {"pt-PT", "Portuguese (Portugal)"},
+	0x081A: {"sr-Latn-CS", "Serbian (Latin, Serbia and Montenegro)"},
+	0x0C04: {"zh-HK", "Chinese (Traditional, Hong Kong SAR)"},
+	0x0C07: {"de-AT", "German (Austria)"},
+	0x0C09: {"en-AU", "English (Australia)"},
+	0x0C0A: {"es-ES", "Spanish (Spain)"},
+	0x0C0C: {"fr-CA", "French (Canada)"},
+	0x0C1A: {"sr-Cyrl-CS", "Serbian (Cyrillic, Serbia and Montenegro)"},
+	0x1004: {"zh-SG", "Chinese (Simplified, Singapore)"},
+	0x1009: {"en-CA", "English (Canada)"},
+	0x100C: {"fr-CH", "French (Switzerland)"},
+	0x1409: {"en-NZ", "English (New Zealand)"},
+	0x1809: {"en-IE", "English (Ireland)"},
+	0x2C0A: {"es-AR", "Spanish (Argentina)"},
+}
+
+// charsets maps the charsets listed in `charsetID` section of
+// https://docs.microsoft.com/en-us/windows/win32/menurc/versioninfo-resource
+// and the other Windows ANSI code pages from
+// https://docs.microsoft.com/en-us/windows/win32/intl/code-page-identifiers
+// to their names. Version resources are not written in the OEM and the
+// ISO code pages, these are reported by CharsetID.String with the number.
+//
+// Zero is CP_ACP: the strings Windows converts with charset 0 are taken in
+// the system default ANSI code page. The VERSIONINFO docs call it 7-bit
+// ASCII, which that code page is only for the ASCII strings.
+//
+//nolint:gochecknoglobals
+var charsets = map[CharsetID]string{
+	0:    "ANSI (system default code page)",
+	874:  "Thai (Windows-874)",
+	932:  "Japanese (Shift JIS)",
+	936:  "Chinese Simplified (GBK)",
+	949:  "Korean (Unified Hangul)",
+	950:  "Chinese Traditional (Big5)",
+	1200: "Unicode",
+	1250: "Central European (Windows-1250)",
+	1251: "Cyrillic (Windows-1251)",
+	1252: "Western European (Windows-1252)",
+	1253: "Greek (Windows-1253)",
+	1254: "Turkish (Windows-1254)",
+	1255: "Hebrew (Windows-1255)",
+	1256: "Arabic (Windows-1256)",
+	1257: "Baltic (Windows-1257)",
+	1258: "Vietnamese (Windows-1258)",
+}
+
+// String returns the English name of the language, e.g. "German (Germany)",
+// or its hex code like "0x0462" for the unknown languages. The languages
+// missing in the table are looked up in the Windows NLS data, so on other
+// platforms more of them are reported by the code.
+func (l LangID) String() string {
+	if lang, ok := lookupLanguage(l); ok {
+		return lang.name
+	}
+	return fmt.Sprintf("0x%04x", uint16(l))
+}
+
+// lookupLanguage describes the language with the table or the system.
+func lookupLanguage(id LangID) (language, bool) {
+	if lang, ok := languages[id]; ok {
+		return lang, true
+	}
+	return systemLanguage(id)
+}
+
+// String returns the name of the charset, e.g. "Unicode", or its decimal
+// number like "code page 437" for unknown charsets.
+func (c CharsetID) String() string {
+	if name, ok := charsets[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("code page %d", uint16(c))
+}
+
+// Description returns a human-readable form of the locale, e.g.
+// "German (Germany), Unicode". Use String for the string table name form.
+func (l Locale) Description() string {
+	return l.LangID.String() + ", " + l.CharsetID.String()
+}
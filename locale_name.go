@@ -0,0 +1,59 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+//nolint:gochecknoglobals
+var (
+	lcidToLocaleNameProc = kernel32.NewProc("LCIDToLocaleName")
+	getLocaleInfoExProc  = kernel32.NewProc("GetLocaleInfoEx")
+
+	// systemLanguages caches the lookups, including the failed ones.
+	systemLanguages sync.Map
+)
+
+// NLS constants. Source:
+// https://learn.microsoft.com/en-us/windows/win32/intl/locale-information-constants
+const (
+	localeNameMaxLength       = 85
+	localeAllowNeutralNames   = 0x08000000
+	localeSEnglishDisplayName = 0x00000072
+)
+
+// systemLanguage describes the languages missing in the languages table with
+// LCIDToLocaleName and GetLocaleInfoEx.
+func systemLanguage(id LangID) (language, bool) {
+	if cached, ok := systemLanguages.Load(id); ok {
+		lang := cached.(language)
+		return lang, lang.tag != ""
+	}
+	lang := lookupSystemLanguage(id)
+	systemLanguages.Store(id, lang)
+	return lang, lang.tag != ""
+}
+
+func lookupSystemLanguage(id LangID) language {
+	if lcidToLocaleNameProc.Find() != nil || getLocaleInfoExProc.Find() != nil {
+		return language{}
+	}
+	var tag [localeNameMaxLength]uint16
+	n, _, _ := lcidToLocaleNameProc.Call(uintptr(id), uintptr(unsafe.Pointer(&tag[0])), uintptr(len(tag)),
+		localeAllowNeutralNames)
+	if n == 0 {
+		return language{}
+	}
+	var name [256]uint16
+	n, _, _ = getLocaleInfoExProc.Call(uintptr(unsafe.Pointer(&tag[0])), localeSEnglishDisplayName,
+		uintptr(unsafe.Pointer(&name[0])), uintptr(len(name)))
+	if n == 0 {
+		return language{}
+	}
+	return language{tag: windows.UTF16ToString(tag[:]), name: windows.UTF16ToString(name[:n])}
+}
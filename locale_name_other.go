@@ -0,0 +1,10 @@
+//go:build !windows
+// +build !windows
+
+package fileversion
+
+// systemLanguage reports the languages missing in the languages table as
+// unknown: the NLS API resolving the rest is only available on Windows.
+func systemLanguage(LangID) (language, bool) {
+	return language{}, false
+}
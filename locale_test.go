@@ -0,0 +1,29 @@
+package fileversion_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/bi-zone/go-fileversion"
+)
+
+func TestLocaleDescription(t *testing.T) {
+	tests := []struct {
+		locale fileversion.Locale
+		want   string
+	}{
+		{fileversion.Locale{LangID: 0x0407, CharsetID: 1200}, "German (Germany), Unicode"},
+		{fileversion.Locale{LangID: 0x0409, CharsetID: 0}, "English (United States), ANSI (system default code page)"},
+		// Missing in the tables.
+		{fileversion.Locale{LangID: 0x0462, CharsetID: 437}, "0x0462, code page 437"},
+	}
+	if runtime.GOOS == "windows" {
+		// Windows knows Frisian.
+		tests = tests[:len(tests)-1]
+	}
+	for _, tt := range tests {
+		if got := tt.locale.Description(); got != tt.want {
+			t.Errorf("%s.Description() = %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import "regexp"
+
+// Rule is a declarative check of version properties. All the non-nil fields
+// must match for the rule to match, an empty Rule matches any file.
+//
+// Regular expressions are matched against the values GetProperty returns, a
+// missing property is matched as an empty string. For example, unsigned
+// binaries claiming to be Microsoft ones are
+//
+//	unsigned := false
+//	rule := fileversion.Rule{
+//		Name:        "fake-microsoft",
+//		CompanyName: regexp.MustCompile(`(?i)^microsoft`),
+//		Signed:      &unsigned,
+//	}
+type Rule struct {
+	// Name identifies the rule in MatchRules results.
+	Name string
+
+	CompanyName      *regexp.Regexp
+	ProductName      *regexp.Regexp
+	FileDescription  *regexp.Regexp
+	OriginalFilename *regexp.Regexp
+	InternalName     *regexp.Regexp
+	// Properties matches arbitrary properties by name.
+	Properties map[string]*regexp.Regexp
+
+	// FileVersion and ProductVersion constrain the binary versions from the
+	// fixed info.
+	FileVersion    VersionConstraint
+	ProductVersion VersionConstraint
+
+	// Signed requires the file to be signed (or unsigned) as IsSigned
+	// reports. It's checked last as it's the most expensive condition. Files
+	// whose signature can't be checked don't match either way.
+	Signed *bool
+}
+
+// RuleMatch is a result of MatchAll: the Info and the names of the rules it
+// matches.
+type RuleMatch struct {
+	Info  Info
+	Rules []string
+}
+
+// Match reports whether info matches the rule.
+func Match(info Info, rule Rule) bool {
+	m := ruleMatcher{info: info}
+	return m.match(rule)
+}
+
+// MatchRules returns the names of the matching rules in the rules order.
+func MatchRules(info Info, rules []Rule) []string {
+	m := ruleMatcher{info: info}
+	var matched []string
+	for _, rule := range rules {
+		if m.match(rule) {
+			matched = append(matched, rule.Name)
+		}
+	}
+	return matched
+}
+
+// MatchAll evaluates the rule set over many files, e.g. the results of a
+// scan, and returns the files matching at least one rule in the infos order.
+// The signature of every file is checked at most once for all the rules.
+func MatchAll(infos []Info, rules []Rule) []RuleMatch {
+	var matches []RuleMatch
+	for _, info := range infos {
+		if matched := MatchRules(info, rules); len(matched) != 0 {
+			matches = append(matches, RuleMatch{Info: info, Rules: matched})
+		}
+	}
+	return matches
+}
+
+// ruleMatcher matches rules against a single Info caching the signature
+// check.
+type ruleMatcher struct {
+	info     Info
+	verified bool
+	signed   bool
+	signErr  error
+}
+
+func (m *ruleMatcher) match(rule Rule) bool {
+	info := m.info
+	properties := []struct {
+		name string
+		re   *regexp.Regexp
+	}{
+		{string(PropCompanyName), rule.CompanyName},
+		{string(PropProductName), rule.ProductName},
+		{string(PropFileDescription), rule.FileDescription},
+		{string(PropOriginalFilename), rule.OriginalFilename},
+		{string(PropInternalName), rule.InternalName},
+	}
+	for _, p := range properties {
+		if p.re != nil && !matchProperty(info, p.name, p.re) {
+			return false
+		}
+	}
+	for name, re := range rule.Properties {
+		if re != nil && !matchProperty(info, name, re) {
+			return false
+		}
+	}
+	if rule.FileVersion != nil || rule.ProductVersion != nil {
+		fixed := info.FixedInfo()
+		if !rule.FileVersion.Match(fixed.FileVersion) || !rule.ProductVersion.Match(fixed.ProductVersion) {
+			return false
+		}
+	}
+	if rule.Signed == nil {
+		return true
+	}
+	if !m.verified {
+		m.signed, m.signErr = info.IsSigned()
+		m.verified = true
+	}
+	return m.signErr == nil && m.signed == *rule.Signed
+}
+
+func matchProperty(info Info, name string, re *regexp.Regexp) bool {
+	value, _ := info.GetProperty(name)
+	return re.MatchString(value)
+}
@@ -0,0 +1,175 @@
+package fileversion
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf16"
+)
+
+// ModuleVersion describes a module recorded in a minidump.
+type ModuleVersion struct {
+	Name          string
+	BaseOfImage   uint64
+	SizeOfImage   uint32
+	TimeDateStamp uint32
+	// FixedInfo is a copy of the module VS_FIXEDFILEINFO made at the dump
+	// time. It's zero if the module had no version resource.
+	FixedInfo FixedFileInfo
+	// DebugInfo is zero if the dump has no CodeView record for the module.
+	DebugInfo DebugInfo
+}
+
+// Minidump layout. Source:
+// https://docs.microsoft.com/en-us/windows/win32/api/minidumpapiset/
+const (
+	minidumpSignature        = 0x504d444d // "MDMP"
+	minidumpModuleListStream = 4
+	// maxMinidumpStreams and maxMinidumpModules limit the reads and the
+	// allocation for malformed dumps.
+	maxMinidumpStreams = 1 << 16
+	maxMinidumpModules = 1 << 16
+	// maxMinidumpString limits module names allocation.
+	maxMinidumpString = 1 << 16
+)
+
+type minidumpHeader struct {
+	Signature          uint32
+	Version            uint32
+	NumberOfStreams    uint32
+	StreamDirectoryRva uint32
+	CheckSum           uint32
+	TimeDateStamp      uint32
+	Flags              uint64
+}
+
+type minidumpLocation struct {
+	DataSize uint32
+	Rva      uint32
+}
+
+type minidumpDirectory struct {
+	StreamType uint32
+	Location   minidumpLocation
+}
+
+type minidumpModule struct {
+	BaseOfImage   uint64
+	SizeOfImage   uint32
+	CheckSum      uint32
+	TimeDateStamp uint32
+	ModuleNameRva uint32
+	VersionInfo   rawFixedFileInfo
+	CvRecord      minidumpLocation
+	MiscRecord    minidumpLocation
+	Reserved0     uint64
+	Reserved1     uint64
+}
+
+// ReadMinidumpModules returns the modules recorded in ModuleListStream of the
+// minidump at path. The versions are taken from the dump itself, so the
+// original binaries are not needed.
+func ReadMinidumpModules(path string) ([]ModuleVersion, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open minidump: %w", err)
+	}
+	defer file.Close()
+	modules, err := minidumpModules(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read modules from %q: %w", path, err)
+	}
+	return modules, nil
+}
+
+func minidumpModules(r io.ReaderAt) ([]ModuleVersion, error) {
+	var header minidumpHeader
+	if err := readStructAt(r, 0, &header); err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	if header.Signature != minidumpSignature {
+		return nil, errors.New("not a minidump file")
+	}
+
+	if header.NumberOfStreams > maxMinidumpStreams {
+		return nil, fmt.Errorf("too many streams: %d", header.NumberOfStreams)
+	}
+	var list minidumpLocation
+	found := false
+	for i := uint32(0); i < header.NumberOfStreams; i++ {
+		var dir minidumpDirectory
+		if err := readStructAt(r, int64(header.StreamDirectoryRva)+int64(i)*int64(binary.Size(dir)), &dir); err != nil {
+			return nil, fmt.Errorf("failed to read stream directory: %w", err)
+		}
+		if dir.StreamType == minidumpModuleListStream {
+			list, found = dir.Location, true
+			break
+		}
+	}
+	if !found {
+		return nil, errors.New("minidump has no module list stream")
+	}
+
+	var count uint32
+	if err := readStructAt(r, int64(list.Rva), &count); err != nil {
+		return nil, fmt.Errorf("failed to read module list: %w", err)
+	}
+	if count > maxMinidumpModules {
+		return nil, fmt.Errorf("too many modules: %d", count)
+	}
+	rawModules := make([]minidumpModule, count)
+	if size := 4 + int64(binary.Size(rawModules)); size > int64(list.DataSize) {
+		return nil, fmt.Errorf("%d modules don't fit the %d bytes module list stream", count, list.DataSize)
+	}
+	if err := readStructAt(r, int64(list.Rva)+4, rawModules); err != nil {
+		return nil, fmt.Errorf("failed to read module list: %w", err)
+	}
+
+	modules := make([]ModuleVersion, 0, count)
+	for _, m := range rawModules {
+		name, err := readMinidumpString(r, m.ModuleNameRva)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read module name: %w", err)
+		}
+		module := ModuleVersion{
+			Name:          name,
+			BaseOfImage:   m.BaseOfImage,
+			SizeOfImage:   m.SizeOfImage,
+			TimeDateStamp: m.TimeDateStamp,
+		}
+		if m.VersionInfo.Signature == FixedFileInfoSignature {
+			module.FixedInfo = m.VersionInfo.toFixedFileInfo()
+		}
+		if m.CvRecord.DataSize != 0 && m.CvRecord.DataSize < maxMinidumpString {
+			record := make([]byte, m.CvRecord.DataSize)
+			if _, err := r.ReadAt(record, int64(m.CvRecord.Rva)); err == nil {
+				module.DebugInfo, _ = parseCodeView(record)
+			}
+		}
+		modules = append(modules, module)
+	}
+	return modules, nil
+}
+
+// readMinidumpString reads MINIDUMP_STRING: a byte length followed by UTF16.
+func readMinidumpString(r io.ReaderAt, rva uint32) (string, error) {
+	var length uint32
+	if err := readStructAt(r, int64(rva), &length); err != nil {
+		return "", err
+	}
+	if length > maxMinidumpString {
+		return "", fmt.Errorf("string is too long: %d", length)
+	}
+	u16 := make([]uint16, length/2)
+	if err := readStructAt(r, int64(rva)+4, u16); err != nil {
+		return "", err
+	}
+	return string(utf16.Decode(u16)), nil
+}
+
+// readStructAt decodes a little endian packed structure at the offset.
+func readStructAt(r io.ReaderAt, off int64, data interface{}) error {
+	return binary.Read(io.NewSectionReader(r, off, int64(binary.Size(data))), binary.LittleEndian, data)
+}
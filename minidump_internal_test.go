@@ -0,0 +1,152 @@
+package fileversion
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// testMinidump is a minidump with a single module list stream, the edit
+// functions break it.
+type testMinidump struct {
+	header    minidumpHeader
+	directory []minidumpDirectory
+	count     uint32
+	modules   []minidumpModule
+	name      string
+}
+
+func newTestMinidump() *testMinidump {
+	return &testMinidump{
+		header: minidumpHeader{Signature: minidumpSignature, NumberOfStreams: 2},
+		directory: []minidumpDirectory{
+			{StreamType: 7}, // SystemInfoStream
+			{StreamType: minidumpModuleListStream},
+		},
+		count: 1,
+		modules: []minidumpModule{{
+			BaseOfImage: 0x7ff800000000,
+			SizeOfImage: 0x1000,
+			VersionInfo: rawFixedFileInfo{
+				Signature:     FixedFileInfoSignature,
+				FileVersionMS: 10<<16 | 0,
+				FileVersionLS: 19041<<16 | 1,
+			},
+		}},
+		name: `C:\Windows\System32\kernel32.dll`,
+	}
+}
+
+// bytes lays the dump out: the header, the directory, the module list and
+// the name.
+func (d *testMinidump) bytes() []byte {
+	d.header.StreamDirectoryRva = uint32(binary.Size(d.header))
+	listRva := d.header.StreamDirectoryRva + uint32(binary.Size(d.directory))
+	listSize := uint32(4 + binary.Size(d.modules))
+	for i := range d.directory {
+		if d.directory[i].StreamType == minidumpModuleListStream && d.directory[i].Location == (minidumpLocation{}) {
+			d.directory[i].Location = minidumpLocation{DataSize: listSize, Rva: listRva}
+		}
+	}
+	nameRva := listRva + listSize
+	for i := range d.modules {
+		if d.modules[i].ModuleNameRva == 0 {
+			d.modules[i].ModuleNameRva = nameRva
+		}
+	}
+	name := utf16.Encode([]rune(d.name))
+	var buf bytes.Buffer
+	for _, data := range []interface{}{d.header, d.directory, d.count, d.modules, uint32(2 * len(name)), name} {
+		binary.Write(&buf, binary.LittleEndian, data) //nolint:errcheck
+	}
+	return buf.Bytes()
+}
+
+func TestMinidumpModules(t *testing.T) {
+	modules, err := minidumpModules(bytes.NewReader(newTestMinidump().bytes()))
+	if err != nil {
+		t.Fatalf("minidumpModules() error = %v", err)
+	}
+	if len(modules) != 1 {
+		t.Fatalf("got %d modules, want 1", len(modules))
+	}
+	m := modules[0]
+	if m.Name != `C:\Windows\System32\kernel32.dll` || m.BaseOfImage != 0x7ff800000000 || m.SizeOfImage != 0x1000 {
+		t.Errorf("module = %+v", m)
+	}
+	if want := (FileVersion{Major: 10, Build: 19041, Patch: 1}); m.FixedInfo.FileVersion != want {
+		t.Errorf("FileVersion = %v, want %v", m.FixedInfo.FileVersion, want)
+	}
+}
+
+func TestMinidumpModulesMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		dump func() []byte
+		err  string
+	}{
+		{"empty", func() []byte { return nil }, "failed to read header"},
+		{"truncated header", func() []byte { return newTestMinidump().bytes()[:10] }, "failed to read header"},
+		{"signature", func() []byte {
+			d := newTestMinidump()
+			d.header.Signature = 0x5a4d
+			return d.bytes()
+		}, "not a minidump"},
+		{"oversized directory", func() []byte {
+			d := newTestMinidump()
+			d.header.NumberOfStreams = 0xffffffff
+			return d.bytes()
+		}, "too many streams"},
+		{"truncated directory", func() []byte {
+			d := newTestMinidump()
+			d.directory = d.directory[:1]
+			data := d.bytes()
+			return data[:binary.Size(d.header)+binary.Size(d.directory)]
+		}, "failed to read stream directory"},
+		{"directory past the end", func() []byte {
+			data := newTestMinidump().bytes()
+			// StreamDirectoryRva.
+			binary.LittleEndian.PutUint32(data[12:], 1<<20)
+			return data
+		}, "failed to read stream directory"},
+		{"no module list", func() []byte {
+			d := newTestMinidump()
+			d.header.NumberOfStreams = 1
+			return d.bytes()
+		}, "no module list stream"},
+		{"too many modules", func() []byte {
+			d := newTestMinidump()
+			d.count = maxMinidumpModules + 1
+			return d.bytes()
+		}, "too many modules"},
+		{"modules past the stream", func() []byte {
+			d := newTestMinidump()
+			d.count = 2
+			return d.bytes()
+		}, "don't fit"},
+		{"truncated module list", func() []byte {
+			d := newTestMinidump()
+			d.directory[1].Location = minidumpLocation{DataSize: 1 << 20, Rva: uint32(binary.Size(d.header) + binary.Size(d.directory))}
+			d.count = 100
+			return d.bytes()
+		}, "failed to read module list"},
+		{"name past the end", func() []byte {
+			d := newTestMinidump()
+			d.modules[0].ModuleNameRva = 1 << 20
+			return d.bytes()
+		}, "failed to read module name"},
+		{"name too long", func() []byte {
+			d := newTestMinidump()
+			d.name = strings.Repeat("a", maxMinidumpString)
+			return d.bytes()
+		}, "string is too long"},
+	}
+	for _, tt := range tests {
+		_, err := minidumpModules(bytes.NewReader(tt.dump()))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: minidumpModules() error = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
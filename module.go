@@ -0,0 +1,75 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Self creates an Info for the executable of the current process, so programs
+// can report their own stamped version.
+func Self(opts ...Option) (Info, error) {
+	return Module(0, opts...)
+}
+
+// Module creates an Info for a module (DLL or the executable) loaded into the
+// current process, e.g. a plugin. Zero handle means the executable.
+func Module(handle windows.Handle, opts ...Option) (Info, error) {
+	path, err := moduleFileName(handle)
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to get module file name: %w", err)
+	}
+	return New(path, opts...)
+}
+
+// ModulePaths returns the paths of all the modules loaded into the current
+// process, the executable goes first.
+func ModulePaths() ([]string, error) {
+	process := windows.CurrentProcess()
+	modules := make([]windows.Handle, 256)
+	for {
+		var needed uint32
+		size := uint32(len(modules)) * uint32(sizeofHandle)
+		if err := windows.EnumProcessModules(process, &modules[0], size, &needed); err != nil {
+			return nil, fmt.Errorf("failed to enumerate process modules: %w", err)
+		}
+		if needed <= size {
+			modules = modules[:needed/uint32(sizeofHandle)]
+			break
+		}
+		modules = make([]windows.Handle, needed/uint32(sizeofHandle))
+	}
+	paths := make([]string, 0, len(modules))
+	for _, m := range modules {
+		path, err := moduleFileName(m)
+		if err != nil {
+			// The module could be unloaded after the enumeration.
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// sizeofHandle is the size of HMODULE.
+const sizeofHandle = unsafe.Sizeof(windows.Handle(0))
+
+// moduleFileName is GetModuleFileName growing the buffer for long paths.
+func moduleFileName(handle windows.Handle) (string, error) {
+	buf := make([]uint16, windows.MAX_PATH)
+	for {
+		n, err := windows.GetModuleFileName(handle, &buf[0], uint32(len(buf)))
+		if err != nil {
+			return "", err
+		}
+		// The path is truncated if it fills the whole buffer.
+		if n < uint32(len(buf)) {
+			return windows.UTF16ToString(buf[:n]), nil
+		}
+		buf = make([]uint16, 2*len(buf))
+	}
+}
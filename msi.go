@@ -0,0 +1,206 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// MsiOwner is the Windows Installer product owning a file.
+type MsiOwner struct {
+	ProductCode   string
+	ComponentCode string
+	// UpgradeCode is empty if the product doesn't declare one.
+	UpgradeCode    string
+	ProductName    string
+	ProductVersion string
+}
+
+//nolint:gochecknoglobals
+var (
+	// The Windows Installer API is not wrapped by x/sys/windows.
+	msi                     = windows.NewLazySystemDLL("msi.dll")
+	msiEnumComponentsProc   = msi.NewProc("MsiEnumComponentsW")
+	msiEnumClientsProc      = msi.NewProc("MsiEnumClientsW")
+	msiGetComponentPathProc = msi.NewProc("MsiGetComponentPathW")
+	msiGetProductInfoProc   = msi.NewProc("MsiGetProductInfoW")
+)
+
+// Windows Installer constants. Source:
+// https://docs.microsoft.com/en-us/windows/win32/api/msi/nf-msi-msigetcomponentpathw
+const (
+	guidStringLength     = 38
+	installStateLocal    = 3
+	installStateSource   = 4
+	installStateMoreData = -3
+
+	upgradeCodesRegistryPath = `SOFTWARE\Microsoft\Windows\CurrentVersion\Installer\UpgradeCodes`
+)
+
+// MsiComponentOwner returns the installed Windows Installer product whose
+// component keypath is the file at path. The lookup enumerates all the
+// components registered on the system, so it takes a while on machines with
+// many products; cache the result for batch audits.
+func MsiComponentOwner(path string) (MsiOwner, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return MsiOwner{}, fmt.Errorf("failed to get absolute path of %q: %w", path, err)
+	}
+	component := make([]uint16, guidStringLength+1)
+	product := make([]uint16, guidStringLength+1)
+	for i := uint32(0); ; i++ {
+		ret, _, _ := msiEnumComponentsProc.Call(uintptr(i), uintptr(unsafe.Pointer(&component[0])))
+		if windows.Errno(ret) == windows.ERROR_NO_MORE_ITEMS {
+			return MsiOwner{}, errors.New("file is not owned by an installed Windows Installer product")
+		}
+		if ret != 0 {
+			return MsiOwner{}, fmt.Errorf("failed to enumerate components: %w", windows.Errno(ret))
+		}
+		for j := uint32(0); ; j++ {
+			ret, _, _ := msiEnumClientsProc.Call(
+				uintptr(unsafe.Pointer(&component[0])), uintptr(j), uintptr(unsafe.Pointer(&product[0])))
+			if ret != 0 {
+				break
+			}
+			keypath, ok := msiComponentPath(&product[0], &component[0])
+			if ok && strings.EqualFold(filepath.Clean(keypath), abs) {
+				return newMsiOwner(windows.UTF16ToString(product), windows.UTF16ToString(component)), nil
+			}
+		}
+	}
+}
+
+// msiComponentPath returns the path of the component of the product if it's
+// installed.
+func msiComponentPath(product, component *uint16) (string, bool) {
+	buf := make([]uint16, windows.MAX_PATH)
+	for {
+		n := uint32(len(buf))
+		state, _, _ := msiGetComponentPathProc.Call(
+			uintptr(unsafe.Pointer(product)),
+			uintptr(unsafe.Pointer(component)),
+			uintptr(unsafe.Pointer(&buf[0])),
+			uintptr(unsafe.Pointer(&n)),
+		)
+		switch int32(state) {
+		case installStateLocal, installStateSource:
+			return windows.UTF16ToString(buf), true
+		case installStateMoreData:
+			buf = make([]uint16, n+1)
+		default:
+			return "", false
+		}
+	}
+}
+
+func newMsiOwner(product, component string) MsiOwner {
+	return MsiOwner{
+		ProductCode:    product,
+		ComponentCode:  component,
+		UpgradeCode:    msiUpgradeCode(product),
+		ProductName:    msiProductInfo(product, "InstalledProductName"),
+		ProductVersion: msiProductInfo(product, "VersionString"),
+	}
+}
+
+// msiProductInfo returns a product property or an empty string.
+func msiProductInfo(product, property string) string {
+	productPtr, err := windows.UTF16PtrFromString(product)
+	if err != nil {
+		return ""
+	}
+	propertyPtr, err := windows.UTF16PtrFromString(property)
+	if err != nil {
+		return ""
+	}
+	buf := make([]uint16, 256)
+	for {
+		n := uint32(len(buf))
+		ret, _, _ := msiGetProductInfoProc.Call(
+			uintptr(unsafe.Pointer(productPtr)),
+			uintptr(unsafe.Pointer(propertyPtr)),
+			uintptr(unsafe.Pointer(&buf[0])),
+			uintptr(unsafe.Pointer(&n)),
+		)
+		switch windows.Errno(ret) {
+		case 0:
+			return windows.UTF16ToString(buf[:n])
+		case windows.ERROR_MORE_DATA:
+			buf = make([]uint16, n+1)
+		default:
+			return ""
+		}
+	}
+}
+
+// msiUpgradeCode finds the upgrade code of the product: Windows Installer
+// only keeps the reverse mapping, so all the upgrade codes are scanned.
+func msiUpgradeCode(product string) string {
+	packedProduct, ok := packMsiGUID(product)
+	if !ok {
+		return ""
+	}
+	root, err := openRegistryKey(registry.LOCAL_MACHINE, upgradeCodesRegistryPath)
+	if err != nil {
+		return ""
+	}
+	defer root.Close()
+	codes, err := root.ReadSubKeyNames(0)
+	if err != nil {
+		return ""
+	}
+	for _, code := range codes {
+		key, err := openRegistryKey(root, code)
+		if err != nil {
+			continue
+		}
+		names, _ := key.ReadValueNames(0)
+		key.Close()
+		for _, name := range names {
+			if strings.EqualFold(name, packedProduct) {
+				upgradeCode, _ := unpackMsiGUID(code)
+				return upgradeCode
+			}
+		}
+	}
+	return ""
+}
+
+// msiGUIDPacking lists the lengths of the GUID hex runs which are reversed in
+// the packed form used by the Installer registry keys.
+//
+//nolint:gochecknoglobals
+var msiGUIDPacking = []int{8, 4, 4, 2, 2, 2, 2, 2, 2, 2, 2}
+
+// packMsiGUID converts `{12345678-ABCD-...}` to the packed registry form.
+func packMsiGUID(guid string) (string, bool) {
+	hex := strings.NewReplacer("{", "", "}", "", "-", "").Replace(guid)
+	if len(hex) != 32 {
+		return "", false
+	}
+	var packed strings.Builder
+	for _, n := range msiGUIDPacking {
+		for i := n - 1; i >= 0; i-- {
+			packed.WriteByte(hex[i])
+		}
+		hex = hex[n:]
+	}
+	return packed.String(), true
+}
+
+// unpackMsiGUID is the inverse of packMsiGUID.
+func unpackMsiGUID(packed string) (string, bool) {
+	hex, ok := packMsiGUID("{" + packed + "}")
+	if !ok {
+		return "", false
+	}
+	return "{" + hex[:8] + "-" + hex[8:12] + "-" + hex[12:16] + "-" + hex[16:20] + "-" + hex[20:] + "}", true
+}
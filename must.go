@@ -0,0 +1,36 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+// Must is a helper that wraps a call to a function returning (Info, error)
+// and panics if the error is non-nil. It's intended for scripts and test
+// helpers:
+//
+//	info := fileversion.Must(fileversion.New(path))
+func Must(info Info, err error) Info {
+	if err != nil {
+		panic(err)
+	}
+	return info
+}
+
+// MustGetProperty is like GetProperty but panics if the property can't be
+// queried.
+func (f Info) MustGetProperty(propertyName string) string {
+	p, err := f.GetProperty(propertyName)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// MustGetPropertyWithLocale is like GetPropertyWithLocale but panics if the
+// property can't be queried.
+func (f Info) MustGetPropertyWithLocale(propertyName string, locale Locale) string {
+	p, err := f.GetPropertyWithLocale(propertyName, locale)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
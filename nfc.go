@@ -0,0 +1,55 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"errors"
+	"unicode/utf16"
+	"unicode/utf8"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+//nolint:gochecknoglobals
+var normalizeStringProc = kernel32.NewProc("NormalizeString")
+
+// normalizationC is NORM_FORM NormalizationC. Source:
+// https://docs.microsoft.com/en-us/windows/win32/api/winnls/ne-winnls-norm_form
+const normalizationC = 1
+
+// normalizeNFC converts the string to the Unicode normalization form C with
+// NormalizeString. The string is returned as is if it can't be normalized.
+func normalizeNFC(s string) string {
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii || normalizeStringProc.Find() != nil {
+		return s
+	}
+	src := utf16.Encode([]rune(s))
+	// NFC rarely grows a string, the estimation is only a hint.
+	n, _, _ := normalizeStringProc.Call(normalizationC, uintptr(unsafe.Pointer(&src[0])), uintptr(len(src)), 0, 0)
+	size := int(int32(n))
+	if size <= 0 {
+		return s
+	}
+	for attempt := 0; attempt < 3; attempt++ {
+		dst := make([]uint16, size)
+		n, _, err := normalizeStringProc.Call(normalizationC, uintptr(unsafe.Pointer(&src[0])), uintptr(len(src)),
+			uintptr(unsafe.Pointer(&dst[0])), uintptr(len(dst)))
+		if length := int(int32(n)); length > 0 {
+			return string(utf16.Decode(dst[:length]))
+		}
+		if !errors.Is(err, windows.ERROR_INSUFFICIENT_BUFFER) {
+			return s
+		}
+		size *= 2
+	}
+	return s
+}
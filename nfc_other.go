@@ -0,0 +1,10 @@
+//go:build !windows
+// +build !windows
+
+package fileversion
+
+// normalizeNFC returns the string as is: NormalizeString is a Windows API,
+// so WithNFCNormalization has no effect on other platforms.
+func normalizeNFC(s string) string {
+	return s
+}
@@ -0,0 +1,38 @@
+package fileversion
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalizeString trims the value, replaces control characters (NUL padding,
+// \r\n) with spaces, drops format characters (zero width spaces, BOMs) like
+// ConfusableSkeleton does and collapses the whitespace runs into single
+// spaces, so "Micro\u200bsoft" is "Microsoft".
+func normalizeString(s string) string {
+	clean := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.Is(unicode.Cf, r):
+			return -1
+		case unicode.IsControl(r):
+			return ' '
+		}
+		return r
+	}, s)
+	return collapseSpaces(clean)
+}
+
+// normalize applies the string normalizations enabled by the options.
+func (f Info) normalize(s string) string {
+	if f.opts.nfc {
+		s = normalizeNFC(s)
+	}
+	if f.opts.normalizeStrings {
+		s = normalizeString(s)
+	}
+	return s
+}
+
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,132 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// New creates an Info instance.
+//
+// It queries a list of translations from the version-information resource and
+// uses them as preferred translations for string properties.
+//
+// Alternate data streams are read with `file.exe:stream` paths, see
+// AlternateStreams.
+func New(path string, opts ...Option) (Info, error) {
+	o := newOptions(opts)
+	info, err := openVersionInfo(path, o)
+	if err != nil {
+		return Info{}, err
+	}
+	info.initLocales(o)
+	return info, nil
+}
+
+// openVersionInfo reads the raw resource for New and NewWithLocale.
+func openVersionInfo(path string, o options) (Info, error) {
+	if o.normalizePath {
+		normalized, err := normalizePath(path, o.baseDir)
+		if err != nil {
+			return Info{}, fmt.Errorf("failed to normalize path: %w", err)
+		}
+		path = normalized
+	}
+	var info Info
+	var err error
+	if o.shareDelete || isStreamPath(path) {
+		info, err = readSharedVersionInfo(path, o)
+	} else {
+		info, err = readVersionInfo(path, o)
+	}
+	if err != nil {
+		markUnsupportedFormat(path, err)
+		o.debug("failed to get version info", "path", path, "error", err)
+		return Info{}, fmt.Errorf("failed to get VersionInfo: %w", err)
+	}
+	if id, err := fileIdentity(path); err == nil {
+		info.identity = &id
+	} else {
+		o.debug("failed to get file identity", "path", path, "error", err)
+	}
+	return info, nil
+}
+
+// markUnsupportedFormat reclassifies a failure to read a DOS or an LE/LX
+// executable: windows reports them as images without resources, which is
+// misleading for legacy software audits.
+func markUnsupportedFormat(path string, err error) {
+	var e *Error
+	if !errors.As(err, &e) || (e.Kind != ErrNoVersionInfo && e.Kind != ErrNotPE) {
+		return
+	}
+	switch detectFileFormat(path) {
+	case FormatMZ, FormatLE, FormatLX:
+		e.Kind = ErrUnsupportedFormat
+	}
+}
+
+// NewWithLocale creates an Info instance with a given locale. All the string
+// properties translations will be firstly queried with the given locale.
+//
+// See GetPropertyWithLocale for exact properties querying.
+func NewWithLocale(path string, locale Locale, opts ...Option) (Info, error) {
+	o := newOptions(opts)
+	info, err := openVersionInfo(path, o)
+	if err != nil {
+		return Info{}, err
+	}
+	info.opts = o
+	return info.WithLocale(locale), nil
+}
+
+// x/sys/windows wraps neither the Ex variants taking flags nor VerQueryValueW
+// without converting the sub-block from a Go string on every call, so the
+// version.dll functions are called directly.
+//
+//nolint:gochecknoglobals
+var (
+	version                    = windows.NewLazySystemDLL("version.dll")
+	getFileVersionInfoSizeProc = version.NewProc("GetFileVersionInfoSizeExW")
+	getFileVersionInfoProc     = version.NewProc("GetFileVersionInfoExW")
+	verQueryValueProc          = version.NewProc("VerQueryValueW")
+)
+
+func newWithoutLocale(path string, o options) (Info, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to convert image path to utf16: %w", err)
+	}
+	var handle uint32
+	size, _, err := getFileVersionInfoSizeProc.Call(
+		uintptr(o.flags),
+		uintptr(unsafe.Pointer(pathPtr)),
+		uintptr(unsafe.Pointer(&handle)),
+	)
+	if size == 0 {
+		return Info{}, fmt.Errorf("failed to get memory size for VersionInfo slice: %w",
+			newWindowsError("GetFileVersionInfoSizeEx", path, err))
+	}
+	if err := o.resourceTooLarge("GetFileVersionInfoSizeEx", path, uint64(size)); err != nil {
+		return Info{}, fmt.Errorf("failed to get memory size for VersionInfo slice: %w", err)
+	}
+	info := make([]byte, size)
+	ret, _, err := getFileVersionInfoProc.Call(
+		uintptr(o.flags),
+		uintptr(unsafe.Pointer(pathPtr)),
+		0,
+		uintptr(len(info)),
+		uintptr(unsafe.Pointer(&info[0])),
+	)
+	if ret == 0 {
+		return Info{}, fmt.Errorf("failed to get VersionInfo from windows: %w",
+			newWindowsError("GetFileVersionInfoEx", path, err))
+	}
+
+	return newInfo(path, info), nil
+}
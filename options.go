@@ -0,0 +1,186 @@
+package fileversion
+
+import "fmt"
+
+// Option configures Info creation in New and NewWithLocale.
+type Option func(*options)
+
+type options struct {
+	systemLocalePreference bool
+	resolver               LocaleResolver
+	noFallback             bool
+	eagerLocales           bool
+	looseKeys              bool
+	normalizeStrings       bool
+	nfc                    bool
+	flags                  VersionInfoFlags
+	maxResourceSize        int
+	shareDelete            bool
+	logger                 logger
+	normalizePath          bool
+	baseDir                string
+}
+
+// logger is implemented by *slog.Logger, see WithLogger.
+type logger interface {
+	Debug(msg string, args ...interface{})
+}
+
+func (o options) debug(msg string, args ...interface{}) {
+	if o.logger != nil {
+		o.logger.Debug(msg, args...)
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{flags: FileVerGetLocalised, maxResourceSize: DefaultMaxResourceSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithSystemLocalePreference makes string properties to be firstly queried
+// using the current user UI language and then using the system UI language,
+// like Explorer does. Both are tried with Unicode and ASCII charsets.
+//
+// For New the languages are placed before the translations declared in the
+// resource, for NewWithLocale - right after the given locale.
+func WithSystemLocalePreference() Option {
+	return func(o *options) {
+		o.systemLocalePreference = true
+	}
+}
+
+// WithLocaleResolver replaces the heuristic choosing translations tried by
+// GetProperty and all the property getters.
+func WithLocaleResolver(r LocaleResolver) Option {
+	return func(o *options) {
+		o.resolver = r
+	}
+}
+
+// WithExhaustiveFallback makes GetProperty try every combination of the
+// declared languages, English (US) and the neutral language with Unicode,
+// ASCII and unknown charsets, like shell32 does. It's a shortcut for
+// WithLocaleResolver(ExhaustiveLocaleResolver).
+func WithExhaustiveFallback() Option {
+	return WithLocaleResolver(ExhaustiveLocaleResolver)
+}
+
+// WithNoFallback disables guessing translations: GetProperty and all the
+// property getters only query Info.Locales and fail otherwise. New doesn't
+// substitute DefaultLocales when the resource declares no translations, so
+// the Info has no locales at all in this case. It replaces any resolver given
+// in WithLocaleResolver with StrictLocaleResolver.
+func WithNoFallback() Option {
+	return func(o *options) {
+		o.resolver = StrictLocaleResolver
+		o.noFallback = true
+	}
+}
+
+// WithEagerLocales makes New and NewWithLocale probe once which of the
+// translations the locale resolver would try have string tables, so property
+// lookups skip the missing ones instead of retrying them for every property.
+// It pays off for files with broken translation tables when many properties
+// are read. The resolver is called with an empty property name, so
+// property-specific resolvers lose their effect.
+func WithEagerLocales() Option {
+	return func(o *options) {
+		o.eagerLocales = true
+	}
+}
+
+// WithLooseKeys makes GetProperty, GetPropertyWithLocale and all the property
+// getters fall back to enumerating the string table when the exact key is
+// missing, matching the keys case-insensitively and by the aliases of
+// CanonicalPropertyName. VerQueryValue keys are case-sensitive, but vendors
+// write "Fileversion", "LegalCopyRight" or "Copyright".
+func WithLooseKeys() Option {
+	return func(o *options) {
+		o.looseKeys = true
+	}
+}
+
+// WithNormalizedStrings makes GetProperty, GetPropertyWithLocale, Properties
+// and all the property getters trim the values, replace control characters
+// (NUL padding, \r\n and so on) with spaces, drop format characters (zero
+// width spaces, BOMs) and collapse whitespace runs, so the values can be
+// compared for equality. The raw values are returned by default.
+func WithNormalizedStrings() Option {
+	return func(o *options) {
+		o.normalizeStrings = true
+	}
+}
+
+// WithNFCNormalization makes GetProperty, GetPropertyWithLocale, Properties
+// and all the property getters convert the values to the Unicode
+// normalization form C, so that precomposed and decomposed spellings ("é"
+// and "e\u0301") compare equal. It's applied before WithNormalizedStrings.
+// The conversion is done by the Windows NormalizeString API, on other
+// platforms the option has no effect.
+func WithNFCNormalization() Option {
+	return func(o *options) {
+		o.nfc = true
+	}
+}
+
+// DefaultMaxResourceSize is the default limit of WithMaxResourceSize. A
+// VS_VERSIONINFO block is at most 64 KiB long, GetFileVersionInfoSize
+// reports about twice of the block size.
+const DefaultMaxResourceSize = 1 << 20
+
+// WithMaxResourceSize limits the size of the version-information resource
+// New, NewWithLocale and the readers allocate. GetFileVersionInfoSize and the
+// resource directories of corrupt files can report absurd sizes, the limit
+// protects memory-constrained agents scanning untrusted files. Bigger
+// resources fail with ErrResourceTooLarge. A zero or negative size disables
+// the limit.
+func WithMaxResourceSize(size int) Option {
+	return func(o *options) {
+		o.maxResourceSize = size
+	}
+}
+
+// resourceTooLarge returns ErrResourceTooLarge if the size exceeds the limit.
+func (o options) resourceTooLarge(op, path string, size uint64) error {
+	if o.maxResourceSize <= 0 || size <= uint64(o.maxResourceSize) {
+		return nil
+	}
+	return &Error{
+		Op:   op,
+		Path: path,
+		Kind: ErrResourceTooLarge,
+		Err:  fmt.Errorf("resource of %d bytes exceeds the limit of %d bytes", size, o.maxResourceSize),
+	}
+}
+
+// VersionInfoFlags are GetFileVersionInfoEx dwFlags controlling which
+// resource (the file itself or its MUI satellite) the data is loaded from.
+//
+// Ref: https://docs.microsoft.com/en-us/windows/win32/api/winver/nf-winver-getfileversioninfoexw
+type VersionInfoFlags uint32
+
+// GetFileVersionInfoEx flags.
+const (
+	// FileVerGetLocalised loads the entire resource (strings and the fixed
+	// info) from the corresponding MUI file if available. It's what
+	// GetFileVersionInfo passes and the default one.
+	FileVerGetLocalised VersionInfoFlags = 0x01
+	// FileVerGetNeutral loads strings from the MUI file if available and the
+	// fixed info from the language-neutral file.
+	FileVerGetNeutral VersionInfoFlags = 0x02
+	// FileVerGetPrefetched is an optimization hint for files which were
+	// recently read and are likely in the cache.
+	FileVerGetPrefetched VersionInfoFlags = 0x04
+)
+
+// WithVersionInfoFlags sets GetFileVersionInfoEx flags used for reading the
+// resource instead of the default FileVerGetLocalised, e.g. FileVerGetNeutral.
+// It's how Explorer gets MUI-localized descriptions.
+func WithVersionInfoFlags(flags VersionInfoFlags) Option {
+	return func(o *options) {
+		o.flags = flags
+	}
+}
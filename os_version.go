@@ -0,0 +1,84 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// OSVersionInfo is the version of the running Windows.
+type OSVersionInfo struct {
+	// Version is the RtlGetVersion version with the UBR from the registry as
+	// the fourth part, e.g. 10.0.26100.3775. Unlike GetVersionEx, it's not
+	// affected by the application manifest compatibility shims.
+	Version FileVersion
+	// KernelVersion is the binary version of ntoskrnl.exe or, for 32-bit
+	// processes on 64-bit Windows which don't see it, of kernel32.dll.
+	KernelVersion FileVersion
+	// ProductName and DisplayVersion are read from the registry, e.g.
+	// "Windows 10 Pro" and "24H2". Windows 11 still reports "Windows 10" in
+	// ProductName, use Release for the product name.
+	ProductName    string
+	DisplayVersion string
+	// Release is the Windows release and the update of the Version, false
+	// in HasRelease if it's unknown, see WindowsPatchLevel.
+	Release    PatchLevel
+	HasRelease bool
+}
+
+const currentVersionRegistryPath = `SOFTWARE\Microsoft\Windows NT\CurrentVersion`
+
+// OSVersion returns the accurate version of the running Windows: RtlGetVersion
+// build combined with the update build revision (UBR), the kernel version and
+// the release names.
+func OSVersion() (OSVersionInfo, error) {
+	rtl := windows.RtlGetVersion()
+	info := OSVersionInfo{
+		Version: FileVersion{
+			Major: uint16(rtl.MajorVersion),
+			Minor: uint16(rtl.MinorVersion),
+			Build: uint16(rtl.BuildNumber),
+		},
+	}
+	if key, err := openRegistryKey(registry.LOCAL_MACHINE, currentVersionRegistryPath); err == nil {
+		if ubr, _, err := key.GetIntegerValue("UBR"); err == nil {
+			info.Version.Patch = uint16(ubr)
+		}
+		info.ProductName, _, _ = key.GetStringValue("ProductName")
+		info.DisplayVersion, _, _ = key.GetStringValue("DisplayVersion")
+		key.Close()
+	}
+
+	system, err := windows.GetSystemDirectory()
+	if err != nil {
+		return OSVersionInfo{}, fmt.Errorf("failed to get system directory: %w", err)
+	}
+	if info.KernelVersion, err = kernelVersion(system); err != nil {
+		return OSVersionInfo{}, fmt.Errorf("failed to get kernel version: %w", err)
+	}
+	info.Release, info.HasRelease = WindowsPatchLevel(info.Version)
+	return info, nil
+}
+
+// kernelVersion returns the version of ntoskrnl.exe falling back to
+// kernel32.dll, since WOW64 redirects the system directory to SysWOW64 which
+// has no kernel image.
+func kernelVersion(system string) (FileVersion, error) {
+	var err error
+	for _, name := range []string{"ntoskrnl.exe", "kernel32.dll"} {
+		var kernel Info
+		if kernel, err = New(filepath.Join(system, name)); err != nil {
+			continue
+		}
+		var fixed FixedFileInfo
+		if fixed, err = kernel.FixedInfoE(); err == nil {
+			return fixed.FileVersion, nil
+		}
+	}
+	return FileVersion{}, err
+}
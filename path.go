@@ -0,0 +1,61 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"golang.org/x/sys/windows"
+)
+
+// WithPathNormalization makes New and NewWithLocale expand `%SystemRoot%`-style
+// environment variables in the path and resolve relative paths against
+// baseDir (or the working directory if baseDir is empty). Paths taken from the
+// registry (App Paths, services ImagePath) are often stored this way.
+func WithPathNormalization(baseDir string) Option {
+	return func(o *options) {
+		o.normalizePath = true
+		o.baseDir = baseDir
+	}
+}
+
+// normalizePath expands environment variables in path and makes it absolute.
+func normalizePath(path, baseDir string) (string, error) {
+	expanded, err := expandEnvironmentStrings(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to expand %q: %w", path, err)
+	}
+	if filepath.IsAbs(expanded) {
+		return filepath.Clean(expanded), nil
+	}
+	if baseDir != "" {
+		// A rooted path like `\Windows` still needs a volume from baseDir.
+		if filepath.VolumeName(expanded) == "" && len(expanded) > 0 && (expanded[0] == '\\' || expanded[0] == '/') {
+			return filepath.Join(filepath.VolumeName(baseDir), expanded), nil
+		}
+		return filepath.Join(baseDir, expanded), nil
+	}
+	return filepath.Abs(expanded)
+}
+
+// expandEnvironmentStrings is ExpandEnvironmentStringsW. Unlike os.ExpandEnv
+// it handles the `%NAME%` syntax and leaves unknown variables as is.
+func expandEnvironmentStrings(s string) (string, error) {
+	src, err := windows.UTF16PtrFromString(s)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]uint16, len(s)+1)
+	for {
+		n, err := windows.ExpandEnvironmentStrings(src, &buf[0], uint32(len(buf)))
+		if err != nil {
+			return "", err
+		}
+		if int(n) <= len(buf) {
+			return windows.UTF16ToString(buf[:n]), nil
+		}
+		buf = make([]uint16, n)
+	}
+}
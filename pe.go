@@ -0,0 +1,92 @@
+package fileversion
+
+import (
+	"debug/pe"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PEInfo contains basic information from PE headers of the file.
+//
+// Ref: https://docs.microsoft.com/en-us/windows/win32/debug/pe-format
+type PEInfo struct {
+	// Machine is a target architecture, one of debug/pe IMAGE_FILE_MACHINE_*
+	// constants.
+	Machine uint16
+	// Is64Bit reports whether the image has PE32+ optional header.
+	Is64Bit bool
+	// Subsystem is one of debug/pe IMAGE_SUBSYSTEM_* constants.
+	Subsystem uint16
+	// TimeDateStamp is a linker timestamp. Reproducible builds put a hash here
+	// instead of a real time.
+	TimeDateStamp time.Time
+	IsDLL         bool
+	// IsDriver reports whether the image is a kernel-mode driver: a native
+	// image marked as a system file or importing ntoskrnl.exe or hal.dll.
+	// Native user-mode executables like smss.exe or autochk.exe are EXEs.
+	IsDriver bool
+	IsEXE    bool
+	// IsCLR reports whether the image contains a .NET (CLR) header.
+	IsCLR bool
+}
+
+// imageDirectoryEntryCOMDescriptor is an index of CLR runtime header in the
+// optional header data directories.
+const imageDirectoryEntryCOMDescriptor = 14
+
+// PEInfo parses PE headers of the file Info was created from.
+func (f Info) PEInfo() (PEInfo, error) {
+	file, err := pe.Open(f.path)
+	if err != nil {
+		return PEInfo{}, fmt.Errorf("failed to parse PE headers of %q: %w", f.path, err)
+	}
+	defer file.Close()
+	return newPEInfo(file), nil
+}
+
+func newPEInfo(file *pe.File) PEInfo {
+	info := PEInfo{
+		Machine:       file.Machine,
+		TimeDateStamp: time.Unix(int64(file.TimeDateStamp), 0).UTC(),
+		IsDLL:         file.Characteristics&pe.IMAGE_FILE_DLL != 0,
+	}
+	switch h := file.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		info.Subsystem = h.Subsystem
+	case *pe.OptionalHeader64:
+		info.Is64Bit = true
+		info.Subsystem = h.Subsystem
+	}
+	if dir, ok := dataDirectory(file, imageDirectoryEntryCOMDescriptor); ok {
+		info.IsCLR = dir.VirtualAddress != 0
+	}
+	info.IsDriver = info.Subsystem == pe.IMAGE_SUBSYSTEM_NATIVE && !info.IsDLL &&
+		(file.Characteristics&pe.IMAGE_FILE_SYSTEM != 0 || importsKernel(file))
+	info.IsEXE = !info.IsDLL && !info.IsDriver
+	return info
+}
+
+// importsKernel reports whether the image imports the kernel or the HAL.
+func importsKernel(file *pe.File) bool {
+	libraries, err := file.ImportedLibraries()
+	if err != nil {
+		return false
+	}
+	for _, library := range libraries {
+		switch strings.ToLower(library) {
+		case "ntoskrnl.exe", "hal.dll":
+			return true
+		}
+	}
+	return false
+}
+
+// dataDirectories returns the meaningful part of the data directories array.
+// NumberOfRvaAndSizes comes from the file, so it's not trusted.
+func dataDirectories(dirs []pe.DataDirectory, n uint32) []pe.DataDirectory {
+	if int(n) < len(dirs) {
+		return dirs[:n]
+	}
+	return dirs
+}
@@ -0,0 +1,99 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"debug/pe"
+	"fmt"
+	"io"
+
+	"golang.org/x/sys/windows"
+)
+
+// OpenProcess access rights. Source:
+// https://docs.microsoft.com/en-us/windows/win32/procthread/process-security-and-access-rights
+const (
+	processVMRead                  = 0x0010
+	processQueryLimitedInformation = 0x1000
+)
+
+// NewFromProcessMemory creates an Info from a module mapped into the address
+// space of the process pid at baseAddress. The image is read with
+// ReadProcessMemory and parsed in its in-memory (section aligned) layout, so
+// it works for modules which don't exist on disk like packed or injected ones.
+//
+// The caller needs PROCESS_VM_READ access to the process.
+func NewFromProcessMemory(pid uint32, baseAddress uintptr, opts ...Option) (Info, error) {
+	process, err := windows.OpenProcess(processVMRead|processQueryLimitedInformation, false, pid)
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to open process %d: %w", pid, err)
+	}
+	defer windows.CloseHandle(process) //nolint:errcheck
+
+	mem := processMemory{process: process, base: baseAddress}
+	file, err := pe.NewFile(mem)
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to parse PE headers at %#x: %w: %v", baseAddress, ErrNotPE, err)
+	}
+	defer file.Close()
+	switch h := file.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		mem.size = h.SizeOfImage
+	case *pe.OptionalHeader64:
+		mem.size = h.SizeOfImage
+	}
+
+	info, err := newFromImage(file, mem.readRVA, newOptions(opts))
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to read version info from process %d memory: %w", pid, err)
+	}
+	return info, nil
+}
+
+// processMemory provides access to an image mapped into other process. Offsets
+// are relative to the image base, so they are RVAs. Size is SizeOfImage, it's
+// zero until the headers are parsed.
+type processMemory struct {
+	process windows.Handle
+	base    uintptr
+	size    uint32
+}
+
+// ReadAt implements io.ReaderAt.
+func (m processMemory) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	var n uintptr
+	err := windows.ReadProcessMemory(m.process, m.base+uintptr(off), &p[0], uintptr(len(p)), &n)
+	if err != nil {
+		return int(n), fmt.Errorf("failed to read process memory at %#x: %w", m.base+uintptr(off), err)
+	}
+	return int(n), nil
+}
+
+// readRVA reads the range of the image like the file-backed readRVA does:
+// the range must be within SizeOfImage and its last byte must be readable
+// before the buffer is allocated.
+func (m processMemory) readRVA(rva, size uint32) ([]byte, error) {
+	if uint64(rva)+uint64(size) > uint64(m.size) {
+		return nil, fmt.Errorf("rva range %#x+%#x is out of the image of %#x bytes", rva, size, m.size)
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	var last [1]byte
+	if _, err := m.ReadAt(last[:], int64(rva)+int64(size)-1); err != nil {
+		return nil, err
+	}
+	data := make([]byte, size)
+	n, err := m.ReadAt(data, int64(rva))
+	if err != nil {
+		return nil, err
+	}
+	if n < len(data) {
+		return nil, fmt.Errorf("failed to read rva range %#x+%#x: %w", rva, size, io.ErrUnexpectedEOF)
+	}
+	return data, nil
+}
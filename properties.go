@@ -0,0 +1,85 @@
+package fileversion
+
+import "strings"
+
+// PropertyName is a name of a string property of the version-information
+// resource.
+type PropertyName string
+
+// Standard string properties. Ref `String Name` section of
+// https://docs.microsoft.com/en-us/windows/win32/menurc/versioninfo-resource
+//
+// Use them with any of the property querying methods as
+// f.GetProperty(string(fileversion.PropCompanyName)).
+const (
+	PropComments         PropertyName = "Comments"
+	PropCompanyName      PropertyName = "CompanyName"
+	PropFileDescription  PropertyName = "FileDescription"
+	PropFileVersion      PropertyName = "FileVersion"
+	PropInternalName     PropertyName = "InternalName"
+	PropLegalCopyright   PropertyName = "LegalCopyright"
+	PropLegalTrademarks  PropertyName = "LegalTrademarks"
+	PropOriginalFilename PropertyName = "OriginalFilename"
+	PropPrivateBuild     PropertyName = "PrivateBuild"
+	PropProductName      PropertyName = "ProductName"
+	PropProductVersion   PropertyName = "ProductVersion"
+	PropSpecialBuild     PropertyName = "SpecialBuild"
+)
+
+// KnownProperties returns all the standard property names in the order they
+// are listed in the windows docs.
+func KnownProperties() []PropertyName {
+	return []PropertyName{
+		PropComments,
+		PropCompanyName,
+		PropFileDescription,
+		PropFileVersion,
+		PropInternalName,
+		PropLegalCopyright,
+		PropLegalTrademarks,
+		PropOriginalFilename,
+		PropPrivateBuild,
+		PropProductName,
+		PropProductVersion,
+		PropSpecialBuild,
+	}
+}
+
+// propertyAliases maps lowercase non-standard property names vendors use to
+// the canonical ones. Names differing from a standard one only in case are
+// matched without the table.
+//
+//nolint:gochecknoglobals
+var propertyAliases = map[string]PropertyName{
+	"comment":              PropComments,
+	"company":              PropCompanyName,
+	"description":          PropFileDescription,
+	"filedesc":             PropFileDescription,
+	"fileversionstring":    PropFileVersion,
+	"internal name":        PropInternalName,
+	"copyright":            PropLegalCopyright,
+	"trademarks":           PropLegalTrademarks,
+	"legaltrademark":       PropLegalTrademarks,
+	"legaltrademarks1":     PropLegalTrademarks,
+	"originalname":         PropOriginalFilename,
+	"original filename":    PropOriginalFilename,
+	"product":              PropProductName,
+	"productversionstring": PropProductVersion,
+}
+
+// CanonicalPropertyName returns the standard property name matching the name
+// case-insensitively or by a known alias ("Fileversion" and
+// "LegalCopyRight" are FileVersion and LegalCopyright, "Copyright" is
+// LegalCopyright). Other names are returned as is.
+func CanonicalPropertyName(name string) PropertyName {
+	lower := strings.ToLower(name)
+	for _, known := range KnownProperties() {
+		if strings.ToLower(string(known)) == lower {
+			return known
+		}
+	}
+	if alias, ok := propertyAliases[lower]; ok {
+		return alias
+	}
+	return PropertyName(name)
+}
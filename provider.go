@@ -0,0 +1,26 @@
+package fileversion
+
+// Provider is the read-only API of Info. Depend on it instead of Info to
+// substitute the version info in tests, see fileversiontest.Fake.
+type Provider interface {
+	CompanyName() string
+	FileDescription() string
+	FileVersion() string
+	InternalName() string
+	LegalCopyright() string
+	OriginalFilename() string
+	ProductName() string
+	ProductVersion() string
+	Comments() string
+	LegalTrademarks() string
+	PrivateBuild() string
+	SpecialBuild() string
+
+	FixedInfo() FixedFileInfo
+	FixedInfoE() (FixedFileInfo, error)
+	Translations() ([]Locale, error)
+	Properties() ([]Property, error)
+	GetProperty(propertyName string) (string, error)
+	GetPropertyEx(propertyName string) (string, Locale, error)
+	GetPropertyWithLocale(propertyName string, locale Locale) (string, error)
+}
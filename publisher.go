@@ -0,0 +1,142 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// PublisherReport compares CompanyName of the resource with the signer of
+// the file, see PublisherConsistency.
+type PublisherReport struct {
+	CompanyName string
+	// Signed reports whether a signer was found at all. Signers of the
+	// embedded signatures are preferred over ones of the catalogs.
+	Signed bool
+	// CatalogSigned reports whether the signer comes from a catalog.
+	CatalogSigned bool
+	// SignerCommonName and SignerOrganization are the CN and O of the signer
+	// certificate subject.
+	SignerCommonName   string
+	SignerOrganization string
+	// Consistent reports whether CompanyName matches the CN or the O of the
+	// signer compared like Inventory groups vendors ("Contoso, Inc." matches
+	// "Contoso Inc"). It's false for unsigned files and files without
+	// CompanyName.
+	Consistent bool
+}
+
+//nolint:gochecknoglobals
+var (
+	// CryptMsgGetParam and CryptMsgClose are not wrapped by x/sys/windows.
+	crypt32              = windows.NewLazySystemDLL("crypt32.dll")
+	cryptMsgGetParamProc = crypt32.NewProc("CryptMsgGetParam")
+	cryptMsgCloseProc    = crypt32.NewProc("CryptMsgClose")
+
+	oidCommonName   = []byte("2.5.4.3\x00")
+	oidOrganization = []byte("2.5.4.10\x00")
+)
+
+// cmsgSignerCertInfoParam is CMSG_SIGNER_CERT_INFO_PARAM. Source:
+// https://docs.microsoft.com/en-us/windows/win32/api/wincrypt/nf-wincrypt-cryptmsggetparam
+const cmsgSignerCertInfoParam = 7
+
+// PublisherConsistency reads the signer of the file and compares its subject
+// with CompanyName. A mismatch (or a well-known vendor name on an unsigned
+// file, see SuspicionReport) is a common detection signal. The signature
+// itself is not verified, use IsSigned for it.
+func (f Info) PublisherConsistency() (PublisherReport, error) {
+	if f.path == "" {
+		return PublisherReport{}, errors.New("info is not backed by a file")
+	}
+	report := PublisherReport{CompanyName: f.CompanyName()}
+	cn, o, ok, err := signerNames(f.path)
+	if err != nil {
+		return PublisherReport{}, err
+	}
+	if !ok {
+		catalog, catalogSigned, err := catalogOf(f.path)
+		if err != nil {
+			return PublisherReport{}, err
+		}
+		if catalogSigned {
+			if cn, o, ok, err = signerNames(catalog); err != nil {
+				return PublisherReport{}, err
+			}
+			report.CatalogSigned = ok
+		}
+	}
+	if !ok {
+		return report, nil
+	}
+	report.Signed = true
+	report.SignerCommonName = cn
+	report.SignerOrganization = o
+	company := NormalizeCompanyName(report.CompanyName)
+	report.Consistent = company != "" &&
+		(company == NormalizeCompanyName(cn) || company == NormalizeCompanyName(o))
+	return report, nil
+}
+
+// signerNames returns the subject CN and O of the first signer of the
+// Authenticode signature embedded in the file or of the signed catalog.
+func signerNames(path string) (string, string, bool, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return "", "", false, err
+	}
+	var encoding, contentType, formatType uint32
+	var store, msg windows.Handle
+	err = windows.CryptQueryObject(
+		windows.CERT_QUERY_OBJECT_FILE,
+		unsafe.Pointer(pathPtr),
+		windows.CERT_QUERY_CONTENT_FLAG_PKCS7_SIGNED_EMBED|windows.CERT_QUERY_CONTENT_FLAG_PKCS7_SIGNED|windows.CERT_QUERY_CONTENT_FLAG_CTL,
+		windows.CERT_QUERY_FORMAT_FLAG_BINARY,
+		0, &encoding, &contentType, &formatType, &store, &msg, nil,
+	)
+	if errors.Is(err, windows.Errno(windows.CRYPT_E_NO_MATCH)) {
+		return "", "", false, nil
+	}
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to read signature of %q: %w", path, err)
+	}
+	defer windows.CertCloseStore(store, 0)     //nolint:errcheck
+	defer cryptMsgCloseProc.Call(uintptr(msg)) //nolint:errcheck
+
+	var size uint32
+	ret, _, err := cryptMsgGetParamProc.Call(uintptr(msg), cmsgSignerCertInfoParam, 0, 0, uintptr(unsafe.Pointer(&size)))
+	if ret == 0 || size == 0 {
+		return "", "", false, fmt.Errorf("failed to get signer info size: %w", err)
+	}
+	// CERT_INFO points to its own buffer, keep it alive until the lookup.
+	buf := make([]byte, size)
+	ret, _, err = cryptMsgGetParamProc.Call(uintptr(msg), cmsgSignerCertInfoParam, 0,
+		uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)))
+	if ret == 0 {
+		return "", "", false, fmt.Errorf("failed to get signer info: %w", err)
+	}
+	cert, err := windows.CertFindCertificateInStore(store, encoding, 0, windows.CERT_FIND_SUBJECT_CERT,
+		unsafe.Pointer(&buf[0]), nil)
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to find signer certificate: %w", err)
+	}
+	defer windows.CertFreeCertificateContext(cert) //nolint:errcheck
+	return certNameAttribute(cert, oidCommonName), certNameAttribute(cert, oidOrganization), true, nil
+}
+
+// certNameAttribute returns the subject attribute of the certificate with the
+// given zero-terminated OID.
+func certNameAttribute(cert *windows.CertContext, oid []byte) string {
+	n := windows.CertGetNameString(cert, windows.CERT_NAME_ATTR_TYPE, 0, unsafe.Pointer(&oid[0]), nil, 0)
+	if n <= 1 {
+		return ""
+	}
+	name := make([]uint16, n)
+	windows.CertGetNameString(cert, windows.CERT_NAME_ATTR_TYPE, 0, unsafe.Pointer(&oid[0]), &name[0], n)
+	return windows.UTF16ToString(name)
+}
@@ -0,0 +1,196 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// Autorun is an executable started automatically by Windows found in the
+// registry. Err is set if the executable or its version info can't be read.
+type Autorun struct {
+	// Location is the registry key the entry is found in, e.g.
+	// `HKLM\SYSTEM\CurrentControlSet\Services\Foo`.
+	Location string
+	// Name is the service name or the Run value name.
+	Name string
+	// CommandLine is the raw registry value.
+	CommandLine string
+	// Path is the executable extracted from CommandLine.
+	Path string
+	Info Info
+	Err  error
+}
+
+// Registry locations of services and autoruns.
+const (
+	servicesKey = `SYSTEM\CurrentControlSet\Services`
+)
+
+//nolint:gochecknoglobals
+var runKeys = []string{
+	`Software\Microsoft\Windows\CurrentVersion\Run`,
+	`Software\Microsoft\Windows\CurrentVersion\RunOnce`,
+}
+
+// registryViews are the 64-bit and the 32-bit views of the registry. The
+// 32-bit one is redirected to Wow6432Node wherever Windows redirects it, not
+// only in HKLM\Software, and both are the same on 32-bit Windows.
+//
+//nolint:gochecknoglobals
+var registryViews = []struct {
+	access uint32
+	// node is inserted after `Software\` in the locations of the view.
+	node string
+}{
+	{registry.WOW64_64KEY, ""},
+	{registry.WOW64_32KEY, `Wow6432Node\`},
+}
+
+// Services returns version info of the binaries of all the services and
+// drivers registered in `HKLM\SYSTEM\CurrentControlSet\Services`. For the
+// services hosted in svchost it's the ServiceDll of the Parameters subkey (or
+// of the service key itself), the Location and the CommandLine are the ones
+// of the value then. Services without ImagePath are skipped.
+func Services(opts ...Option) ([]Autorun, error) {
+	root, err := openRegistryKey(registry.LOCAL_MACHINE, servicesKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open services key: %w", err)
+	}
+	defer root.Close()
+	names, err := root.ReadSubKeyNames(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enumerate services: %w", err)
+	}
+	var services []Autorun
+	for _, name := range names {
+		key, err := openRegistryKey(root, name)
+		if err != nil {
+			continue
+		}
+		location := `HKLM\` + servicesKey + `\` + name
+		imagePath, _, err := key.GetStringValue("ImagePath")
+		if err != nil || imagePath == "" {
+			key.Close()
+			continue
+		}
+		cmd := imagePath
+		if isSvchost(imagePath) {
+			if dllLocation, dll, ok := serviceDll(key); ok {
+				location, cmd = location+dllLocation, dll
+			}
+		}
+		key.Close()
+		services = append(services, newAutorun(location, name, cmd, opts))
+	}
+	return services, nil
+}
+
+// isSvchost reports whether the service ImagePath starts svchost.exe.
+func isSvchost(imagePath string) bool {
+	path, _ := splitCommandLine(imagePath, isFile)
+	base := strings.ToLower(path[strings.LastIndexAny(path, `\/`)+1:])
+	return base == "svchost.exe" || base == "svchost"
+}
+
+// serviceDll returns the ServiceDll of an svchost service and the subkey it
+// was found in relative to the service key.
+func serviceDll(service registry.Key) (string, string, bool) {
+	if params, err := openRegistryKey(service, "Parameters"); err == nil {
+		dll, _, err := params.GetStringValue("ServiceDll")
+		params.Close()
+		if err == nil && dll != "" {
+			return `\Parameters`, dll, true
+		}
+	}
+	if dll, _, err := service.GetStringValue("ServiceDll"); err == nil && dll != "" {
+		return "", dll, true
+	}
+	return "", "", false
+}
+
+// RunEntries returns version info of the executables started by the Run and
+// RunOnce keys of HKLM and HKCU in both the 64-bit and the 32-bit registry
+// views. The entries of the 32-bit view are located in `Software\Wow6432Node`
+// and skipped if the 64-bit view has them too (the keys shared by the views).
+// Missing keys are skipped.
+func RunEntries(opts ...Option) ([]Autorun, error) {
+	hives := []struct {
+		name string
+		key  registry.Key
+	}{
+		{"HKLM", registry.LOCAL_MACHINE},
+		{"HKCU", registry.CURRENT_USER},
+	}
+	var entries []Autorun
+	for _, hive := range hives {
+		for _, path := range runKeys {
+			// seen are the values of the 64-bit view.
+			seen := make(map[registryValue]bool)
+			for _, view := range registryViews {
+				key, err := registry.OpenKey(hive.key, path, registry.READ|view.access)
+				if err != nil {
+					continue
+				}
+				values, err := stringValues(key)
+				key.Close()
+				location := hive.name + `\` + strings.Replace(path, `Software\`, `Software\`+view.node, 1)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read %s: %w", location, err)
+				}
+				for _, v := range values {
+					if seen[v] {
+						continue
+					}
+					seen[v] = true
+					entries = append(entries, newAutorun(location, v.name, v.value, opts))
+				}
+			}
+		}
+	}
+	return entries, nil
+}
+
+func newAutorun(location, name, cmd string, opts []Option) Autorun {
+	entry := Autorun{Location: location, Name: name, CommandLine: cmd}
+	entry.Path, entry.Err = ExecutableFromCommandLine(cmd)
+	if entry.Err == nil {
+		entry.Info, entry.Err = NewFromCommandLine(cmd, opts...)
+		if entry.Err == nil {
+			entry.Path = entry.Info.path
+		}
+	}
+	return entry
+}
+
+// openRegistryKey opens the key for reading.
+func openRegistryKey(parent registry.Key, path string) (registry.Key, error) {
+	return registry.OpenKey(parent, path, registry.READ)
+}
+
+type registryValue struct {
+	name  string
+	value string
+}
+
+// stringValues returns all the REG_SZ and REG_EXPAND_SZ values of the key
+// skipping other types. Environment variables are not expanded.
+func stringValues(key registry.Key) ([]registryValue, error) {
+	names, err := key.ReadValueNames(0)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]registryValue, 0, len(names))
+	for _, name := range names {
+		value, _, err := key.GetStringValue(name)
+		if err != nil {
+			continue
+		}
+		values = append(values, registryValue{name: name, value: value})
+	}
+	return values, nil
+}
@@ -0,0 +1,118 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// RemoteCredentials are used by NewRemote to establish an SMB session. A nil
+// *RemoteCredentials means the session of the current user or an already
+// established one is used.
+type RemoteCredentials struct {
+	// User is like `DOMAIN\user`. Empty means the current user.
+	User     string
+	Password string
+}
+
+// RemotePath converts a local path on the host like `C:\foo.dll` to the path
+// over the administrative share `\\host\C$\foo.dll`.
+func RemotePath(host, localPath string) (string, error) {
+	share, err := adminShare(host, localPath)
+	if err != nil {
+		return "", err
+	}
+	return share + localPath[2:], nil
+}
+
+// adminShare returns the administrative share `\\host\C$` of the path drive.
+func adminShare(host, localPath string) (string, error) {
+	if len(localPath) < 3 || localPath[1] != ':' || (localPath[2] != '\\' && localPath[2] != '/') {
+		return "", fmt.Errorf("path %q is not an absolute local path", localPath)
+	}
+	return `\\` + strings.TrimLeft(host, `\`) + `\` + localPath[:1] + `$`, nil
+}
+
+// NewRemote creates an Info for localPath on a remote host via its
+// administrative share, so no agent is needed on the host. If creds is not
+// nil an SMB session for the share is established with WNetAddConnection2
+// for the time of the call.
+func NewRemote(host, localPath string, creds *RemoteCredentials, opts ...Option) (Info, error) {
+	path, err := RemotePath(host, localPath)
+	if err != nil {
+		return Info{}, err
+	}
+	if creds != nil {
+		share, _ := adminShare(host, localPath)
+		cancel, err := addConnection(share, creds)
+		if err != nil {
+			return Info{}, fmt.Errorf("failed to connect to %s: %w", share, err)
+		}
+		defer cancel()
+	}
+	return New(path, opts...)
+}
+
+//nolint:gochecknoglobals
+var (
+	// WNet API is not wrapped by x/sys/windows.
+	mpr                       = windows.NewLazySystemDLL("mpr.dll")
+	wNetAddConnection2Proc    = mpr.NewProc("WNetAddConnection2W")
+	wNetCancelConnection2Proc = mpr.NewProc("WNetCancelConnection2W")
+)
+
+// netResource is NETRESOURCEW structure. Source:
+// https://docs.microsoft.com/en-us/windows/win32/api/winnetwk/ns-winnetwk-netresourcew
+type netResource struct {
+	Scope       uint32
+	Type        uint32
+	DisplayType uint32
+	Usage       uint32
+	LocalName   *uint16
+	RemoteName  *uint16
+	Comment     *uint16
+	Provider    *uint16
+}
+
+const resourceTypeDisk = 1
+
+// addConnection establishes a session to the share and returns a function
+// closing it.
+func addConnection(share string, creds *RemoteCredentials) (func(), error) {
+	remoteName, err := windows.UTF16PtrFromString(share)
+	if err != nil {
+		return nil, err
+	}
+	var user, password *uint16
+	if creds.User != "" {
+		if user, err = windows.UTF16PtrFromString(creds.User); err != nil {
+			return nil, err
+		}
+	}
+	if password, err = windows.UTF16PtrFromString(creds.Password); err != nil {
+		return nil, err
+	}
+	resource := netResource{Type: resourceTypeDisk, RemoteName: remoteName}
+	ret, _, _ := wNetAddConnection2Proc.Call(
+		uintptr(unsafe.Pointer(&resource)),
+		uintptr(unsafe.Pointer(password)),
+		uintptr(unsafe.Pointer(user)),
+		0,
+	)
+	switch windows.Errno(ret) {
+	case 0:
+	case windows.ERROR_SESSION_CREDENTIAL_CONFLICT:
+		// A session with other credentials already exists, it's usable as is.
+		return func() {}, nil
+	default:
+		return nil, windows.Errno(ret)
+	}
+	return func() {
+		wNetCancelConnection2Proc.Call(uintptr(unsafe.Pointer(remoteName)), 0, 0) //nolint:errcheck
+	}, nil
+}
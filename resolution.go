@@ -0,0 +1,74 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+// ResolutionSource tells where the translation of a property came from, see
+// ResolutionReport.
+type ResolutionSource int
+
+// Sources of property translations.
+const (
+	// ResolutionNotFound means no translation was found.
+	ResolutionNotFound ResolutionSource = iota
+	// ResolutionDeclared means the translation is declared in
+	// `\VarFileInfo\Translation`.
+	ResolutionDeclared
+	// ResolutionRequested means the translation is not declared but is one
+	// of Info.Locales, e.g. given to NewWithLocale or a system locale.
+	ResolutionRequested
+	// ResolutionFallback means the translation was guessed by the locale
+	// resolver, e.g. taken from DefaultLocales.
+	ResolutionFallback
+)
+
+// String returns the source name.
+func (s ResolutionSource) String() string {
+	switch s {
+	case ResolutionDeclared:
+		return "declared"
+	case ResolutionRequested:
+		return "requested"
+	case ResolutionFallback:
+		return "fallback"
+	default:
+		return "not found"
+	}
+}
+
+// PropertyResolution describes how a standard property was resolved.
+type PropertyResolution struct {
+	Name  PropertyName
+	Value string
+	// Locale is the translation the value was taken from, it's zero if the
+	// property was not found.
+	Locale Locale
+	Source ResolutionSource
+}
+
+// ResolutionReport resolves all the standard properties the way the
+// convenience getters do and reports which translation each value came from,
+// for data-quality audits over large inventories. Properties are listed in
+// the KnownProperties order.
+func (f Info) ResolutionReport() []PropertyResolution {
+	declared, _ := f.Translations()
+	report := make([]PropertyResolution, 0, len(KnownProperties()))
+	for _, name := range KnownProperties() {
+		r := PropertyResolution{Name: name}
+		value, locale, err := f.GetPropertyEx(string(name))
+		if err == nil {
+			r.Value = value
+			r.Locale = locale
+			switch {
+			case containsLocale(declared, locale):
+				r.Source = ResolutionDeclared
+			case containsLocale(f.Locales, locale) && !(f.defaultLocales && containsLocale(DefaultLocales, locale)):
+				r.Source = ResolutionRequested
+			default:
+				r.Source = ResolutionFallback
+			}
+		}
+		report = append(report, r)
+	}
+	return report
+}
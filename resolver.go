@@ -0,0 +1,82 @@
+package fileversion
+
+// LocaleResolver chooses translations GetProperty tries for a property.
+//
+// Candidates receives the locales of the Info (declared in the resource or
+// given to NewWithLocale) and the queried property name and returns locales
+// to try in order. The first translation existing in the resource wins.
+type LocaleResolver interface {
+	Candidates(declared []Locale, propertyName string) []Locale
+}
+
+// LocaleResolverFunc is an adapter allowing to use an ordinary function as a
+// LocaleResolver.
+type LocaleResolverFunc func(declared []Locale, propertyName string) []Locale
+
+// Candidates calls r(declared, propertyName).
+func (r LocaleResolverFunc) Candidates(declared []Locale, propertyName string) []Locale {
+	return r(declared, propertyName)
+}
+
+// DefaultLocaleResolver is the resolver used unless WithLocaleResolver is
+// given. It tries the declared locales and then DefaultLocales, each locale is
+// tried once in the order of the first occurrence.
+//
+//nolint:gochecknoglobals
+var DefaultLocaleResolver LocaleResolver = LocaleResolverFunc(defaultCandidates)
+
+func defaultCandidates(declared []Locale, _ string) []Locale {
+	// Some dlls might not contain correct codepage information. In this case we will fail during lookup.
+	// Explorer will take a few shots in dark by trying `defaultPageIDs`.
+	// Explorer also randomly guess 041D04B0=Swedish+CP_UNICODE and 040704B0=German+CP_UNICODE) sometimes.
+	// We will try to simulate similar behavior here.
+	return mergeLocales(declared, DefaultLocales)
+}
+
+// StrictLocaleResolver tries the declared locales only, see WithNoFallback.
+//
+//nolint:gochecknoglobals
+var StrictLocaleResolver LocaleResolver = LocaleResolverFunc(strictCandidates)
+
+func strictCandidates(declared []Locale, _ string) []Locale {
+	return declared
+}
+
+// ExhaustiveLocaleResolver tries the declared locales and then all the
+// combinations of {declared languages, LangEnglishUS, LangNeutral} and
+// {CSUnicode, CSAscii, CSUnknown}. It finds translations in resources authored
+// with the neutral language or with a charset not matching the declared one.
+//
+//nolint:gochecknoglobals
+var ExhaustiveLocaleResolver LocaleResolver = LocaleResolverFunc(exhaustiveCandidates)
+
+func exhaustiveCandidates(declared []Locale, _ string) []Locale {
+	langs := make([]LangID, 0, len(declared)+2)
+	for _, l := range declared {
+		langs = append(langs, l.LangID)
+	}
+	langs = append(langs, LangEnglishUS, LangNeutral)
+
+	combinations := make([]Locale, 0, len(langs)*3)
+	for _, lang := range langs {
+		for _, cs := range []CharsetID{CSUnicode, CSAscii, CSUnknown} {
+			combinations = append(combinations, Locale{LangID: lang, CharsetID: cs})
+		}
+	}
+	return mergeLocales(declared, combinations)
+}
+
+// mergeLocales concatenates the lists dropping duplicates.
+func mergeLocales(lists ...[]Locale) []Locale {
+	var merged []Locale
+	seen := make(map[Locale]bool)
+	for _, list := range lists {
+		for _, l := range list {
+			if !seen[l] {
+				seen[l] = true
+				merged = append(merged, l)
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,355 @@
+package fileversion
+
+import (
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+)
+
+// ResourceID is either an integer ID or a string name of a resource type or a
+// resource name. Name is empty for integer IDs.
+type ResourceID struct {
+	ID   uint16
+	Name string
+}
+
+// String returns the name or the decimal ID prefixed by "#" like resource
+// compilers do.
+func (r ResourceID) String() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("#%d", r.ID)
+}
+
+// ResourceEntry is a leaf of the PE resource directory tree.
+type ResourceEntry struct {
+	Type ResourceID
+	Name ResourceID
+	Lang LangID
+	// Size is the size of the resource data in bytes.
+	Size uint32
+	// CodePage is the code page of the data declared in the data entry. It's
+	// usually zero.
+	CodePage uint32
+	rva      uint32
+}
+
+// Standard resource types. Source:
+// https://docs.microsoft.com/en-us/windows/win32/menurc/resource-types
+//
+//nolint:gochecknoglobals
+var resourceTypeNames = map[uint16]string{
+	1:  "RT_CURSOR",
+	2:  "RT_BITMAP",
+	3:  "RT_ICON",
+	4:  "RT_MENU",
+	5:  "RT_DIALOG",
+	6:  "RT_STRING",
+	7:  "RT_FONTDIR",
+	8:  "RT_FONT",
+	9:  "RT_ACCELERATOR",
+	10: "RT_RCDATA",
+	11: "RT_MESSAGETABLE",
+	12: "RT_GROUP_CURSOR",
+	14: "RT_GROUP_ICON",
+	16: "RT_VERSION",
+	17: "RT_DLGINCLUDE",
+	19: "RT_PLUGPLAY",
+	20: "RT_VXD",
+	21: "RT_ANICURSOR",
+	22: "RT_ANIICON",
+	23: "RT_HTML",
+	24: "RT_MANIFEST",
+}
+
+// TypeName returns the RT_* name of a standard resource type ID, e.g.
+// "RT_MANIFEST", and String otherwise.
+func (r ResourceID) TypeName() string {
+	if name, ok := resourceTypeNames[r.ID]; ok && r.Name == "" {
+		return name
+	}
+	return r.String()
+}
+
+// rvaReader reads size bytes of an image at the relative virtual address rva.
+type rvaReader func(rva, size uint32) ([]byte, error)
+
+// RT_VERSION resource type and resource directory parsing constants. Source:
+// https://docs.microsoft.com/en-us/windows/win32/debug/pe-format#the-rsrc-section
+const (
+	rtVersion = 16
+
+	imageDirectoryEntryResource = 2
+	resourceDirectorySize       = 16
+	resourceDirectoryEntrySize  = 8
+	resourceDataEntrySize       = 16
+	resourceHighBit             = 0x80000000
+	// maxResourceEntries limits the directory walk over malformed images.
+	maxResourceEntries = 1 << 16
+)
+
+// readResources walks the whole 3-level (type, name, language) resource tree.
+// The directory tables are read one by one: the directory Size comes from
+// the file and is only used as a bound, so a corrupt header can't make the
+// walk allocate more than the tables it really contains.
+func readResources(file *pe.File, read rvaReader) ([]ResourceEntry, error) {
+	dir, ok := dataDirectory(file, imageDirectoryEntryResource)
+	if !ok || dir.VirtualAddress == 0 || dir.Size == 0 {
+		return nil, nil
+	}
+	w := resourceWalker{read: read, base: dir.VirtualAddress, size: dir.Size}
+	if err := w.walk(0, 0, nil); err != nil {
+		return nil, err
+	}
+	return w.entries, nil
+}
+
+type resourceWalker struct {
+	read    rvaReader
+	base    uint32
+	size    uint32
+	entries []ResourceEntry
+	visited int
+}
+
+// bytes reads n bytes at the offset from the start of the resource
+// directory, what is the string at the offset for error messages.
+func (w *resourceWalker) bytes(offset, n uint64, what string) ([]byte, error) {
+	if offset+n > uint64(w.size) {
+		return nil, fmt.Errorf("%s at %#x is out of the section", what, offset)
+	}
+	data, err := w.read(w.base+uint32(offset), uint32(n))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %#x: %w", what, offset, err)
+	}
+	return data, nil
+}
+
+func (w *resourceWalker) walk(offset uint32, level int, path []ResourceID) error {
+	if level > 2 {
+		return errors.New("resource directory is nested too deep")
+	}
+	header, err := w.bytes(uint64(offset), resourceDirectorySize, "resource directory")
+	if err != nil {
+		return err
+	}
+	count := int(binary.LittleEndian.Uint16(header[12:])) + int(binary.LittleEndian.Uint16(header[14:]))
+	if w.visited += count; w.visited > maxResourceEntries {
+		return errors.New("too many resource entries")
+	}
+	table, err := w.bytes(uint64(offset)+resourceDirectorySize, uint64(count)*resourceDirectoryEntrySize,
+		"resource directory entries")
+	if err != nil {
+		return err
+	}
+	for i := 0; i < count; i++ {
+		nameField := binary.LittleEndian.Uint32(table[i*resourceDirectoryEntrySize:])
+		dataField := binary.LittleEndian.Uint32(table[i*resourceDirectoryEntrySize+4:])
+
+		id, err := w.id(nameField)
+		if err != nil {
+			return err
+		}
+		entryPath := append(path[:level:level], id)
+		if dataField&resourceHighBit != 0 {
+			if err := w.walk(dataField&^resourceHighBit, level+1, entryPath); err != nil {
+				return err
+			}
+			continue
+		}
+		if level != 2 {
+			// A data entry at the type or name level is malformed, skip it.
+			continue
+		}
+		data, err := w.bytes(uint64(dataField), resourceDataEntrySize, "resource data entry")
+		if err != nil {
+			return err
+		}
+		w.entries = append(w.entries, ResourceEntry{
+			Type:     entryPath[0],
+			Name:     entryPath[1],
+			Lang:     LangID(id.ID),
+			Size:     binary.LittleEndian.Uint32(data[4:]),
+			CodePage: binary.LittleEndian.Uint32(data[8:]),
+			rva:      binary.LittleEndian.Uint32(data),
+		})
+	}
+	return nil
+}
+
+// id decodes a Name field of a resource directory entry.
+func (w *resourceWalker) id(field uint32) (ResourceID, error) {
+	if field&resourceHighBit == 0 {
+		return ResourceID{ID: uint16(field)}, nil
+	}
+	offset := uint64(field &^ resourceHighBit)
+	length, err := w.bytes(offset, 2, "resource name")
+	if err != nil {
+		return ResourceID{}, err
+	}
+	n := uint64(binary.LittleEndian.Uint16(length))
+	name, err := w.bytes(offset+2, 2*n, "resource name")
+	if err != nil {
+		return ResourceID{}, err
+	}
+	u16 := make([]uint16, n)
+	for i := range u16 {
+		u16[i] = binary.LittleEndian.Uint16(name[2*i:])
+	}
+	return ResourceID{Name: string(utf16.Decode(u16))}, nil
+}
+
+// fileRVAReader reads RVAs of an image stored in a file layout.
+func fileRVAReader(file *pe.File) rvaReader {
+	return func(rva, size uint32) ([]byte, error) {
+		return readRVA(file, rva, size)
+	}
+}
+
+// ListResources returns all the resources of the PE file in the directory
+// order: sorted by type, then by name and by language, named entries first.
+// The resource directory is parsed in pure Go, the file isn't loaded.
+func ListResources(path string) ([]ResourceEntry, error) {
+	file, err := pe.Open(path)
+	if err != nil {
+		if format := detectFileFormat(path); format != FormatUnknown && format != FormatPE {
+			return nil, fmt.Errorf("failed to parse PE headers of %q: %w: %s executable", path, ErrUnsupportedFormat, format)
+		}
+		return nil, fmt.Errorf("failed to parse PE headers of %q: %w", path, err)
+	}
+	defer file.Close()
+	entries, err := readResources(file, fileRVAReader(file))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resources: %w", err)
+	}
+	return entries, nil
+}
+
+// NewFromReader creates an Info from a PE image read from r without touching
+// the file system, e.g. from memory or from an archive entry. The resource
+// section is parsed in pure Go, the version-information resource is then
+// queried the same way as for New.
+//
+// If the image has several version-information resources the first one (with
+// the lowest language ID) is used.
+func NewFromReader(r io.ReaderAt, opts ...Option) (Info, error) {
+	file, err := pe.NewFile(r)
+	if err != nil {
+		if formatErr := unsupportedFormatError(r); formatErr != nil {
+			return Info{}, formatErr
+		}
+		return Info{}, fmt.Errorf("failed to parse PE headers: %w: %v", ErrNotPE, err)
+	}
+	defer file.Close()
+	return newFromImage(file, fileRVAReader(file), newOptions(opts))
+}
+
+func newFromImage(file *pe.File, read rvaReader, o options) (Info, error) {
+	entries, err := readResources(file, read)
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to read resources: %w", err)
+	}
+	for _, e := range entries {
+		if e.Type.Name != "" || e.Type.ID != rtVersion {
+			continue
+		}
+		if err := o.resourceTooLarge("ReadResource", "", uint64(e.Size)); err != nil {
+			return Info{}, fmt.Errorf("failed to read version resource: %w", err)
+		}
+		block, err := read(e.rva, e.Size)
+		if err != nil {
+			return Info{}, fmt.Errorf("failed to read version resource: %w", err)
+		}
+		return newFromBlock(block, o)
+	}
+	return Info{}, ErrNoVersionInfo
+}
+
+// VersionResource is one of RT_VERSION resources of an image. Err is set if
+// the resource can't be read or parsed.
+type VersionResource struct {
+	Name ResourceID
+	Lang LangID
+	Info Info
+	Err  error
+}
+
+// NewAll reads every version-information resource of the PE file, unlike New
+// which gets only the one chosen by GetFileVersionInfo. Images carrying
+// several resources with different names or languages may show different
+// data to different tools, so triage code should look at all of them.
+func NewAll(path string, opts ...Option) ([]VersionResource, error) {
+	file, err := pe.Open(path)
+	if err != nil {
+		if format := detectFileFormat(path); format != FormatUnknown && format != FormatPE {
+			return nil, fmt.Errorf("failed to parse PE headers of %q: %w: %s executable", path, ErrUnsupportedFormat, format)
+		}
+		return nil, fmt.Errorf("failed to parse PE headers of %q: %w", path, err)
+	}
+	defer file.Close()
+	resources, err := newAllFromImage(file, fileRVAReader(file), newOptions(opts))
+	for i := range resources {
+		if resources[i].Err == nil {
+			resources[i].Info.path = path
+		}
+	}
+	return resources, err
+}
+
+// NewAllFromReader is like NewAll but reads the image from r.
+func NewAllFromReader(r io.ReaderAt, opts ...Option) ([]VersionResource, error) {
+	file, err := pe.NewFile(r)
+	if err != nil {
+		if formatErr := unsupportedFormatError(r); formatErr != nil {
+			return nil, formatErr
+		}
+		return nil, fmt.Errorf("failed to parse PE headers: %w: %v", ErrNotPE, err)
+	}
+	defer file.Close()
+	return newAllFromImage(file, fileRVAReader(file), newOptions(opts))
+}
+
+func newAllFromImage(file *pe.File, read rvaReader, o options) ([]VersionResource, error) {
+	entries, err := readResources(file, read)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resources: %w", err)
+	}
+	var resources []VersionResource
+	for _, e := range entries {
+		if e.Type.Name != "" || e.Type.ID != rtVersion {
+			continue
+		}
+		r := VersionResource{Name: e.Name, Lang: e.Lang}
+		if err := o.resourceTooLarge("ReadResource", "", uint64(e.Size)); err != nil {
+			r.Err = fmt.Errorf("failed to read version resource: %w", err)
+			resources = append(resources, r)
+			continue
+		}
+		block, err := read(e.rva, e.Size)
+		if err != nil {
+			r.Err = fmt.Errorf("failed to read version resource: %w", err)
+		} else {
+			r.Info, r.Err = newFromBlock(block, o)
+		}
+		resources = append(resources, r)
+	}
+	if len(resources) == 0 {
+		return nil, ErrNoVersionInfo
+	}
+	return resources, nil
+}
+
+// newFromBlock creates an Info from a raw VS_VERSIONINFO resource.
+func newFromBlock(block []byte, o options) (Info, error) {
+	// The block may reference the reader memory, so it's copied.
+	info := newInfo("", append([]byte(nil), block...))
+	if err := info.tree.err; err != nil {
+		return Info{}, err
+	}
+	info.initLocales(o)
+	return info, nil
+}
@@ -0,0 +1,34 @@
+//go:build go1.18
+// +build go1.18
+
+package fileversion_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bi-zone/go-fileversion"
+	"github.com/bi-zone/go-fileversion/fileversiontest"
+)
+
+func FuzzNewFromReader(f *testing.F) {
+	resources := fuzzResources()
+	for _, r := range resources {
+		image := fileversiontest.PE(r)
+		f.Add(image)
+		f.Add(image[:len(image)-len(r.Block())/2])
+	}
+	f.Add(fileversiontest.PE(resources...))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// A small image must not make the reader allocate gigabytes, the
+		// limit turns such resources into errors.
+		info, err := fileversion.NewFromReader(bytes.NewReader(data), fileversion.WithMaxResourceSize(1<<16))
+		if err != nil {
+			return
+		}
+		_, _ = info.FixedInfoE()
+		_, _ = info.Translations()
+		_, _ = info.Properties()
+		_ = info.CompanyName()
+	})
+}
@@ -0,0 +1,268 @@
+// Package sbom writes CycloneDX and SPDX documents describing PE images by
+// their version information: the component name is ProductName (or the file
+// name if it's missing), the version is FileVersion and the supplier is
+// CompanyName.
+//
+// The writers take fileversion.Provider values, so they work on every
+// platform, e.g. with the Info values NewFromReader reads server-side. A
+// Provider also implementing Path (like fileversion.Info does) names the
+// file, which is then hashed with SHA-256. Providers without a path are
+// described without the hash.
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bi-zone/go-fileversion"
+)
+
+// now is the creation time of the documents, replaced in tests.
+//
+//nolint:gochecknoglobals
+var now = time.Now
+
+// pather is implemented by the providers backed by a file.
+type pather interface {
+	Path() string
+}
+
+// component is a described file.
+type component struct {
+	name     string
+	version  string
+	supplier string
+	path     string
+	sha256   string
+}
+
+// newComponents collects the components of the files. Files without a name
+// (no ProductName, OriginalFilename or path) are skipped: both formats
+// require one.
+func newComponents(files []fileversion.Provider) ([]component, error) {
+	components := make([]component, 0, len(files))
+	for _, file := range files {
+		var path string
+		if p, ok := file.(pather); ok {
+			path = p.Path()
+		}
+		c := component{
+			name:     file.ProductName(),
+			version:  file.FileVersion(),
+			supplier: file.CompanyName(),
+			path:     path,
+		}
+		if c.name == "" {
+			c.name = file.OriginalFilename()
+		}
+		if c.name == "" {
+			c.name = baseName(path)
+		}
+		if c.name == "" {
+			continue
+		}
+		if path != "" {
+			hash, err := fileSHA256(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to hash %q: %w", path, err)
+			}
+			c.sha256 = hash
+		}
+		components = append(components, c)
+	}
+	return components, nil
+}
+
+// baseName is filepath.Base of a Windows path on any platform.
+func baseName(path string) string {
+	return path[strings.LastIndexAny(path, `\/:`)+1:]
+}
+
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// WriteCycloneDX writes a CycloneDX 1.4 JSON document describing the given
+// files to w.
+//
+// Ref: https://cyclonedx.org/docs/1.4/json/
+func WriteCycloneDX(w io.Writer, files []fileversion.Provider) error {
+	components, err := newComponents(files)
+	if err != nil {
+		return fmt.Errorf("failed to collect components: %w", err)
+	}
+
+	type hash struct {
+		Alg     string `json:"alg"`
+		Content string `json:"content"`
+	}
+	type property struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	type supplier struct {
+		Name string `json:"name"`
+	}
+	type cdxComponent struct {
+		Type       string     `json:"type"`
+		Name       string     `json:"name"`
+		Version    string     `json:"version,omitempty"`
+		Supplier   *supplier  `json:"supplier,omitempty"`
+		Hashes     []hash     `json:"hashes,omitempty"`
+		Properties []property `json:"properties,omitempty"`
+	}
+	type metadata struct {
+		Timestamp string `json:"timestamp"`
+	}
+	type bom struct {
+		BOMFormat   string         `json:"bomFormat"`
+		SpecVersion string         `json:"specVersion"`
+		Version     int            `json:"version"`
+		Metadata    metadata       `json:"metadata"`
+		Components  []cdxComponent `json:"components"`
+	}
+
+	doc := bom{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.4",
+		Version:     1,
+		Metadata:    metadata{Timestamp: now().UTC().Format(time.RFC3339)},
+		Components:  make([]cdxComponent, 0, len(components)),
+	}
+	for _, c := range components {
+		cc := cdxComponent{
+			Type:    "file",
+			Name:    c.name,
+			Version: c.version,
+		}
+		if c.sha256 != "" {
+			cc.Hashes = []hash{{Alg: "SHA-256", Content: c.sha256}}
+		}
+		if c.path != "" {
+			cc.Properties = []property{{Name: "fileversion:path", Value: c.path}}
+		}
+		if c.supplier != "" {
+			cc.Supplier = &supplier{Name: c.supplier}
+		}
+		doc.Components = append(doc.Components, cc)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode CycloneDX document: %w", err)
+	}
+	return nil
+}
+
+// WriteSPDX writes an SPDX 2.3 JSON document describing the given files to w.
+// Every file becomes a package the document DESCRIBES. The name and the
+// namespace (an unique URI) of the document are required by the
+// specification and must be provided by the caller.
+//
+// Ref: https://spdx.github.io/spdx-spec/v2.3/
+func WriteSPDX(w io.Writer, name, namespace string, files []fileversion.Provider) error {
+	components, err := newComponents(files)
+	if err != nil {
+		return fmt.Errorf("failed to collect components: %w", err)
+	}
+
+	type checksum struct {
+		Algorithm     string `json:"algorithm"`
+		ChecksumValue string `json:"checksumValue"`
+	}
+	type pkg struct {
+		SPDXID           string     `json:"SPDXID"`
+		Name             string     `json:"name"`
+		VersionInfo      string     `json:"versionInfo,omitempty"`
+		Supplier         string     `json:"supplier"`
+		PackageFileName  string     `json:"packageFileName,omitempty"`
+		DownloadLocation string     `json:"downloadLocation"`
+		FilesAnalyzed    bool       `json:"filesAnalyzed"`
+		Checksums        []checksum `json:"checksums,omitempty"`
+	}
+	type relationship struct {
+		SPDXElementID      string `json:"spdxElementId"`
+		RelationshipType   string `json:"relationshipType"`
+		RelatedSPDXElement string `json:"relatedSpdxElement"`
+	}
+	type creationInfo struct {
+		Created  string   `json:"created"`
+		Creators []string `json:"creators"`
+	}
+	type document struct {
+		SPDXVersion       string         `json:"spdxVersion"`
+		DataLicense       string         `json:"dataLicense"`
+		SPDXID            string         `json:"SPDXID"`
+		Name              string         `json:"name"`
+		DocumentNamespace string         `json:"documentNamespace"`
+		CreationInfo      creationInfo   `json:"creationInfo"`
+		DocumentDescribes []string       `json:"documentDescribes"`
+		Packages          []pkg          `json:"packages"`
+		Relationships     []relationship `json:"relationships"`
+	}
+
+	const documentID = "SPDXRef-DOCUMENT"
+	doc := document{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            documentID,
+		Name:              name,
+		DocumentNamespace: namespace,
+		CreationInfo: creationInfo{
+			Created:  now().UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: go-fileversion"},
+		},
+		DocumentDescribes: make([]string, 0, len(components)),
+		Packages:          make([]pkg, 0, len(components)),
+		Relationships:     make([]relationship, 0, len(components)),
+	}
+	for i, c := range components {
+		id := "SPDXRef-Package-" + strconv.Itoa(i+1)
+		supplier := "NOASSERTION"
+		if c.supplier != "" {
+			supplier = "Organization: " + c.supplier
+		}
+		p := pkg{
+			SPDXID:           id,
+			Name:             c.name,
+			VersionInfo:      c.version,
+			Supplier:         supplier,
+			PackageFileName:  c.path,
+			DownloadLocation: "NOASSERTION",
+		}
+		if c.sha256 != "" {
+			p.Checksums = []checksum{{Algorithm: "SHA256", ChecksumValue: c.sha256}}
+		}
+		doc.Packages = append(doc.Packages, p)
+		doc.DocumentDescribes = append(doc.DocumentDescribes, id)
+		doc.Relationships = append(doc.Relationships, relationship{
+			SPDXElementID:      documentID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: id,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode SPDX document: %w", err)
+	}
+	return nil
+}
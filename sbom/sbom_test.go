@@ -0,0 +1,94 @@
+package sbom
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bi-zone/go-fileversion"
+	"github.com/bi-zone/go-fileversion/fileversiontest"
+)
+
+//nolint:gochecknoglobals
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// fileProvider is a Fake backed by a file.
+type fileProvider struct {
+	fileversiontest.Fake
+	path string
+}
+
+func (p fileProvider) Path() string { return p.path }
+
+func testFiles() []fileversion.Provider {
+	return []fileversion.Provider{
+		fileProvider{
+			Fake: fileversiontest.Fake{Strings: map[string]string{
+				string(fileversion.PropProductName): "Contoso Tools",
+				string(fileversion.PropFileVersion): "1.2.3.4",
+				string(fileversion.PropCompanyName): "Contoso Ltd.",
+			}},
+			// A literal slash keeps the golden files the same on windows.
+			path: "testdata/sample.dll",
+		},
+		// Read from a reader: no path and no hash.
+		fileversiontest.Fake{Strings: map[string]string{
+			string(fileversion.PropOriginalFilename): "remote.exe",
+			string(fileversion.PropFileVersion):      "10.0",
+		}},
+		// Nothing to name the component by.
+		fileversiontest.Fake{},
+	}
+}
+
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	golden := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(golden, got, 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("document differs from %s:\n%s", golden, got)
+	}
+}
+
+func setNow(t *testing.T) {
+	t.Helper()
+	now = func() time.Time { return time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { now = time.Now })
+}
+
+func TestWriteCycloneDX(t *testing.T) {
+	setNow(t)
+	var buf bytes.Buffer
+	if err := WriteCycloneDX(&buf, testFiles()); err != nil {
+		t.Fatalf("WriteCycloneDX() error = %v", err)
+	}
+	checkGolden(t, "cyclonedx.golden.json", buf.Bytes())
+}
+
+func TestWriteSPDX(t *testing.T) {
+	setNow(t)
+	var buf bytes.Buffer
+	if err := WriteSPDX(&buf, "inventory", "https://example.com/spdx/inventory", testFiles()); err != nil {
+		t.Fatalf("WriteSPDX() error = %v", err)
+	}
+	checkGolden(t, "spdx.golden.json", buf.Bytes())
+}
+
+func TestWriteMissingFile(t *testing.T) {
+	files := []fileversion.Provider{fileProvider{path: filepath.Join("testdata", "missing.dll")}}
+	if err := WriteCycloneDX(&bytes.Buffer{}, files); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("WriteCycloneDX() error = %v, want not exist", err)
+	}
+}
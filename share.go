@@ -0,0 +1,137 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"debug/pe"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+
+	"golang.org/x/sys/windows"
+)
+
+//nolint:gochecknoglobals
+var (
+	// RtlGetLastNtStatus is not wrapped by x/sys/windows.
+	ntdll                  = windows.NewLazySystemDLL("ntdll.dll")
+	rtlGetLastNtStatusProc = ntdll.NewProc("RtlGetLastNtStatus")
+)
+
+// statusDeletePending is STATUS_DELETE_PENDING, windows reports it as
+// ERROR_ACCESS_DENIED. Source:
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-erref/596a1078-e883-4972-9bbc-49e60bebca55
+const statusDeletePending = 0xc0000056
+
+// WithShareDelete makes New and NewWithLocale open the file with all the
+// sharing modes including FILE_SHARE_DELETE and parse the resource in pure Go
+// (like NewFromReader does) instead of calling GetFileVersionInfoEx. The
+// loader opens images without FILE_SHARE_DELETE, so it fails with a sharing
+// violation on files an uninstaller holds open for deletion. Files already
+// pending deletion can't be opened by path at all: New fails with an error
+// matching ErrDeletePending, read them by a handle with NewFromHandle.
+//
+// MUI satellites are not looked up in this mode, WithVersionInfoFlags is
+// ignored.
+func WithShareDelete() Option {
+	return func(o *options) {
+		o.shareDelete = true
+	}
+}
+
+// readSharedVersionInfo reads the resource for WithShareDelete.
+func readSharedVersionInfo(path string, o options) (Info, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to convert image path to utf16: %w", err)
+	}
+	// The NT status is per thread, it must be read on the thread of the call.
+	runtime.LockOSThread()
+	handle, err := windows.CreateFile(pathPtr, windows.GENERIC_READ,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil, windows.OPEN_EXISTING, windows.FILE_ATTRIBUTE_NORMAL, 0)
+	var status uintptr
+	if err != nil && rtlGetLastNtStatusProc.Find() == nil {
+		status, _, _ = rtlGetLastNtStatusProc.Call()
+	}
+	runtime.UnlockOSThread()
+	if err != nil {
+		if uint32(status) == statusDeletePending {
+			return Info{}, &Error{Op: "CreateFile", Path: path, Kind: ErrDeletePending, Err: err}
+		}
+		return Info{}, newWindowsError("CreateFile", path, err)
+	}
+	defer windows.CloseHandle(handle) //nolint:errcheck
+
+	info, err := newFromHandle(handle, o)
+	if err != nil {
+		return Info{}, err
+	}
+	info.path = path
+	return info, nil
+}
+
+// NewFromHandle creates an Info from a PE image opened by the caller. The
+// handle must have read access, it's not closed. Reads at explicit offsets
+// still move the file pointer of synchronous handles, so the position is
+// saved and restored around them. It's the way to read files pending
+// deletion and files opened with minimal sharing modes, e.g. by a driver or
+// an uninstaller. The resource is parsed in pure Go like NewFromReader does.
+func NewFromHandle(handle windows.Handle, opts ...Option) (Info, error) {
+	if pos, err := windows.Seek(handle, 0, io.SeekCurrent); err == nil {
+		defer windows.Seek(handle, pos, io.SeekStart) //nolint:errcheck
+	}
+	info, err := newFromHandle(handle, newOptions(opts))
+	if err != nil {
+		return Info{}, err
+	}
+	if id, err := handleIdentity(handle); err == nil {
+		info.identity = &id
+	}
+	return info, nil
+}
+
+func newFromHandle(handle windows.Handle, o options) (Info, error) {
+	r := handleReader{handle: handle}
+	file, err := pe.NewFile(r)
+	if err != nil {
+		if formatErr := unsupportedFormatError(r); formatErr != nil {
+			return Info{}, formatErr
+		}
+		return Info{}, fmt.Errorf("failed to parse PE headers: %w: %v", ErrNotPE, err)
+	}
+	defer file.Close()
+	return newFromImage(file, fileRVAReader(file), o)
+}
+
+// handleReader reads a file by its handle at explicit offsets, so the file
+// pointer of the caller's handle is not relied on (but it's moved for
+// synchronous handles, see NewFromHandle).
+type handleReader struct {
+	handle windows.Handle
+}
+
+// ReadAt implements io.ReaderAt. Handles opened for overlapped I/O are
+// waited for.
+func (r handleReader) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	overlapped := windows.Overlapped{Offset: uint32(off), OffsetHigh: uint32(off >> 32)}
+	var n uint32
+	err := windows.ReadFile(r.handle, p, &n, &overlapped)
+	if errors.Is(err, windows.ERROR_IO_PENDING) {
+		err = windows.GetOverlappedResult(r.handle, &overlapped, &n, true)
+	}
+	switch {
+	case errors.Is(err, windows.ERROR_HANDLE_EOF):
+		return int(n), io.EOF
+	case err != nil:
+		return int(n), fmt.Errorf("failed to read file at %#x: %w", off, err)
+	case int(n) < len(p):
+		return int(n), io.EOF
+	}
+	return int(n), nil
+}
@@ -0,0 +1,136 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// shellPropertyKey is PROPERTYKEY structure. Source:
+// https://docs.microsoft.com/en-us/windows/win32/api/wtypes/ns-wtypes-propertykey
+type shellPropertyKey struct {
+	fmtid windows.GUID
+	pid   uint32
+}
+
+// propVariant is PROPVARIANT structure, only its size matters here.
+type propVariant struct {
+	vt       uint16
+	reserved [3]uint16
+	value    [2]uintptr
+}
+
+//nolint:gochecknoglobals
+var (
+	// The shell property system is not wrapped by x/sys/windows.
+	shell32                               = windows.NewLazySystemDLL("shell32.dll")
+	propsys                               = windows.NewLazySystemDLL("propsys.dll")
+	ole32                                 = windows.NewLazySystemDLL("ole32.dll")
+	shGetPropertyStoreFromParsingNameProc = shell32.NewProc("SHGetPropertyStoreFromParsingName")
+	propVariantToStringAllocProc          = propsys.NewProc("PropVariantToStringAlloc")
+	propVariantClearProc                  = ole32.NewProc("PropVariantClear")
+	iidIPropertyStore                     = windows.GUID{Data1: 0x886d8eeb, Data2: 0x8cf2, Data3: 0x4446, Data4: [8]byte{0x8d, 0x02, 0xcd, 0xba, 0x1d, 0xbd, 0xcf, 0x99}}
+	fmtidVersion                          = windows.GUID{Data1: 0x0cef7d53, Data2: 0xfa64, Data3: 0x11d1, Data4: [8]byte{0xa2, 0x03, 0x00, 0x00, 0xf8, 0x1f, 0xed, 0xee}}
+	fmtidDocumentSummaryInformation       = windows.GUID{Data1: 0xd5cdd502, Data2: 0x2e9c, Data3: 0x101b, Data4: [8]byte{0x93, 0x97, 0x08, 0x00, 0x2b, 0x2c, 0xf9, 0xae}}
+	fmtidCopyright                        = windows.GUID{Data1: 0x64440492, Data2: 0x4c8b, Data3: 0x11d1, Data4: [8]byte{0x8b, 0x70, 0x08, 0x00, 0x36, 0xb1, 0x1a, 0x03}}
+	shellPropertyKeys                     = map[PropertyName]shellPropertyKey{
+		PropCompanyName:      {fmtidDocumentSummaryInformation, 15}, // System.Company
+		PropFileDescription:  {fmtidVersion, 3},                     // System.FileDescription
+		PropFileVersion:      {fmtidVersion, 4},                     // System.FileVersion
+		PropOriginalFilename: {fmtidVersion, 6},                     // System.OriginalFileName
+		PropProductName:      {fmtidVersion, 7},                     // System.Software.ProductName
+		PropProductVersion:   {fmtidVersion, 8},                     // System.Software.ProductVersion
+		PropLegalCopyright:   {fmtidCopyright, 11},                  // System.Copyright
+	}
+)
+
+// IPropertyStore vtable indexes and SHGetPropertyStoreFromParsingName flags.
+// Source: https://docs.microsoft.com/en-us/windows/win32/api/propsys/nn-propsys-ipropertystore
+const (
+	vtableRelease  = 2
+	vtableGetValue = 5
+	gpsDefault     = 0
+)
+
+// comObject is the memory layout of a COM interface pointer target.
+type comObject struct {
+	vtable *[8]uintptr
+}
+
+// ShellProperties returns the version properties the way Explorer shows them
+// on the Details tab. Explorer reads them from the shell property system
+// (System.FileVersion and so on), which may differ from the raw resource:
+// e.g. it prefers MUI-localized strings and formats the versions itself.
+//
+// Only the properties the property system exposes are returned:
+// CompanyName, FileDescription, FileVersion, OriginalFilename, ProductName,
+// ProductVersion and LegalCopyright. Empty values are omitted.
+func ShellProperties(path string) (map[PropertyName]string, error) {
+	if err := shGetPropertyStoreFromParsingNameProc.Find(); err != nil {
+		return nil, fmt.Errorf("shell property store is not available: %w", err)
+	}
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// COM is initialized per thread, keep the goroutine on it.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	switch err := windows.CoInitializeEx(0, windows.COINIT_MULTITHREADED); {
+	case err == nil, errors.Is(err, syscall.Errno(1)): // S_FALSE: already initialized.
+		defer windows.CoUninitialize()
+	case errors.Is(err, syscall.Errno(windows.RPC_E_CHANGED_MODE)):
+		// The thread is already in an apartment, use it as is.
+	default:
+		return nil, fmt.Errorf("failed to initialize COM: %w", err)
+	}
+
+	var store *comObject
+	hr, _, _ := shGetPropertyStoreFromParsingNameProc.Call(
+		uintptr(unsafe.Pointer(pathPtr)),
+		0,
+		gpsDefault,
+		uintptr(unsafe.Pointer(&iidIPropertyStore)),
+		uintptr(unsafe.Pointer(&store)),
+	)
+	if hr != 0 {
+		return nil, fmt.Errorf("failed to get property store of %q: %w", path, syscall.Errno(hr))
+	}
+	defer comCall(store, vtableRelease)
+
+	properties := make(map[PropertyName]string)
+	for name, key := range shellPropertyKeys {
+		var value propVariant
+		if hr := comCall(store, vtableGetValue, uintptr(unsafe.Pointer(&key)), uintptr(unsafe.Pointer(&value))); hr != 0 {
+			return nil, fmt.Errorf("failed to get shell property %s of %q: %w", name, path, syscall.Errno(hr))
+		}
+		var str *uint16
+		hr, _, _ := propVariantToStringAllocProc.Call(uintptr(unsafe.Pointer(&value)), uintptr(unsafe.Pointer(&str)))
+		propVariantClearProc.Call(uintptr(unsafe.Pointer(&value))) //nolint:errcheck
+		if hr != 0 || str == nil {
+			continue
+		}
+		if s := windows.UTF16PtrToString(str); s != "" {
+			properties[name] = s
+		}
+		windows.CoTaskMemFree(unsafe.Pointer(str))
+	}
+	return properties, nil
+}
+
+// comCall calls the method of a COM object by its vtable index. Methods with
+// up to two arguments besides the object are supported.
+func comCall(object *comObject, method int, args ...uintptr) uintptr {
+	all := [3]uintptr{uintptr(unsafe.Pointer(object))}
+	copy(all[1:], args)
+	hr, _, _ := syscall.Syscall(object.vtable[method], uintptr(len(args)+1), all[0], all[1], all[2])
+	return hr
+}
@@ -0,0 +1,211 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// ShimEntry is an application entry (an EXE tag) of a shim database: the
+// fixes applied to an executable identified by its matching files.
+type ShimEntry struct {
+	Name   string
+	App    string
+	Vendor string
+	// Fixes are the names of the shims, layers, patches and flags applied.
+	Fixes []string
+	// Files identify the executable, the one named "*" is the executable
+	// itself.
+	Files []ShimMatchingFile
+}
+
+// ShimMatchingFile is a MATCHING_FILE of a shim database entry. String
+// attributes may contain the `*` wildcards. The binary version bounds are
+// zero if not set, the upper ones are inclusive.
+type ShimMatchingFile struct {
+	Name           string
+	CompanyName    string
+	ProductName    string
+	ProductVersion string
+	FileVersion    string
+
+	BinFileVersion        FileVersion
+	UptoBinFileVersion    FileVersion
+	BinProductVersion     FileVersion
+	UptoBinProductVersion FileVersion
+}
+
+//nolint:gochecknoglobals
+var (
+	// The shim database API of apphelp.dll is not wrapped by x/sys/windows.
+	apphelp                = windows.NewLazySystemDLL("apphelp.dll")
+	sdbOpenDatabaseProc    = apphelp.NewProc("SdbOpenDatabase")
+	sdbCloseDatabaseProc   = apphelp.NewProc("SdbCloseDatabase")
+	sdbGetFirstChildProc   = apphelp.NewProc("SdbGetFirstChild")
+	sdbGetNextChildProc    = apphelp.NewProc("SdbGetNextChild")
+	sdbGetTagFromTagIDProc = apphelp.NewProc("SdbGetTagFromTagID")
+	sdbReadStringTagProc   = apphelp.NewProc("SdbReadStringTag")
+	sdbReadQWORDTagProc    = apphelp.NewProc("SdbReadQWORDTag")
+)
+
+// Shim database tags. Source:
+// https://docs.microsoft.com/en-us/windows/win32/devnotes/application-compatibility-database
+const (
+	sdbDOSPath = 0
+	sdbRoot    = 0
+
+	tagDatabase      = 0x7001
+	tagExe           = 0x7007
+	tagMatchingFile  = 0x7008
+	tagShimRef       = 0x7009
+	tagPatchRef      = 0x700a
+	tagLayer         = 0x700b
+	tagFlagRef       = 0x7014
+	tagName          = 0x6001
+	tagVendor        = 0x6005
+	tagAppName       = 0x6006
+	tagCompanyName   = 0x6009
+	tagProductName   = 0x6010
+	tagProductVer    = 0x6011
+	tagFileVer       = 0x6013
+	tagBinFileVer    = 0x5002
+	tagBinProductVer = 0x5003
+	tagUptoBinProdV  = 0x5006
+	tagUptoBinFileV  = 0x500d
+)
+
+// ReadShimDatabase reads the application entries of a shim database, e.g.
+// `%SystemRoot%\apppatch\sysmain.sdb` or a custom database installed with
+// sdbinst, to see which versions of a product get compatibility fixes.
+func ReadShimDatabase(path string) ([]ShimEntry, error) {
+	if err := sdbOpenDatabaseProc.Find(); err != nil {
+		return nil, fmt.Errorf("shim database API is not available: %w", err)
+	}
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	pdb, _, err := sdbOpenDatabaseProc.Call(uintptr(unsafe.Pointer(pathPtr)), sdbDOSPath)
+	if pdb == 0 {
+		return nil, fmt.Errorf("failed to open shim database %q: %w", path, err)
+	}
+	defer sdbCloseDatabaseProc.Call(pdb) //nolint:errcheck
+
+	db := shimDatabase(pdb)
+	var entries []ShimEntry
+	for _, root := range db.children(sdbRoot) {
+		if db.tag(root) != tagDatabase {
+			continue
+		}
+		for _, exe := range db.children(root) {
+			if db.tag(exe) == tagExe {
+				entries = append(entries, db.entry(exe))
+			}
+		}
+	}
+	return entries, nil
+}
+
+// shimDatabase is a PDB handle.
+type shimDatabase uintptr
+
+func (db shimDatabase) children(parent uintptr) []uintptr {
+	var children []uintptr
+	child, _, _ := sdbGetFirstChildProc.Call(uintptr(db), parent)
+	for child != 0 {
+		children = append(children, child)
+		child, _, _ = sdbGetNextChildProc.Call(uintptr(db), parent, child)
+	}
+	return children
+}
+
+func (db shimDatabase) tag(id uintptr) uint16 {
+	tag, _, _ := sdbGetTagFromTagIDProc.Call(uintptr(db), id)
+	return uint16(tag)
+}
+
+// str reads a STRING or a STRINGREF tag. The API doesn't report the length,
+// so a short buffer is tried first.
+func (db shimDatabase) str(id uintptr) string {
+	for _, size := range []int{256, 32 * 1024} {
+		buf := make([]uint16, size)
+		ok, _, _ := sdbReadStringTagProc.Call(uintptr(db), id, uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+		if ok != 0 {
+			return windows.UTF16ToString(buf)
+		}
+	}
+	return ""
+}
+
+// qword reads a QWORD tag. The zero default is passed as two words, which is
+// the 386 stack layout and an ignored extra argument on amd64; the 386
+// result is returned in EDX:EAX.
+func (db shimDatabase) qword(id uintptr) uint64 {
+	lo, hi, _ := sdbReadQWORDTagProc.Call(uintptr(db), id, 0, 0)
+	if unsafe.Sizeof(uintptr(0)) == 4 {
+		return uint64(hi)<<32 | uint64(uint32(lo))
+	}
+	return uint64(lo)
+}
+
+// childName returns the NAME child of the tag.
+func (db shimDatabase) childName(id uintptr) string {
+	for _, child := range db.children(id) {
+		if db.tag(child) == tagName {
+			return db.str(child)
+		}
+	}
+	return ""
+}
+
+func (db shimDatabase) entry(exe uintptr) ShimEntry {
+	var entry ShimEntry
+	for _, child := range db.children(exe) {
+		switch db.tag(child) {
+		case tagName:
+			entry.Name = db.str(child)
+		case tagAppName:
+			entry.App = db.str(child)
+		case tagVendor:
+			entry.Vendor = db.str(child)
+		case tagShimRef, tagPatchRef, tagLayer, tagFlagRef:
+			if name := db.childName(child); name != "" {
+				entry.Fixes = append(entry.Fixes, name)
+			}
+		case tagMatchingFile:
+			entry.Files = append(entry.Files, db.matchingFile(child))
+		}
+	}
+	return entry
+}
+
+func (db shimDatabase) matchingFile(id uintptr) ShimMatchingFile {
+	var file ShimMatchingFile
+	for _, child := range db.children(id) {
+		switch db.tag(child) {
+		case tagName:
+			file.Name = db.str(child)
+		case tagCompanyName:
+			file.CompanyName = db.str(child)
+		case tagProductName:
+			file.ProductName = db.str(child)
+		case tagProductVer:
+			file.ProductVersion = db.str(child)
+		case tagFileVer:
+			file.FileVersion = db.str(child)
+		case tagBinFileVer:
+			file.BinFileVersion = FileVersionFromUint64(db.qword(child))
+		case tagUptoBinFileV:
+			file.UptoBinFileVersion = FileVersionFromUint64(db.qword(child))
+		case tagBinProductVer:
+			file.BinProductVersion = FileVersionFromUint64(db.qword(child))
+		case tagUptoBinProdV:
+			file.UptoBinProductVersion = FileVersionFromUint64(db.qword(child))
+		}
+	}
+	return file
+}
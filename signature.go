@@ -0,0 +1,159 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// IsSigned reports whether the file has a valid Authenticode signature either
+// embedded or in a catalog installed on the system (like most of the OS
+// binaries). Revocation is not checked. The error is returned for Info values
+// not backed by a file and if the file can't be read.
+func (f Info) IsSigned() (bool, error) {
+	if f.path == "" {
+		return false, errors.New("info is not backed by a file")
+	}
+	ok, err := verifyEmbeddedSignature(f.path)
+	if err != nil || ok {
+		return ok, err
+	}
+	_, ok, err = catalogOf(f.path)
+	return ok, err
+}
+
+//nolint:gochecknoglobals
+var (
+	// The catalog API is not wrapped by x/sys/windows.
+	wintrust                                 = windows.NewLazySystemDLL("wintrust.dll")
+	cryptCATAdminAcquireContextProc          = wintrust.NewProc("CryptCATAdminAcquireContext")
+	cryptCATAdminAcquireContext2Proc         = wintrust.NewProc("CryptCATAdminAcquireContext2")
+	cryptCATAdminCalcHashFromFileHandleProc  = wintrust.NewProc("CryptCATAdminCalcHashFromFileHandle")
+	cryptCATAdminCalcHashFromFileHandle2Proc = wintrust.NewProc("CryptCATAdminCalcHashFromFileHandle2")
+	cryptCATAdminEnumCatalogFromHashProc     = wintrust.NewProc("CryptCATAdminEnumCatalogFromHash")
+	cryptCATAdminReleaseCatalogContextProc   = wintrust.NewProc("CryptCATAdminReleaseCatalogContext")
+	cryptCATAdminReleaseContextProc          = wintrust.NewProc("CryptCATAdminReleaseContext")
+	cryptCATCatalogInfoFromContextProc       = wintrust.NewProc("CryptCATCatalogInfoFromContext")
+)
+
+// verifyEmbeddedSignature verifies the embedded Authenticode signature.
+func verifyEmbeddedSignature(path string) (bool, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return false, err
+	}
+	file := windows.WinTrustFileInfo{FilePath: pathPtr}
+	file.Size = uint32(unsafe.Sizeof(file))
+	data := windows.WinTrustData{
+		UIChoice:                        windows.WTD_UI_NONE,
+		RevocationChecks:                windows.WTD_REVOKE_NONE,
+		UnionChoice:                     windows.WTD_CHOICE_FILE,
+		FileOrCatalogOrBlobOrSgnrOrCert: unsafe.Pointer(&file),
+		StateAction:                     windows.WTD_STATEACTION_VERIFY,
+		ProvFlags:                       windows.WTD_CACHE_ONLY_URL_RETRIEVAL,
+	}
+	data.Size = uint32(unsafe.Sizeof(data))
+	verifyErr := windows.WinVerifyTrustEx(windows.InvalidHWND, &windows.WINTRUST_ACTION_GENERIC_VERIFY_V2, &data)
+	data.StateAction = windows.WTD_STATEACTION_CLOSE
+	windows.WinVerifyTrustEx(windows.InvalidHWND, &windows.WINTRUST_ACTION_GENERIC_VERIFY_V2, &data) //nolint:errcheck
+	return verifyErr == nil, nil
+}
+
+// catalogInfo is CATALOG_INFO structure. Source:
+// https://docs.microsoft.com/en-us/windows/win32/api/mscat/ns-mscat-catalog_info
+type catalogInfo struct {
+	size        uint32
+	catalogFile [windows.MAX_PATH]uint16
+}
+
+// catalogOf returns the path of a catalog installed on the system which lists
+// the file hash. Catalogs are only installed after their signature is
+// verified, so it's not re-verified here.
+func catalogOf(path string) (string, bool, error) {
+	file, err := windows.Open(path, windows.O_RDONLY, 0)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to open %q: %w", path, err)
+	}
+	defer windows.CloseHandle(file) //nolint:errcheck
+
+	var admin uintptr
+	var ret uintptr
+	// SHA256 catalogs need the Windows 8 API, older systems only have SHA1.
+	sha256, _ := windows.UTF16PtrFromString("SHA256")
+	useV2 := cryptCATAdminAcquireContext2Proc.Find() == nil
+	if useV2 {
+		ret, _, err = cryptCATAdminAcquireContext2Proc.Call(uintptr(unsafe.Pointer(&admin)), 0, uintptr(unsafe.Pointer(sha256)), 0, 0)
+	} else {
+		ret, _, err = cryptCATAdminAcquireContextProc.Call(uintptr(unsafe.Pointer(&admin)), 0, 0)
+	}
+	if ret == 0 {
+		return "", false, fmt.Errorf("failed to acquire catalog context: %w", err)
+	}
+	defer cryptCATAdminReleaseContextProc.Call(admin, 0) //nolint:errcheck
+
+	hash := make([]byte, 64)
+	size := uint32(len(hash))
+	if useV2 {
+		ret, _, err = cryptCATAdminCalcHashFromFileHandle2Proc.Call(admin, uintptr(file), uintptr(unsafe.Pointer(&size)), uintptr(unsafe.Pointer(&hash[0])), 0)
+	} else {
+		ret, _, err = cryptCATAdminCalcHashFromFileHandleProc.Call(uintptr(file), uintptr(unsafe.Pointer(&size)), uintptr(unsafe.Pointer(&hash[0])), 0)
+	}
+	if ret == 0 {
+		return "", false, fmt.Errorf("failed to hash %q: %w", path, err)
+	}
+
+	catalog, _, _ := cryptCATAdminEnumCatalogFromHashProc.Call(admin, uintptr(unsafe.Pointer(&hash[0])), uintptr(size), 0, 0)
+	if catalog == 0 {
+		return "", false, nil
+	}
+	defer cryptCATAdminReleaseCatalogContextProc.Call(admin, catalog, 0) //nolint:errcheck
+
+	info := catalogInfo{}
+	info.size = uint32(unsafe.Sizeof(info))
+	ret, _, err = cryptCATCatalogInfoFromContextProc.Call(catalog, uintptr(unsafe.Pointer(&info)), 0)
+	if ret == 0 {
+		return "", false, fmt.Errorf("failed to get catalog info: %w", err)
+	}
+	return windows.UTF16ToString(info.catalogFile[:]), true, nil
+}
+
+//nolint:gochecknoglobals
+var (
+	// SfcIsFileProtected is not wrapped by x/sys/windows.
+	sfc                    = windows.NewLazySystemDLL("sfc.dll")
+	sfcIsFileProtectedProc = sfc.NewProc("SfcIsFileProtected")
+)
+
+// IsOSComponent reports whether the file is an in-box Windows component: it's
+// protected by Windows Resource Protection (SFC) or its hash is listed in a
+// system catalog signed by Microsoft. Third-party drivers and software also
+// install catalogs, these are told apart by the catalog signer. Like
+// IsSigned, the error is returned for Info values not backed by a file.
+func (f Info) IsOSComponent() (bool, error) {
+	if f.path == "" {
+		return false, errors.New("info is not backed by a file")
+	}
+	pathPtr, err := windows.UTF16PtrFromString(f.path)
+	if err != nil {
+		return false, err
+	}
+	if sfcIsFileProtectedProc.Find() == nil {
+		if protected, _, _ := sfcIsFileProtectedProc.Call(0, uintptr(unsafe.Pointer(pathPtr))); protected != 0 {
+			return true, nil
+		}
+	}
+	catalog, ok, err := catalogOf(f.path)
+	if err != nil || !ok {
+		return false, err
+	}
+	_, organization, ok, err := signerNames(catalog)
+	if err != nil || !ok {
+		return false, err
+	}
+	return NormalizeCompanyName(organization) == "microsoft", nil
+}
@@ -0,0 +1,18 @@
+//go:build go1.21
+// +build go1.21
+
+package fileversion
+
+import "log/slog"
+
+// WithLogger makes the package trace its heuristics to l at the debug level:
+// failed windows calls, fallbacks to DefaultLocales and every translation
+// tried while looking up a property. It helps to find out why a property is
+// empty.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *options) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package fileversion
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Source opens files by path as random access readers. Implement it to read
+// files not accessible through the local file system, e.g. fetched by an EDR
+// agent over RPC, and parse them with NewFromSource server-side. Readers
+// implementing io.Closer are closed after reading.
+type Source interface {
+	Open(path string) (io.ReaderAt, error)
+}
+
+// SourceFunc adapts a function to Source.
+type SourceFunc func(path string) (io.ReaderAt, error)
+
+// Open calls fn.
+func (fn SourceFunc) Open(path string) (io.ReaderAt, error) {
+	return fn(path)
+}
+
+// FileSource is the Source of local files.
+//
+//nolint:gochecknoglobals
+var FileSource Source = SourceFunc(func(path string) (io.ReaderAt, error) {
+	return os.Open(path)
+})
+
+// NewFromSource opens the file with the source and reads it like
+// NewFromReader does: the resources and the version information are parsed
+// in pure Go, so it works on any platform. The Info is not backed by a local
+// file, so the methods reading the file itself (IsSigned, Installer and so
+// on) fail on it.
+func NewFromSource(src Source, path string, opts ...Option) (Info, error) {
+	r, err := openSource(src, path)
+	if err != nil {
+		return Info{}, err
+	}
+	defer closeSource(r)
+	info, err := NewFromReader(r, opts...)
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+	return info, nil
+}
+
+// NewAllFromSource is like NewFromSource but reads every version-information
+// resource like NewAll does.
+func NewAllFromSource(src Source, path string, opts ...Option) ([]VersionResource, error) {
+	r, err := openSource(src, path)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSource(r)
+	resources, err := NewAllFromReader(r, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+	return resources, nil
+}
+
+func openSource(src Source, path string) (io.ReaderAt, error) {
+	r, err := src.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %q: %w", path, err)
+	}
+	return r, nil
+}
+
+func closeSource(r io.ReaderAt) {
+	if c, ok := r.(io.Closer); ok {
+		c.Close() //nolint:errcheck
+	}
+}
@@ -0,0 +1,55 @@
+package fileversion_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/bi-zone/go-fileversion"
+	"github.com/bi-zone/go-fileversion/fileversiontest"
+)
+
+func TestNewFromSource(t *testing.T) {
+	english := fileversion.Locale{LangID: 0x0409, CharsetID: fileversion.CSUnicode}
+	resource := fileversiontest.Resource{
+		Fixed: fileversion.FixedFileInfo{FileVersion: fileversion.FileVersion{Major: 1, Minor: 2, Patch: 3, Build: 4}},
+		StringTables: []fileversiontest.StringTable{{Locale: english, Strings: []fileversiontest.String{
+			{Name: "CompanyName", Value: "Acme Corporation"},
+		}}},
+	}
+	images := map[string][]byte{
+		`C:\remote\acme.dll`: fileversiontest.PE(resource, fileversiontest.Resource{Lang: 0x0419}),
+	}
+	src := fileversion.SourceFunc(func(path string) (io.ReaderAt, error) {
+		image, ok := images[path]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return bytes.NewReader(image), nil
+	})
+
+	info, err := fileversion.NewFromSource(src, `C:\remote\acme.dll`)
+	if err != nil {
+		t.Fatalf("NewFromSource() error = %v", err)
+	}
+	if got := info.CompanyName(); got != "Acme Corporation" {
+		t.Errorf("CompanyName() = %q, want %q", got, "Acme Corporation")
+	}
+	if got := info.FixedInfo().FileVersion; got != resource.Fixed.FileVersion {
+		t.Errorf("FileVersion = %v, want %v", got, resource.Fixed.FileVersion)
+	}
+
+	resources, err := fileversion.NewAllFromSource(src, `C:\remote\acme.dll`)
+	if err != nil {
+		t.Fatalf("NewAllFromSource() error = %v", err)
+	}
+	if len(resources) != 2 || resources[1].Lang != 0x0419 {
+		t.Errorf("NewAllFromSource() = %+v, want 2 resources", resources)
+	}
+
+	if _, err := fileversion.NewFromSource(src, `C:\remote\missing.dll`); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("NewFromSource() error = %v, want ErrNotExist", err)
+	}
+}
@@ -0,0 +1,98 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// wellKnownVendors are normalized (see Inventory) vendor names malware
+// commonly impersonates. All their binaries are expected to be signed.
+//
+//nolint:gochecknoglobals
+var wellKnownVendors = map[string]bool{
+	"microsoft":             true,
+	"google":                true,
+	"adobe":                 true,
+	"oracle":                true,
+	"intel":                 true,
+	"nvidia":                true,
+	"apple":                 true,
+	"mozilla":               true,
+	"vmware":                true,
+	"cisco systems":         true,
+	"kaspersky lab":         true,
+	"symantec":              true,
+	"mcafee":                true,
+	"realtek semiconductor": true,
+}
+
+// SuspicionReport flags the tricks malware commonly uses to look like a
+// legitimate binary:
+//   - CompanyName claims a well-known vendor while the file isn't signed;
+//   - OriginalFilename doesn't match the name of the file;
+//   - properties contain zero-width or bidirectional control characters or
+//     mix Latin letters with look-alike letters of other scripts (homoglyphs).
+//
+// The signature is checked only for Info values backed by a file. The report
+// is a heuristic: legitimate files can have findings too.
+func SuspicionReport(info Info) []Finding {
+	var findings []Finding
+	company := info.CompanyName()
+	if wellKnownVendors[NormalizeCompanyName(company)] && info.path != "" {
+		if signed, err := info.IsSigned(); err == nil && !signed {
+			findings = append(findings, Finding{
+				Kind:     FindingUnsignedVendorClaim,
+				Property: PropCompanyName,
+				Message:  fmt.Sprintf("CompanyName %q claims a well-known vendor but the file isn't signed", company),
+			})
+		}
+	}
+	for _, f := range info.Validate() {
+		if f.Kind == FindingFilenameMismatch {
+			findings = append(findings, f)
+		}
+	}
+
+	properties, _ := info.Properties()
+	reported := make(map[string]bool)
+	for _, p := range properties {
+		if reported[p.Name] {
+			continue
+		}
+		if reason := suspiciousCharacters(p.Value); reason != "" {
+			reported[p.Name] = true
+			findings = append(findings, Finding{
+				Kind:     FindingSuspiciousCharacters,
+				Property: PropertyName(p.Name),
+				Message:  fmt.Sprintf("%s %q %s", p.Name, p.Value, reason),
+			})
+		}
+	}
+	return findings
+}
+
+// suspiciousCharacters returns a description of suspicious characters in s or
+// an empty string. Scripts are compared within words, so legitimately
+// multilingual values aren't reported.
+func suspiciousCharacters(s string) string {
+	var latin, lookAlike bool
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Cf, r):
+			return fmt.Sprintf("contains invisible character %U", r)
+		case unicode.Is(unicode.Latin, r):
+			latin = true
+		case unicode.Is(unicode.Cyrillic, r), unicode.Is(unicode.Greek, r), unicode.Is(unicode.Armenian, r):
+			lookAlike = true
+		case !unicode.IsLetter(r):
+			latin, lookAlike = false, false
+		}
+		if latin && lookAlike {
+			return "mixes Latin letters with Cyrillic, Greek or Armenian ones in a word"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// RichEntry is a single record of the Rich header: a tool (product ID and its
+// build) used by the linker and the number of objects it produced.
+type RichEntry struct {
+	ProductID uint16
+	Build     uint16
+	Count     uint32
+}
+
+// ImportSummary describes functions imported from a single DLL.
+type ImportSummary struct {
+	DLL       string
+	Functions []string
+}
+
+// TriageInfo contains data commonly needed by triage tooling besides the
+// version info.
+type TriageInfo struct {
+	PE PEInfo
+	// RichHeader is empty for images linked without MSVC linker.
+	RichHeader []RichEntry
+	// Imports are sorted by DLL name.
+	Imports []ImportSummary
+}
+
+// Triage parses PE headers, the Rich header and the import table of the file
+// Info was created from.
+func (f Info) Triage() (TriageInfo, error) {
+	raw, err := os.Open(f.path)
+	if err != nil {
+		return TriageInfo{}, fmt.Errorf("failed to open %q: %w", f.path, err)
+	}
+	defer raw.Close()
+
+	file, err := pe.NewFile(raw)
+	if err != nil {
+		return TriageInfo{}, fmt.Errorf("failed to parse PE headers of %q: %w", f.path, err)
+	}
+	defer file.Close()
+
+	rich, err := readRichHeader(raw)
+	if err != nil {
+		return TriageInfo{}, fmt.Errorf("failed to read Rich header: %w", err)
+	}
+	imports, err := summarizeImports(file)
+	if err != nil {
+		return TriageInfo{}, fmt.Errorf("failed to read import table: %w", err)
+	}
+	return TriageInfo{
+		PE:         newPEInfo(file),
+		RichHeader: rich,
+		Imports:    imports,
+	}, nil
+}
+
+const (
+	richSignature = 0x68636952 // "Rich"
+	dansSignature = 0x536e6144 // "DanS"
+	// peHeaderOffsetPos is an offset of e_lfanew field of the DOS header.
+	peHeaderOffsetPos = 0x3c
+	// maxDOSStubSize limits the amount of data read looking for the Rich header.
+	maxDOSStubSize = 0x1000
+)
+
+// readRichHeader decodes the undocumented Rich header located between the DOS
+// stub and the PE header.
+//
+// Ref: https://www.ntcore.com/files/richsign.htm
+func readRichHeader(r io.ReaderAt) ([]RichEntry, error) {
+	var lfanew [4]byte
+	if _, err := r.ReadAt(lfanew[:], peHeaderOffsetPos); err != nil {
+		return nil, err
+	}
+	size := binary.LittleEndian.Uint32(lfanew[:])
+	if size > maxDOSStubSize {
+		size = maxDOSStubSize
+	}
+	stub := make([]byte, size)
+	if _, err := r.ReadAt(stub, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	end := -1
+	for i := (len(stub) - 8) &^ 3; i >= 0; i -= 4 {
+		if binary.LittleEndian.Uint32(stub[i:]) == richSignature {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return nil, nil
+	}
+	key := binary.LittleEndian.Uint32(stub[end+4:])
+
+	start := -1
+	for i := end - 4; i >= 0; i -= 4 {
+		if binary.LittleEndian.Uint32(stub[i:])^key == dansSignature {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil, errors.New("rich header has no DanS marker")
+	}
+
+	// DanS is followed by 3 zero padding dwords and then by the entries.
+	var entries []RichEntry
+	for i := start + 16; i+8 <= end; i += 8 {
+		compID := binary.LittleEndian.Uint32(stub[i:]) ^ key
+		count := binary.LittleEndian.Uint32(stub[i+4:]) ^ key
+		entries = append(entries, RichEntry{
+			ProductID: uint16(compID >> 16),
+			Build:     uint16(compID),
+			Count:     count,
+		})
+	}
+	return entries, nil
+}
+
+func summarizeImports(file *pe.File) ([]ImportSummary, error) {
+	symbols, err := file.ImportedSymbols()
+	if err != nil {
+		return nil, err
+	}
+	byDLL := make(map[string][]string)
+	for _, sym := range symbols {
+		// Symbols are formatted as "function:dll".
+		i := strings.LastIndexByte(sym, ':')
+		if i < 0 {
+			continue
+		}
+		dll := strings.ToLower(sym[i+1:])
+		byDLL[dll] = append(byDLL[dll], sym[:i])
+	}
+	summary := make([]ImportSummary, 0, len(byDLL))
+	for dll, functions := range byDLL {
+		summary = append(summary, ImportSummary{DLL: dll, Functions: functions})
+	}
+	sort.Slice(summary, func(i, j int) bool { return summary[i].DLL < summary[j].DLL })
+	return summary, nil
+}
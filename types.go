@@ -0,0 +1,243 @@
+// Package fileversion provides wrapper for querying properties from windows
+// version-information resource.
+//
+// fileversion API is aimed to the easiest way of getting file properties so
+// it ignore most of errors querying properties. We suppose most of the time
+// it will be used as "create with New and just access properties". If you
+// need some guaranties - access the properties manually using GetProperty and
+// GetPropertyWithLocale.
+//
+// For more info about version-information resource look at
+// https://docs.microsoft.com/en-us/windows/win32/menurc/versioninfo-resource
+//
+// New and the other functions reading files by path through the windows API
+// require windows. The resource itself is parsed in pure Go, so Info values
+// read from images with NewFromReader, NewAllFromReader and NewFromSource,
+// the value types (FileVersion, Locale, FixedFileInfo, Property), the errors
+// and Provider work on every platform: a server can parse the files its
+// agents collect, and code depending on Provider can be unit-tested with
+// fileversiontest.Fake on any CI.
+package fileversion
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// FileVersion is a multi-component version. The windows docs call the parts
+// Major.Minor.Build.Revision: Build is the third part and Patch is the fourth
+// one (the revision).
+type FileVersion struct {
+	Major uint16
+	Minor uint16
+	// Patch is the fourth version part.
+	//
+	// Deprecated: the windows docs call it the revision, use Revision.
+	Patch uint16
+	Build uint16
+}
+
+// String returns a string representation of the version.
+//
+// For compatibility it keeps the historical Major.Minor.Patch.Build order,
+// i.e. the revision goes before the build. Use Parts for the windows order.
+func (f FileVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d", f.Major, f.Minor, f.Patch, f.Build)
+}
+
+// Revision returns the fourth version part, stored in Patch.
+func (f FileVersion) Revision() uint16 {
+	return f.Patch
+}
+
+// Parts returns the version parts in the windows docs order: major, minor,
+// build and revision.
+func (f FileVersion) Parts() [4]uint16 {
+	return [4]uint16{f.Major, f.Minor, f.Build, f.Patch}
+}
+
+// Uint64 packs the version as Major<<48 | Minor<<32 | Build<<16 | Revision,
+// the packing used by Windows Installer and VS_FIXEDFILEINFO (the MS and LS
+// halves). Packed versions compare like the versions themselves.
+func (f FileVersion) Uint64() uint64 {
+	return uint64(f.Major)<<48 | uint64(f.Minor)<<32 | uint64(f.Build)<<16 | uint64(f.Patch)
+}
+
+// rawVersion formats a binary version in Major.Minor.Build.Patch order used by
+// the windows properties dialog.
+func rawVersion(v FileVersion) string {
+	return fmt.Sprintf("%d.%d.%d.%d", v.Major, v.Minor, v.Build, v.Patch)
+}
+
+// FileVersionFromUint64 unpacks a version packed by FileVersion.Uint64.
+func FileVersionFromUint64(v uint64) FileVersion {
+	return FileVersion{
+		Major: uint16(v >> 48),
+		Minor: uint16(v >> 32),
+		Build: uint16(v >> 16),
+		Patch: uint16(v),
+	}
+}
+
+// FixedFileInfo contains a "fixed" part of a file information (without any strings).
+//
+// Ref VS_FIXEDFILEINFO:
+// https://docs.microsoft.com/en-us/windows/win32/api/verrsrc/ns-verrsrc-vs_fixedfileinfo
+type FixedFileInfo struct {
+	// Signature is always FixedFileInfoSignature for valid resources.
+	Signature uint32
+	// StrucVersion is a binary version of the structure, the high word is the
+	// major version number and the low word is the minor one.
+	StrucVersion   uint32
+	FileVersion    FileVersion
+	ProductVersion FileVersion
+	FileFlagsMask  uint32
+	FileFlags      uint32
+	FileOs         uint32
+	FileType       uint32
+	FileSubType    uint32
+	FileDateMS     uint32
+	FileDateLS     uint32
+}
+
+// LangID is a Windows language identifier. Could be one of the codes listed in
+// `langID` section of
+// https://docs.microsoft.com/en-us/windows/win32/menurc/versioninfo-resource
+type LangID uint16
+
+// CharsetID is character-set identifier. Could be one of the codes listed in
+// `charsetID` section of
+// https://docs.microsoft.com/en-us/windows/win32/menurc/versioninfo-resource
+type CharsetID uint16
+
+// Locale defines a pair of a language ID and a charsetID. It can be either any
+// combination of predefined LangID and CharsetID or crafted manually suing
+// values from https://docs.microsoft.com/en-us/windows/win32/menurc/versioninfo-resource
+type Locale struct {
+	LangID    LangID
+	CharsetID CharsetID
+}
+
+// The package defines a list of most commonly used LangID and CharsetID
+// constant. More combinations you can find in windows docs or at
+// https://godoc.org/github.com/josephspurrier/goversioninfo#pkg-constants
+const (
+	LangEnglish   = LangID(0x049)
+	LangEnglishUS = LangID(0x0409)
+	LangNeutral   = LangID(0x0000)
+
+	CSAscii   = CharsetID(0x04e4)
+	CSUnicode = CharsetID(0x04B0)
+	CSUnknown = CharsetID(0x0000)
+)
+
+// DefaultLocales is a list of default Locale values. It's used as a fallback
+// in a calls with automatic locales detection.
+//
+//nolint:gochecknoglobals
+var DefaultLocales = []Locale{
+	{
+		LangID:    LangEnglish,
+		CharsetID: CSAscii,
+	},
+	{
+		LangID:    LangEnglish,
+		CharsetID: CSUnicode,
+	},
+	{
+		LangID:    LangEnglish,
+		CharsetID: CSUnknown,
+	},
+}
+
+// FixedFileInfoSignature is the value of VS_FIXEDFILEINFO dwSignature field.
+const FixedFileInfoSignature = 0xFEEF04BD
+
+// rawFixedFileInfo is VS_FIXEDFILEINFO structure. Source:
+// https://docs.microsoft.com/en-us/windows/win32/api/verrsrc/ns-verrsrc-vs_fixedfileinfo
+type rawFixedFileInfo struct {
+	Signature        uint32
+	StrucVersion     uint32
+	FileVersionMS    uint32
+	FileVersionLS    uint32
+	ProductVersionMS uint32
+	ProductVersionLS uint32
+	FileFlagsMask    uint32
+	FileFlags        uint32
+	FileOS           uint32
+	FileType         uint32
+	FileSubtype      uint32
+	FileDateMS       uint32
+	FileDateLS       uint32
+}
+
+func (vsFixedInfo rawFixedFileInfo) toFixedFileInfo() FixedFileInfo {
+	return FixedFileInfo{
+		Signature:    vsFixedInfo.Signature,
+		StrucVersion: vsFixedInfo.StrucVersion,
+		FileVersion: FileVersion{
+			Major: uint16(vsFixedInfo.FileVersionMS >> 16),
+			Minor: uint16(vsFixedInfo.FileVersionMS & 0xffff),
+			Patch: uint16(vsFixedInfo.FileVersionLS & 0xffff),
+			Build: uint16(vsFixedInfo.FileVersionLS >> 16),
+		},
+		ProductVersion: FileVersion{
+			Major: uint16(vsFixedInfo.ProductVersionMS >> 16),
+			Minor: uint16(vsFixedInfo.ProductVersionMS & 0xffff),
+			Patch: uint16(vsFixedInfo.ProductVersionLS & 0xffff),
+			Build: uint16(vsFixedInfo.ProductVersionLS >> 16),
+		},
+		FileFlagsMask: vsFixedInfo.FileFlagsMask,
+		FileFlags:     vsFixedInfo.FileFlags,
+		FileOs:        vsFixedInfo.FileOS,
+		FileType:      vsFixedInfo.FileType,
+		FileSubType:   vsFixedInfo.FileSubtype,
+		FileDateMS:    vsFixedInfo.FileDateMS,
+		FileDateLS:    vsFixedInfo.FileDateLS,
+	}
+}
+
+// fromFixedFileInfo is the inverse of toFixedFileInfo.
+func fromFixedFileInfo(f FixedFileInfo) rawFixedFileInfo {
+	return rawFixedFileInfo{
+		Signature:        f.Signature,
+		StrucVersion:     f.StrucVersion,
+		FileVersionMS:    uint32(f.FileVersion.Major)<<16 | uint32(f.FileVersion.Minor),
+		FileVersionLS:    uint32(f.FileVersion.Build)<<16 | uint32(f.FileVersion.Patch),
+		ProductVersionMS: uint32(f.ProductVersion.Major)<<16 | uint32(f.ProductVersion.Minor),
+		ProductVersionLS: uint32(f.ProductVersion.Build)<<16 | uint32(f.ProductVersion.Patch),
+		FileFlagsMask:    f.FileFlagsMask,
+		FileFlags:        f.FileFlags,
+		FileOS:           f.FileOs,
+		FileType:         f.FileType,
+		FileSubtype:      f.FileSubType,
+		FileDateMS:       f.FileDateMS,
+		FileDateLS:       f.FileDateLS,
+	}
+}
+
+// MarshalBinary encodes the info as the 52-byte little-endian VS_FIXEDFILEINFO
+// structure, exactly as it's stored in the resource. Fields are written as is,
+// a zero Signature is not replaced.
+func (f FixedFileInfo) MarshalBinary() ([]byte, error) {
+	var b bytes.Buffer
+	if err := binary.Write(&b, binary.LittleEndian, fromFixedFileInfo(f)); err != nil {
+		return nil, fmt.Errorf("failed to encode fixed file info: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// UnmarshalBinary decodes VS_FIXEDFILEINFO encoded by MarshalBinary. The data
+// must be exactly 52 bytes long, the signature is not checked.
+func (f *FixedFileInfo) UnmarshalBinary(data []byte) error {
+	var raw rawFixedFileInfo
+	if len(data) != binary.Size(raw) {
+		return fmt.Errorf("%w: fixed file info is %d bytes, want %d", ErrMalformedResource, len(data), binary.Size(raw))
+	}
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &raw); err != nil {
+		return fmt.Errorf("failed to decode fixed file info: %w", err)
+	}
+	*f = raw.toFixedFileInfo()
+	return nil
+}
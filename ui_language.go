@@ -0,0 +1,32 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import "golang.org/x/sys/windows"
+
+//nolint:gochecknoglobals
+var (
+	getUserDefaultUILanguageProc   = kernel32.NewProc("GetUserDefaultUILanguage")
+	getSystemDefaultUILanguageProc = kernel32.NewProc("GetSystemDefaultUILanguage")
+)
+
+// systemLocales returns locales derived from the user and the system UI
+// languages.
+func systemLocales() []Locale {
+	var locales []Locale
+	for _, proc := range []*windows.LazyProc{getUserDefaultUILanguageProc, getSystemDefaultUILanguageProc} {
+		if proc.Find() != nil {
+			continue
+		}
+		lang, _, _ := proc.Call()
+		if lang == 0 {
+			continue
+		}
+		locales = append(locales,
+			Locale{LangID: LangID(lang), CharsetID: CSUnicode},
+			Locale{LangID: LangID(lang), CharsetID: CSAscii},
+		)
+	}
+	return locales
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+// +build !windows
+
+package fileversion
+
+// systemLocales returns no locales: the UI languages of the machine the
+// resource comes from are unknown off Windows, so WithSystemLocalePreference
+// has no effect there.
+func systemLocales() []Locale {
+	return nil
+}
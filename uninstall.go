@@ -0,0 +1,68 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+//nolint:gochecknoglobals
+var uninstallKeys = []string{
+	`Software\Microsoft\Windows\CurrentVersion\Uninstall`,
+	`Software\Wow6432Node\Microsoft\Windows\CurrentVersion\Uninstall`,
+}
+
+// InstalledProducts returns the products of the Uninstall keys of HKLM and
+// HKCU, including the 32-bit (Wow6432Node) view. Entries without DisplayName
+// are hidden by Add/Remove Programs and skipped.
+func InstalledProducts() ([]InstalledProduct, error) {
+	hives := []struct {
+		name string
+		key  registry.Key
+	}{
+		{"HKLM", registry.LOCAL_MACHINE},
+		{"HKCU", registry.CURRENT_USER},
+	}
+	var products []InstalledProduct
+	for _, hive := range hives {
+		for _, path := range uninstallKeys {
+			root, err := openRegistryKey(hive.key, path)
+			if err != nil {
+				continue
+			}
+			names, err := root.ReadSubKeyNames(0)
+			if err != nil {
+				root.Close()
+				return nil, fmt.Errorf("failed to enumerate %s\\%s: %w", hive.name, path, err)
+			}
+			for _, name := range names {
+				if p, ok := readInstalledProduct(root, name); ok {
+					p.Key = hive.name + `\` + path + `\` + name
+					products = append(products, p)
+				}
+			}
+			root.Close()
+		}
+	}
+	return products, nil
+}
+
+func readInstalledProduct(root registry.Key, name string) (InstalledProduct, bool) {
+	key, err := openRegistryKey(root, name)
+	if err != nil {
+		return InstalledProduct{}, false
+	}
+	defer key.Close()
+	var p InstalledProduct
+	p.DisplayName, _, _ = key.GetStringValue("DisplayName")
+	if p.DisplayName == "" {
+		return InstalledProduct{}, false
+	}
+	p.DisplayVersion, _, _ = key.GetStringValue("DisplayVersion")
+	p.Publisher, _, _ = key.GetStringValue("Publisher")
+	p.InstallLocation, _, _ = key.GetStringValue("InstallLocation")
+	return p, true
+}
@@ -0,0 +1,238 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// UsnPosition is a position in the NTFS change journal of a volume.
+type UsnPosition struct {
+	// Volume is the volume root like `C:\`.
+	Volume    string
+	JournalID uint64
+	USN       int64
+}
+
+// UsnChange is a PE file (judged by extension, see HasPEExtension) changed
+// since a journal position.
+type UsnChange struct {
+	Path string
+	// Deleted is set if the last change deleted the file or renamed it away.
+	Deleted bool
+	// Reason is the union of the USN_REASON flags of the file changes.
+	Reason uint32
+}
+
+// Change journal control codes and structures. Source:
+// https://docs.microsoft.com/en-us/windows/win32/api/winioctl/ni-winioctl-fsctl_read_usn_journal
+const (
+	fsctlQueryUsnJournal = 0x000900f4
+	fsctlReadUsnJournal  = 0x000900bb
+
+	usnReasonFileDelete    = 0x00000200
+	usnReasonRenameOldName = 0x00001000
+
+	usnRecordV2Fixed = 60
+	usnReadBuffer    = 64 * 1024
+	fileIDType       = 0
+)
+
+type usnJournalData struct {
+	UsnJournalID    uint64
+	FirstUsn        int64
+	NextUsn         int64
+	LowestValidUsn  int64
+	MaxUsn          int64
+	MaximumSize     uint64
+	AllocationDelta uint64
+}
+
+type readUsnJournalData struct {
+	StartUsn          int64
+	ReasonMask        uint32
+	ReturnOnlyOnClose uint32
+	Timeout           uint64
+	BytesToWaitFor    uint64
+	UsnJournalID      uint64
+}
+
+// fileIDDescriptor is FILE_ID_DESCRIPTOR with the FileId member of the union.
+type fileIDDescriptor struct {
+	Size   uint32
+	Type   uint32
+	FileID uint64
+	_      [8]byte
+}
+
+//nolint:gochecknoglobals
+var openFileByIDProc = kernel32.NewProc("OpenFileById")
+
+// UsnJournalPosition returns the current end of the change journal of the
+// volume holding path. Save it before a full scan and pass it to UsnChanges
+// next time to get the files changed since. Reading the journal requires
+// administrator rights.
+func UsnJournalPosition(path string) (UsnPosition, error) {
+	volume, handle, err := openVolume(path)
+	if err != nil {
+		return UsnPosition{}, err
+	}
+	defer windows.CloseHandle(handle) //nolint:errcheck
+	journal, err := queryUsnJournal(handle, volume)
+	if err != nil {
+		return UsnPosition{}, err
+	}
+	return UsnPosition{Volume: volume, JournalID: journal.UsnJournalID, USN: journal.NextUsn}, nil
+}
+
+// UsnChanges reads the change journal from the position and returns the PE
+// files created, modified, renamed or deleted since, in the order of their
+// last changes, and the position to continue from. If the journal was
+// recreated or has wrapped over the position the error matches
+// ErrUsnJournalReset and the volume must be rescanned fully.
+func UsnChanges(from UsnPosition) ([]UsnChange, UsnPosition, error) {
+	volume, handle, err := openVolume(from.Volume)
+	if err != nil {
+		return nil, UsnPosition{}, err
+	}
+	defer windows.CloseHandle(handle) //nolint:errcheck
+	journal, err := queryUsnJournal(handle, volume)
+	if err != nil {
+		return nil, UsnPosition{}, err
+	}
+	if journal.UsnJournalID != from.JournalID || from.USN < journal.LowestValidUsn {
+		return nil, UsnPosition{}, fmt.Errorf("%w: journal of %s starts at %d", ErrUsnJournalReset, volume, journal.LowestValidUsn)
+	}
+
+	index := make(map[string]int)
+	var changes []UsnChange
+	dirs := make(map[uint64]string)
+	read := readUsnJournalData{StartUsn: from.USN, ReasonMask: 0xffffffff, UsnJournalID: journal.UsnJournalID}
+	buf := make([]byte, usnReadBuffer)
+	for read.StartUsn < journal.NextUsn {
+		var n uint32
+		err := windows.DeviceIoControl(handle, fsctlReadUsnJournal,
+			(*byte)(unsafe.Pointer(&read)), uint32(unsafe.Sizeof(read)), &buf[0], uint32(len(buf)), &n, nil)
+		if errors.Is(err, windows.ERROR_JOURNAL_ENTRY_DELETED) {
+			return nil, UsnPosition{}, fmt.Errorf("%w: %v", ErrUsnJournalReset, err)
+		}
+		if err != nil {
+			return nil, UsnPosition{}, fmt.Errorf("failed to read change journal of %s: %w", volume, err)
+		}
+		if n <= 8 {
+			break
+		}
+		next := int64(binary.LittleEndian.Uint64(buf))
+		for pos := 8; pos+usnRecordV2Fixed <= int(n); {
+			record := buf[pos:n]
+			length := int(binary.LittleEndian.Uint32(record))
+			if length < usnRecordV2Fixed || length > len(record) {
+				break
+			}
+			pos += length
+			// Only the V2 records are requested by READ_USN_JOURNAL_DATA_V0.
+			if binary.LittleEndian.Uint16(record[4:]) != 2 {
+				continue
+			}
+			nameLength := int(binary.LittleEndian.Uint16(record[56:]))
+			nameOffset := int(binary.LittleEndian.Uint16(record[58:]))
+			if nameOffset+nameLength > length {
+				continue
+			}
+			name := decodeUTF16(record[nameOffset : nameOffset+nameLength])
+			if !HasPEExtension(name) {
+				continue
+			}
+			dir, ok := resolveFileID(handle, binary.LittleEndian.Uint64(record[16:]), dirs)
+			if !ok {
+				continue
+			}
+			reason := binary.LittleEndian.Uint32(record[40:])
+			path := filepath.Join(dir, name)
+			key := strings.ToLower(path)
+			i, ok := index[key]
+			if !ok {
+				i = len(changes)
+				index[key] = i
+				changes = append(changes, UsnChange{Path: path})
+			}
+			changes[i].Reason |= reason
+			// Any later record of a deleted path means it's re-created.
+			changes[i].Deleted = reason&(usnReasonFileDelete|usnReasonRenameOldName) != 0
+		}
+		read.StartUsn = next
+	}
+	return changes, UsnPosition{Volume: volume, JournalID: journal.UsnJournalID, USN: read.StartUsn}, nil
+}
+
+// openVolume opens the volume holding path for the journal control codes.
+func openVolume(path string) (string, windows.Handle, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return "", 0, err
+	}
+	buf := make([]uint16, windows.MAX_PATH)
+	if err := windows.GetVolumePathName(pathPtr, &buf[0], uint32(len(buf))); err != nil {
+		return "", 0, fmt.Errorf("failed to get volume of %q: %w", path, err)
+	}
+	volume := windows.UTF16ToString(buf)
+	device, err := windows.UTF16PtrFromString(`\\.\` + strings.TrimSuffix(volume, `\`))
+	if err != nil {
+		return "", 0, err
+	}
+	handle, err := windows.CreateFile(device, windows.GENERIC_READ,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE, nil, windows.OPEN_EXISTING, 0, 0)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open volume %s: %w", volume, err)
+	}
+	return volume, handle, nil
+}
+
+func queryUsnJournal(handle windows.Handle, volume string) (usnJournalData, error) {
+	var journal usnJournalData
+	var n uint32
+	err := windows.DeviceIoControl(handle, fsctlQueryUsnJournal, nil, 0,
+		(*byte)(unsafe.Pointer(&journal)), uint32(unsafe.Sizeof(journal)), &n, nil)
+	if err != nil {
+		return usnJournalData{}, fmt.Errorf("failed to query change journal of %s: %w", volume, err)
+	}
+	return journal, nil
+}
+
+// resolveFileID returns the path of the directory by its file reference
+// number. Deleted directories can't be resolved.
+func resolveFileID(volume windows.Handle, id uint64, cache map[uint64]string) (string, bool) {
+	if path, ok := cache[id]; ok {
+		return path, path != ""
+	}
+	desc := fileIDDescriptor{Size: uint32(unsafe.Sizeof(fileIDDescriptor{})), Type: fileIDType, FileID: id}
+	handle, _, _ := openFileByIDProc.Call(uintptr(volume), uintptr(unsafe.Pointer(&desc)), 0,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE, 0, windows.FILE_FLAG_BACKUP_SEMANTICS)
+	if windows.Handle(handle) == windows.InvalidHandle {
+		cache[id] = ""
+		return "", false
+	}
+	defer windows.CloseHandle(windows.Handle(handle)) //nolint:errcheck
+	buf := make([]uint16, windows.MAX_PATH)
+	for {
+		n, err := windows.GetFinalPathNameByHandle(windows.Handle(handle), &buf[0], uint32(len(buf)), 0)
+		if err != nil {
+			cache[id] = ""
+			return "", false
+		}
+		if int(n) < len(buf) {
+			path := strings.TrimPrefix(windows.UTF16ToString(buf[:n]), `\\?\`)
+			cache[id] = path
+			return path, true
+		}
+		buf = make([]uint16, n)
+	}
+}
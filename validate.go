@@ -0,0 +1,184 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FindingKind is a type of a problem found by Validate or SuspicionReport.
+type FindingKind int
+
+// Kinds of Validate and SuspicionReport findings.
+const (
+	// FindingMissingProperty means a property required by the windows docs
+	// is missing.
+	FindingMissingProperty FindingKind = iota + 1
+	// FindingVersionMismatch means a FileVersion or ProductVersion string
+	// doesn't match the binary version in the fixed info.
+	FindingVersionMismatch
+	// FindingFilenameMismatch means OriginalFilename doesn't match the name
+	// of the file on disk.
+	FindingFilenameMismatch
+	// FindingUnsignedVendorClaim means CompanyName claims a well-known vendor
+	// but the file has no valid signature.
+	FindingUnsignedVendorClaim
+	// FindingSuspiciousCharacters means a property contains invisible
+	// characters or mixes Latin letters with look-alike letters of other
+	// scripts.
+	FindingSuspiciousCharacters
+	// FindingTranslationMismatch means a translation declared in
+	// `\VarFileInfo\Translation` has no string table or a string table is
+	// not declared.
+	FindingTranslationMismatch
+)
+
+// Finding is a single problem found by Validate or SuspicionReport.
+type Finding struct {
+	Kind     FindingKind
+	Property PropertyName
+	Message  string
+}
+
+// VS_FIXEDFILEINFO FileFlags values. Source:
+// https://docs.microsoft.com/en-us/windows/win32/api/verrsrc/ns-verrsrc-vs_fixedfileinfo
+const (
+	vsFFDebug        = 0x00000001
+	vsFFPrerelease   = 0x00000002
+	vsFFPatched      = 0x00000004
+	vsFFPrivateBuild = 0x00000008
+	vsFFSpecialBuild = 0x00000020
+)
+
+// Validate checks the resource for inconsistencies Explorer users commonly
+// stumble on:
+//   - string properties required by the windows docs are missing (PrivateBuild
+//     and SpecialBuild are required only if the corresponding file flags are
+//     set);
+//   - FileVersion and ProductVersion strings don't start with the versions
+//     stored in the fixed info;
+//   - OriginalFilename doesn't match the name of the file;
+//   - declared translations don't match the string tables present.
+//
+// An empty result means no problems were found.
+func (f Info) Validate() []Finding {
+	var findings []Finding
+	fixed := f.FixedInfo()
+
+	required := []PropertyName{
+		PropCompanyName, PropFileDescription, PropFileVersion, PropInternalName,
+		PropOriginalFilename, PropProductName, PropProductVersion,
+	}
+	if fixed.FileFlags&fixed.FileFlagsMask&vsFFPrivateBuild != 0 {
+		required = append(required, PropPrivateBuild)
+	}
+	if fixed.FileFlags&fixed.FileFlagsMask&vsFFSpecialBuild != 0 {
+		required = append(required, PropSpecialBuild)
+	}
+	for _, name := range required {
+		if _, err := f.GetProperty(string(name)); err != nil {
+			findings = append(findings, Finding{
+				Kind:     FindingMissingProperty,
+				Property: name,
+				Message:  fmt.Sprintf("required property %s is missing", name),
+			})
+		}
+	}
+
+	versions := []struct {
+		name  PropertyName
+		fixed FileVersion
+	}{
+		{PropFileVersion, fixed.FileVersion},
+		{PropProductVersion, fixed.ProductVersion},
+	}
+	for _, v := range versions {
+		s, err := f.GetProperty(string(v.name))
+		if err != nil {
+			continue
+		}
+		if parsed, ok := parseVersionPrefix(s); !ok || parsed != v.fixed {
+			findings = append(findings, Finding{
+				Kind:     FindingVersionMismatch,
+				Property: v.name,
+				Message:  fmt.Sprintf("%s %q doesn't match the fixed version %s", v.name, s, rawVersion(v.fixed)),
+			})
+		}
+	}
+
+	if original, err := f.GetProperty(string(PropOriginalFilename)); err == nil && f.path != "" {
+		if !strings.EqualFold(strings.TrimSpace(original), filepath.Base(f.path)) {
+			findings = append(findings, Finding{
+				Kind:     FindingFilenameMismatch,
+				Property: PropOriginalFilename,
+				Message:  fmt.Sprintf("OriginalFilename %q doesn't match the file name %q", original, filepath.Base(f.path)),
+			})
+		}
+	}
+	return append(findings, f.translationFindings()...)
+}
+
+// translationFindings compares Translations with ActualStringTables.
+func (f Info) translationFindings() []Finding {
+	declared, err := f.Translations()
+	if err != nil {
+		return nil
+	}
+	actual, err := f.ActualStringTables()
+	if err != nil {
+		return nil
+	}
+	var findings []Finding
+	for _, l := range declared {
+		if !containsLocale(actual, l) {
+			findings = append(findings, Finding{
+				Kind:    FindingTranslationMismatch,
+				Message: fmt.Sprintf("declared translation %s has no string table", l),
+			})
+		}
+	}
+	for _, l := range actual {
+		if !containsLocale(declared, l) {
+			findings = append(findings, Finding{
+				Kind:    FindingTranslationMismatch,
+				Message: fmt.Sprintf("string table %s is not declared in translations", l),
+			})
+		}
+	}
+	return findings
+}
+
+func containsLocale(locales []Locale, l Locale) bool {
+	for _, v := range locales {
+		if v == l {
+			return true
+		}
+	}
+	return false
+}
+
+// parseVersionPrefix parses leading 1 to 4 numeric version parts of a version
+// string like "10.0.19041.1 (WinBuild.160101.0800)" or "1, 2, 3, 4". The
+// third part is stored to Build and the fourth one to Patch, matching FixedInfo.
+func parseVersionPrefix(s string) (FileVersion, bool) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '.' || r == ',' || r == ' '
+	})
+	var parts [4]uint16
+	n := 0
+	for ; n < len(fields) && n < len(parts); n++ {
+		v, err := strconv.ParseUint(fields[n], 10, 16)
+		if err != nil {
+			break
+		}
+		parts[n] = uint16(v)
+	}
+	if n == 0 {
+		return FileVersion{}, false
+	}
+	return FileVersion{Major: parts[0], Minor: parts[1], Build: parts[2], Patch: parts[3]}, true
+}
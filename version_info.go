@@ -1,268 +1,230 @@
-// Package fileversion provides wrapper for querying properties from windows
-// version-information resource.
-//
-// fileversion API is aimed to the easiest way of getting file properties so
-// it ignore most of errors querying properties. We suppose most of the time
-// it will be used as "create with New and just access properties". If you
-// need some guaranties - access the properties manually using GetProperty and
-// GetPropertyWithLocale.
-//
-// For more info about version-information resource look at
-// https://docs.microsoft.com/en-us/windows/win32/menurc/versioninfo-resource
 package fileversion
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
-	"syscall"
-	"unsafe"
-
-	"golang.org/x/xerrors"
+	"strings"
 )
 
-// FileVersion is a multi-component version.
-type FileVersion struct {
-	Major uint16
-	Minor uint16
-	Patch uint16
-	Build uint16
-}
-
-// String returns a string representation of the version.
-func (f FileVersion) String() string {
-	return fmt.Sprintf("%d.%d.%d.%d", f.Major, f.Minor, f.Patch, f.Build)
-}
-
-// FixedFileInfo contains a "fixed" part of a file information (without any strings).
-//
-// Ref VS_FIXEDFILEINFO:
-// https://docs.microsoft.com/en-us/windows/win32/api/verrsrc/ns-verrsrc-vs_fixedfileinfo
-type FixedFileInfo struct {
-	FileVersion    FileVersion
-	ProductVersion FileVersion
-	FileFlagsMask  uint32
-	FileFlags      uint32
-	FileOs         uint32
-	FileType       uint32
-	FileSubType    uint32
-	FileDateMS     uint32
-	FileDateLS     uint32
-}
-
-// LangID is a Windows language identifier. Could be one of the codes listed in
-// `langID` section of
-// https://docs.microsoft.com/en-us/windows/win32/menurc/versioninfo-resource
-type LangID uint16
-
-// CharsetID is character-set identifier. Could be one of the codes listed in
-// `charsetID` section of
-// https://docs.microsoft.com/en-us/windows/win32/menurc/versioninfo-resource
-type CharsetID uint16
-
-// Locale defines a pair of a language ID and a charsetID. It can be either any
-// combination of predefined LangID and CharsetID or crafted manually suing
-// values from https://docs.microsoft.com/en-us/windows/win32/menurc/versioninfo-resource
-type Locale struct {
-	LangID    LangID
-	CharsetID CharsetID
-}
-
-// The package defines a list of most commonly used LangID and CharsetID
-// constant. More combinations you can find in windows docs or at
-// https://godoc.org/github.com/josephspurrier/goversioninfo#pkg-constants
-const (
-	LangEnglish = LangID(0x049)
-
-	CSAscii   = CharsetID(0x04e4)
-	CSUnicode = CharsetID(0x04B0)
-	CSUnknown = CharsetID(0x0000)
-)
-
-// DefaultLocales is a list of default Locale values. It's used as a fallback
-// in a calls with automatic locales detection.
-//
-//nolint:gochecknoglobals
-var DefaultLocales = []Locale{
-	{
-		LangID:    LangEnglish,
-		CharsetID: CSAscii,
-	},
-	{
-		LangID:    LangEnglish,
-		CharsetID: CSUnicode,
-	},
-	{
-		LangID:    LangEnglish,
-		CharsetID: CSUnknown,
-	},
-}
-
-// Info contains a transparent windows object, which is being used for getting
-// file version resource properties.
+// Info contains a version-information resource and gives access to its
+// string properties and the fixed part. The values are looked up in pure Go
+// on the parsed resource, so the Info values created by NewFromReader and
+// NewFromSource work on any platform.
 //
 // Locales is a list of locales defined for the object. For the Info created
-// using New it's queried from `\VarFileInfo\Translation`, for ones created
-// using NewWithLocale it's just the given locale.
+// using New it's queried from `\VarFileInfo\Translation` and keeps the resource
+// order, for ones created using NewWithLocale it's just the given locale.
 //
 // A translation for the any property value is automatically chosen from Locales
 // and then from fileversion.DefaultLocales prior to to the list order. Use
 // GetPropertyWithLocale for deterministic selection of the property translation.
 type Info struct {
-	Locales []Locale
-	data    []byte
+	Locales  []Locale
+	path     string
+	data     []byte
+	opts     options
+	compact  *compactInfo
+	identity *FileIdentity
+	tree     *versionTree
+	// resolved are the locales having string tables, see WithEagerLocales.
+	resolved []Locale
+	// defaultLocales is set if DefaultLocales were substituted for missing
+	// translations.
+	defaultLocales bool
 }
 
-// New creates an Info instance.
-//
-// It queries a list of translations from the version-information resource and
-// uses them as preferred translations for string properties.
-func New(path string) (Info, error) {
-	info, err := newWithoutLocale(path)
-	if err != nil {
-		return Info{}, xerrors.Errorf("failed to get VersionInfo: %w", err)
-	}
+var _ Provider = Info{}
 
-	if locales, err := info.getLocales(); err == nil {
-		info.Locales = locales
+// versionTree is the parsed resource shared by the copies of an Info, so the
+// lookups don't parse the resource again.
+type versionTree struct {
+	root versionBlock
+	err  error
+}
+
+// newInfo creates an Info from the raw resource data parsing it once.
+func newInfo(path string, data []byte) Info {
+	root, err := parseRootBlock(data)
+	return Info{path: path, data: data, tree: &versionTree{root: root, err: err}}
+}
+
+// initLocales fills the locales of a new Info from the resource translations.
+func (f *Info) initLocales(o options) {
+	f.opts = o
+	if locales, err := f.getLocales(); err == nil {
+		f.Locales = locales
+	} else if o.noFallback {
+		f.debug("no translations declared", "path", f.path, "error", err)
 	} else {
-		info.Locales = DefaultLocales
+		f.debug("no translations declared, using default locales", "path", f.path, "error", err)
+		f.Locales = DefaultLocales
+		f.defaultLocales = true
+	}
+	if o.systemLocalePreference {
+		f.Locales = mergeLocales(systemLocales(), f.Locales)
 	}
+	f.resolveLocales()
+}
 
-	return info, nil
+// resolveLocales probes the string tables for WithEagerLocales.
+func (f *Info) resolveLocales() {
+	f.resolved = nil
+	if !f.opts.eagerLocales {
+		return
+	}
+	f.resolved = []Locale{}
+	for _, l := range f.localeResolver().Candidates(f.Locales, "") {
+		if f.hasStringTable(l) {
+			f.resolved = append(f.resolved, l)
+		}
+	}
+	f.debug("string tables resolved", "path", f.path, "locales", f.resolved)
 }
 
-// NewWithLocale creates an Info instance with a given locale. All the string
-// properties translations will be firstly queried with the given locale.
-//
-// See GetPropertyWithLocale for exact properties querying.
-func NewWithLocale(path string, locale Locale) (Info, error) {
-	info, err := newWithoutLocale(path)
+// hasStringTable reports whether the resource has a string table for the
+// locale.
+func (f Info) hasStringTable(locale Locale) bool {
+	if f.compact != nil {
+		return f.compact.tables[locale]
+	}
+	root, err := f.rootBlock()
 	if err != nil {
-		return Info{}, xerrors.Errorf("failed to get VersionInfo: %w", err)
+		return false
 	}
-	info.Locales = []Locale{locale}
-	return info, nil
+	_, ok := stringTable(root, locale)
+	return ok
+}
+
+// WithLocale returns a view of the Info preferring the given locale, as if it
+// was created with NewWithLocale. Convenience getters of the view return the
+// locale translation when it exists, the original Info is not changed.
+func (f Info) WithLocale(locale Locale) Info {
+	f.Locales = []Locale{locale}
+	f.defaultLocales = false
+	if f.opts.systemLocalePreference {
+		f.Locales = mergeLocales(f.Locales, systemLocales())
+	}
+	f.resolveLocales()
+	return f
 }
 
 // CompanyName returns CompanyName property.
 func (f Info) CompanyName() string {
-	p, _ := f.GetProperty("CompanyName")
+	p, _ := f.GetProperty(string(PropCompanyName))
 	return p
 }
 
 // FileDescription returns FileDescription property.
 func (f Info) FileDescription() string {
-	p, _ := f.GetProperty("FileDescription")
+	p, _ := f.GetProperty(string(PropFileDescription))
 	return p
 }
 
 // FileVersion returns FileVersion property.
 func (f Info) FileVersion() string {
-	p, _ := f.GetProperty("FileVersion")
+	p, _ := f.GetProperty(string(PropFileVersion))
 	return p
 }
 
 // InternalName returns InternalName property.
 func (f Info) InternalName() string {
-	p, _ := f.GetProperty("InternalName")
+	p, _ := f.GetProperty(string(PropInternalName))
 	return p
 }
 
 // LegalCopyright returns LegalCopyright property.
 func (f Info) LegalCopyright() string {
-	p, _ := f.GetProperty("LegalCopyright")
+	p, _ := f.GetProperty(string(PropLegalCopyright))
 	return p
 }
 
 // OriginalFilename returns OriginalFilename property.
 func (f Info) OriginalFilename() string {
-	p, _ := f.GetProperty("OriginalFilename")
+	p, _ := f.GetProperty(string(PropOriginalFilename))
 	return p
 }
 
 // ProductName returns ProductName property.
 func (f Info) ProductName() string {
-	p, _ := f.GetProperty("ProductName")
+	p, _ := f.GetProperty(string(PropProductName))
 	return p
 }
 
 // ProductVersion returns ProductVersion property.
 func (f Info) ProductVersion() string {
-	p, _ := f.GetProperty("ProductVersion")
+	p, _ := f.GetProperty(string(PropProductVersion))
 	return p
 }
 
 // Comments returns Comments property.
 func (f Info) Comments() string {
-	p, _ := f.GetProperty("Comments")
+	p, _ := f.GetProperty(string(PropComments))
 	return p
 }
 
 // LegalTrademarks returns LegalTrademarks property.
 func (f Info) LegalTrademarks() string {
-	p, _ := f.GetProperty("LegalTrademarks")
+	p, _ := f.GetProperty(string(PropLegalTrademarks))
 	return p
 }
 
 // PrivateBuild returns PrivateBuild property.
 func (f Info) PrivateBuild() string {
-	p, _ := f.GetProperty("PrivateBuild")
+	p, _ := f.GetProperty(string(PropPrivateBuild))
 	return p
 }
 
 // SpecialBuild returns SpecialBuild property.
 func (f Info) SpecialBuild() string {
-	p, _ := f.GetProperty("SpecialBuild")
+	p, _ := f.GetProperty(string(PropSpecialBuild))
 	return p
 }
 
 // FixedInfo returns a fixed (non-string) part of the file version-information
 // resource. Contains file and product versions.
 //
+// FixedInfo returns a zero FixedFileInfo if the fixed part is missing or
+// malformed, use FixedInfoE to distinguish these cases.
+//
 // Ref: https://helloacm.com/c-function-to-get-file-version-using-win32-api-ansi-and-unicode-version/
 func (f Info) FixedInfo() FixedFileInfo {
-	data, err := f.verQueryValue(`\`, false)
+	info, err := f.FixedInfoE()
 	if err != nil {
 		return FixedFileInfo{}
 	}
-	// source:
-	// https://docs.microsoft.com/en-us/windows/win32/api/verrsrc/ns-verrsrc-vs_fixedfileinfo
-	type rawFixedFileInfo struct {
-		Signature        uint32
-		StrucVersion     uint32
-		FileVersionMS    uint32
-		FileVersionLS    uint32
-		ProductVersionMS uint32
-		ProductVersionLS uint32
-		FileFlagsMask    uint32
-		FileFlags        uint32
-		FileOS           uint32
-		FileType         uint32
-		FileSubtype      uint32
-		FileDateMS       uint32
-		FileDateLS       uint32
-	}
-	vsFixedInfo := *((*rawFixedFileInfo)(unsafe.Pointer(&data[0])))
-	return FixedFileInfo{
-		FileVersion: FileVersion{
-			Major: uint16(vsFixedInfo.FileVersionMS >> 16),
-			Minor: uint16(vsFixedInfo.FileVersionMS & 0xffff),
-			Patch: uint16(vsFixedInfo.FileVersionLS & 0xffff),
-			Build: uint16(vsFixedInfo.FileVersionLS >> 16),
-		},
-		ProductVersion: FileVersion{
-			Major: uint16(vsFixedInfo.ProductVersionMS >> 16),
-			Minor: uint16(vsFixedInfo.ProductVersionMS & 0xffff),
-			Patch: uint16(vsFixedInfo.ProductVersionLS & 0xffff),
-			Build: uint16(vsFixedInfo.ProductVersionLS >> 16),
-		},
-		FileFlagsMask: vsFixedInfo.FileFlagsMask,
-		FileFlags:     vsFixedInfo.FileFlags,
-		FileOs:        vsFixedInfo.FileOS,
-		FileType:      vsFixedInfo.FileType,
-		FileSubType:   vsFixedInfo.FileSubtype,
-		FileDateMS:    vsFixedInfo.FileDateMS,
-		FileDateLS:    vsFixedInfo.FileDateLS,
+	return info
+}
+
+// FixedInfoE is like FixedInfo but returns an error if the fixed part is
+// missing, truncated or has wrong signature. The error matches ErrNoFixedInfo
+// if the resource has no fixed part at all, so it can be told apart from a
+// legitimate all-zero one.
+func (f Info) FixedInfoE() (FixedFileInfo, error) {
+	if f.compact != nil {
+		return f.compact.fixed, f.compact.fixedErr
+	}
+	root, err := f.rootBlock()
+	if err != nil {
+		return FixedFileInfo{}, fmt.Errorf("failed to query fixed file info: %w", err)
+	}
+	// The value of the VS_VERSIONINFO block itself, the `\` sub-block.
+	data := root.value
+	if len(data) == 0 {
+		return FixedFileInfo{}, ErrNoFixedInfo
+	}
+	var vsFixedInfo rawFixedFileInfo
+	if len(data) < binary.Size(vsFixedInfo) {
+		return FixedFileInfo{}, fmt.Errorf("%w: fixed file info is truncated: %d bytes", ErrMalformedResource, len(data))
 	}
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &vsFixedInfo); err != nil {
+		return FixedFileInfo{}, fmt.Errorf("failed to decode fixed file info: %w", err)
+	}
+	if vsFixedInfo.Signature != FixedFileInfoSignature {
+		return FixedFileInfo{}, fmt.Errorf("%w: invalid fixed file info signature %#08x", ErrMalformedResource, vsFixedInfo.Signature)
+	}
+	return vsFixedInfo.toFixedFileInfo(), nil
 }
 
 // GetProperty queries a string-property from version-information resource.
@@ -271,24 +233,46 @@ func (f Info) FixedInfo() FixedFileInfo {
 // translations. GetProperty does its best trying to find an existing
 // translation: it returns a first existing translation for any of .Locales
 // and if failed tries to query it for locales from fileversion.DefaultLocales.
+// The order of tried locales can be changed with WithLocaleResolver and the
+// fallback can be disabled with WithNoFallback.
 func (f Info) GetProperty(propertyName string) (string, error) {
-	for _, id := range f.Locales {
-		property, err := f.GetPropertyWithLocale(propertyName, id)
-		if err == nil {
-			return property, nil
+	property, _, err := f.GetPropertyEx(propertyName)
+	return property, err
+}
+
+// GetPropertyEx is like GetProperty but also returns the locale of the
+// translation it has chosen, e.g. to record where an audited value came from.
+func (f Info) GetPropertyEx(propertyName string) (string, Locale, error) {
+	candidates := f.resolved
+	if candidates == nil {
+		candidates = f.localeResolver().Candidates(f.Locales, propertyName)
+	}
+	for _, id := range candidates {
+		if property, ok := f.queryString(id, propertyName); ok {
+			f.debug("property found", "path", f.path, "property", propertyName, "locale", id)
+			return f.normalize(property), id, nil
 		}
+		f.debug("property translation not found", "path", f.path, "property", propertyName, "locale", id)
 	}
-	// Some dlls might not contain correct codepage information. In this case we will fail during lookup.
-	// Explorer will take a few shots in dark by trying `defaultPageIDs`.
-	// Explorer also randomly guess 041D04B0=Swedish+CP_UNICODE and 040704B0=German+CP_UNICODE) sometimes.
-	// We will try to simulate similar behavior here.
-	for _, id := range DefaultLocales {
-		property, err := f.GetPropertyWithLocale(propertyName, id)
-		if err == nil {
-			return property, nil
+	if f.opts.looseKeys {
+		if property, id, ok := f.looseQueryValue(candidates, propertyName); ok {
+			f.debug("property found by a loose key", "path", f.path, "property", propertyName, "locale", id)
+			return f.normalize(property), id, nil
 		}
 	}
-	return "", xerrors.Errorf("failed to get property %q", propertyName)
+	return "", Locale{}, fmt.Errorf("failed to get property %q: %w", propertyName, ErrPropertyNotFound)
+}
+
+func (f Info) localeResolver() LocaleResolver {
+	if f.opts.resolver == nil {
+		return DefaultLocaleResolver
+	}
+	return f.opts.resolver
+}
+
+// debug writes a debug message to the logger given in WithLogger.
+func (f Info) debug(msg string, args ...interface{}) {
+	f.opts.debug(msg, args...)
 }
 
 // GetPropertyWithLocale returns string-property with user-defined locale. It's
@@ -296,113 +280,115 @@ func (f Info) GetProperty(propertyName string) (string, error) {
 // methods do heuristics in translation choosing.
 //
 // See Locale, LangID and CharsetID docs for more info about locales.
+//
+// On failure the returned error wraps *Error with the queried sub-block path.
+// It matches ErrBadLocale if the resource has no
+// string table for the locale at all and ErrPropertyNotFound if the table
+// exists but has no such property.
 func (f Info) GetPropertyWithLocale(propertyName string, locale Locale) (string, error) {
-	property, err := f.verQueryValueString(locale, propertyName)
-	if err != nil {
-		return "", xerrors.Errorf("failed to get property %q with locale %+v", propertyName, locale)
+	if property, ok := f.queryString(locale, propertyName); ok {
+		return f.normalize(property), nil
+	}
+	if f.opts.looseKeys {
+		if property, _, ok := f.looseQueryValue([]Locale{locale}, propertyName); ok {
+			return f.normalize(property), nil
+		}
 	}
-	return property, nil
+	kind := ErrPropertyNotFound
+	if !f.hasStringTable(locale) {
+		kind = ErrBadLocale
+	}
+	e := &Error{Op: "VerQueryValue", Path: f.path, SubBlock: stringTablePath(locale) + `\` + propertyName, Kind: kind}
+	return "", fmt.Errorf("failed to get property %q with locale %+v: %w", propertyName, locale, e)
 }
 
-//nolint:gochecknoglobals
-var uint16Size = int(unsafe.Sizeof(uint16(0)))
-
-//nolint:gochecknoglobals
-var (
-	version                    = syscall.NewLazyDLL("version.dll")
-	getFileVersionInfoSizeProc = version.NewProc("GetFileVersionInfoSizeW")
-	getFileVersionInfoProc     = version.NewProc("GetFileVersionInfoW")
-	verQueryValueProc          = version.NewProc("VerQueryValueW")
-)
-
-// verQueryValueString returns property with type UTF16.
-func (f Info) verQueryValueString(locale Locale, property string) (string, error) {
-	localeStr := fmt.Sprintf("%04x%04x", locale.LangID, locale.CharsetID)
-	data, err := f.verQueryValue(`\StringFileInfo\`+localeStr+`\`+property, true)
-	if err != nil || len(data) == 0 {
-		return "", err
+// looseQueryValue finds the property in the string tables of the locales
+// comparing the keys as WithLooseKeys describes.
+func (f Info) looseQueryValue(locales []Locale, propertyName string) (string, Locale, bool) {
+	properties, err := f.Properties()
+	if err != nil {
+		return "", Locale{}, false
 	}
-	n := len(data) / uint16Size
-	u16 := (*[1 << 28]uint16)(unsafe.Pointer(&data[0]))[:n:n]
-	return syscall.UTF16ToString(u16), err
+	canonical := CanonicalPropertyName(propertyName)
+	for _, locale := range locales {
+		for _, p := range properties {
+			if p.Locale == locale &&
+				(CanonicalPropertyName(p.Name) == canonical || strings.EqualFold(p.Name, propertyName)) {
+				return p.Value, locale, true
+			}
+		}
+	}
+	return "", Locale{}, false
 }
 
-// verQueryValue returns property data.
-func (f Info) verQueryValue(property string, isUTF16String bool) ([]byte, error) {
-	var offset uintptr
-	var length uint
-	blockStart := uintptr(unsafe.Pointer(&f.data[0]))
-	propertyUTF16Ptr, err := syscall.UTF16PtrFromString(property)
+// queryString returns the value of the property in the string table of the
+// locale. It's the hot path of all the property getters, so the parsed tree
+// is walked without building the sub-block path and no errors are
+// constructed: ok is false if the property is missing.
+func (f Info) queryString(locale Locale, property string) (value string, ok bool) {
+	if f.compact != nil {
+		value, ok = f.compact.values[PropertyKey{Locale: locale, Name: property}]
+		return value, ok
+	}
+	root, err := f.rootBlock()
 	if err != nil {
-		return nil, err
-	}
-	ret, _, err := verQueryValueProc.Call(
-		blockStart,
-		uintptr(unsafe.Pointer(propertyUTF16Ptr)),
-		uintptr(unsafe.Pointer(&offset)),
-		uintptr(unsafe.Pointer(&length)),
-	)
-	if ret == 0 {
-		return nil, err
-	}
-	// We need calculate indexes of needed data in `f.data` memory.
-	// `end` depends on length, which can be represent in characters or in bytes
-	// source: `puLen` parameter in
-	// https://docs.microsoft.com/en-us/windows/win32/api/winver/nf-winver-verqueryvaluew
-	start := int(offset) - int(blockStart)
-	var end int
-	if isUTF16String {
-		end = start + uint16Size*int(length) // length represents in characters count in string
-	} else {
-		end = start + int(length)
+		return "", false
 	}
-	if start < 0 || end > len(f.data) {
-		return nil, xerrors.New("index out of range")
+	table, ok := stringTable(root, locale)
+	if !ok {
+		return "", false
 	}
-	return f.data[start:end], nil
+	s, ok := table.child(property)
+	if !ok {
+		return "", false
+	}
+	return s.text(), true
 }
 
-func newWithoutLocale(path string) (Info, error) {
-	pathPtr, err := syscall.UTF16PtrFromString(path)
-	if err != nil {
-		return Info{}, xerrors.Errorf("failed to convert image path to utf16: %w", err)
-	}
-	size, _, err := getFileVersionInfoSizeProc.Call(
-		uintptr(unsafe.Pointer(pathPtr)),
-		0,
-	)
-	if size == 0 {
-		return Info{}, xerrors.Errorf("failed to get memory size for VersionInfo slice: %w", err)
-	}
-	info := make([]byte, size)
-	ret, _, err := getFileVersionInfoProc.Call(
-		uintptr(unsafe.Pointer(pathPtr)),
-		0,
-		uintptr(len(info)),
-		uintptr(unsafe.Pointer(&info[0])),
-	)
-	if ret == 0 {
-		return Info{}, xerrors.Errorf("failed to get VersionInfo from windows: %w", err)
-	}
-
-	vi := Info{data: info}
-	return vi, nil
+// stringTablePath returns a sub-block path of the string table for the locale.
+func stringTablePath(locale Locale) string {
+	return `\StringFileInfo\` + locale.String()
 }
 
 // getLocales tries to get `Translation` property from VersionInfo data.
 func (f Info) getLocales() ([]Locale, error) {
-	data, err := f.verQueryValue(`\VarFileInfo\Translation`, false)
-	if err != nil || len(data) == 0 {
-		return nil, xerrors.Errorf("failed to get Translation property from a windows object: %w", err)
+	root, err := f.rootBlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Translation value: %w", err)
+	}
+	varFileInfo, _ := root.child("VarFileInfo")
+	translation, _ := varFileInfo.child("Translation")
+	data := translation.value
+	if len(data) == 0 {
+		return nil, errors.New("failed to get Translation value: no translations declared")
 	}
 
-	if len(data)%int(unsafe.Sizeof(Locale{})) != 0 {
-		return nil, xerrors.New("get wrong locales len in a windows object")
+	const localeSize = 4
+	if len(data)%localeSize != 0 {
+		return nil, fmt.Errorf("%w: wrong Translation length %d", ErrMalformedResource, len(data))
 	}
-	n := len(data) / int(unsafe.Sizeof(Locale{}))
-	if n == 0 {
-		return nil, xerrors.New("get empty locales array in a windows object")
+	n := len(data) / localeSize
+	// The locales are decoded to a new slice, so they don't keep the resource
+	// data alive.
+	locales := make([]Locale, n)
+	for i := range locales {
+		locales[i] = Locale{
+			LangID:    LangID(binary.LittleEndian.Uint16(data[i*localeSize:])),
+			CharsetID: CharsetID(binary.LittleEndian.Uint16(data[i*localeSize+2:])),
+		}
 	}
-	locales := (*[1 << 28]Locale)(unsafe.Pointer(&data[0]))[:n:n]
 	return locales, nil
 }
+
+// Translations returns the translations declared in `\VarFileInfo\Translation`
+// in the order they are stored in the resource. Unlike Locales it doesn't
+// contain fallback or system locales.
+func (f Info) Translations() ([]Locale, error) {
+	if f.compact != nil {
+		if f.compact.translationsErr != nil {
+			return nil, f.compact.translationsErr
+		}
+		return append([]Locale(nil), f.compact.translations...), nil
+	}
+	return f.getLocales()
+}
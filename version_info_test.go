@@ -0,0 +1,38 @@
+package fileversion_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bi-zone/go-fileversion"
+	"github.com/bi-zone/go-fileversion/fileversiontest"
+)
+
+func BenchmarkGetProperty(b *testing.B) {
+	russian := fileversion.Locale{LangID: 0x0419, CharsetID: fileversion.CSUnicode}
+	image := fileversiontest.PE(fileversiontest.Resource{
+		StringTables: []fileversiontest.StringTable{{Locale: russian, Strings: []fileversiontest.String{
+			{Name: "CompanyName", Value: "Microsoft Corporation"},
+			{Name: "ProductName", Value: "Операционная система Microsoft® Windows®"},
+		}}},
+	})
+	info, err := fileversion.NewFromReader(bytes.NewReader(image))
+	if err != nil {
+		b.Fatalf("NewFromReader() error = %v", err)
+	}
+	for _, bm := range []struct {
+		name     string
+		property string
+	}{
+		{"Declared", "CompanyName"},
+		{"NonASCII", "ProductName"},
+		{"Missing", "Comments"},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				info.GetProperty(bm.property) //nolint:errcheck
+			}
+		})
+	}
+}
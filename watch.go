@@ -0,0 +1,179 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/sys/windows"
+)
+
+// ReadDirectoryChangesW parameters. Source:
+// https://docs.microsoft.com/en-us/windows/win32/api/winbase/nf-winbase-readdirectorychangesw
+const (
+	fileListDirectory = 0x0001
+
+	fileNotifyChangeFileName   = 0x00000001
+	fileNotifyChangeSize       = 0x00000008
+	fileNotifyChangeLastWrite  = 0x00000010
+	fileNotifyChangeCreation   = 0x00000040
+	watchNotifyFilter          = fileNotifyChangeFileName | fileNotifyChangeSize | fileNotifyChangeLastWrite | fileNotifyChangeCreation
+	watchBufferSize            = 64 * 1024
+	watchPollIntervalMs        = 250
+	fileNotifyInformationFixed = 12
+)
+
+// Watch watches the file at path and sends a fresh Info every time its
+// version-information resource changes: the file is replaced, renamed into
+// place or updated in-place. Notifications that don't change the resource
+// (e.g. timestamps updates) are dropped.
+//
+// The current state of the file is not sent. The channel is closed when ctx
+// is done. Errors re-reading the file (e.g. if it's still being written) are
+// ignored, the next change notification will trigger a new attempt.
+func Watch(ctx context.Context, path string, opts ...Option) (<-chan Info, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path of %q: %w", path, err)
+	}
+	dir, name := filepath.Split(path)
+	dirPtr, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert directory path to utf16: %w", err)
+	}
+	handle, err := windows.CreateFile(
+		dirPtr,
+		fileListDirectory,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil,
+		windows.OPEN_EXISTING,
+		windows.FILE_FLAG_BACKUP_SEMANTICS|windows.FILE_FLAG_OVERLAPPED,
+		0,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open directory %q: %w", dir, err)
+	}
+	port, err := windows.CreateIoCompletionPort(handle, 0, 0, 1)
+	if err != nil {
+		windows.CloseHandle(handle) //nolint:errcheck
+		return nil, fmt.Errorf("failed to create completion port: %w", err)
+	}
+
+	w := &watcher{
+		path:   path,
+		name:   name,
+		opts:   opts,
+		handle: handle,
+		port:   port,
+		buf:    make([]byte, watchBufferSize),
+		out:    make(chan Info),
+	}
+	if info, err := New(path, opts...); err == nil {
+		w.last = info.data
+	}
+	go w.run(ctx)
+	return w.out, nil
+}
+
+type watcher struct {
+	path   string
+	name   string
+	opts   []Option
+	handle windows.Handle
+	port   windows.Handle
+	buf    []byte
+	ov     windows.Overlapped
+	last   []byte
+	out    chan Info
+}
+
+func (w *watcher) run(ctx context.Context) {
+	defer close(w.out)
+	defer windows.CloseHandle(w.port)   //nolint:errcheck
+	defer windows.CloseHandle(w.handle) //nolint:errcheck
+
+	for {
+		w.ov = windows.Overlapped{}
+		err := windows.ReadDirectoryChanges(w.handle, &w.buf[0], uint32(len(w.buf)), false,
+			watchNotifyFilter, nil, &w.ov, 0)
+		if err != nil {
+			return
+		}
+		n, ok := w.wait(ctx)
+		if !ok {
+			return
+		}
+		// Zero bytes means the buffer overflowed and the changes were lost,
+		// so just recheck the file.
+		if n != 0 && !w.affected(w.buf[:n]) {
+			continue
+		}
+		info, err := New(w.path, w.opts...)
+		if err != nil || bytes.Equal(info.data, w.last) {
+			continue
+		}
+		w.last = info.data
+		select {
+		case w.out <- info:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// wait waits for the pending ReadDirectoryChanges completion. It returns false
+// if ctx is done or the operation failed. In the first case the operation is
+// cancelled and its completion is awaited, so the buffer is not used by the
+// kernel anymore.
+func (w *watcher) wait(ctx context.Context) (uint32, bool) {
+	for {
+		var n uint32
+		var key uintptr
+		var ov *windows.Overlapped
+		err := windows.GetQueuedCompletionStatus(w.port, &n, &key, &ov, watchPollIntervalMs)
+		if err == nil {
+			return n, true
+		}
+		if ov != nil {
+			// The operation itself failed.
+			return 0, false
+		}
+		select {
+		case <-ctx.Done():
+			windows.CancelIoEx(w.handle, &w.ov)                                        //nolint:errcheck
+			windows.GetQueuedCompletionStatus(w.port, &n, &key, &ov, windows.INFINITE) //nolint:errcheck
+			return 0, false
+		default:
+		}
+	}
+}
+
+// affected reports whether a FILE_NOTIFY_INFORMATION list mentions the file.
+func (w *watcher) affected(buf []byte) bool {
+	for len(buf) >= fileNotifyInformationFixed {
+		next := binary.LittleEndian.Uint32(buf)
+		nameLen := int(binary.LittleEndian.Uint32(buf[8:]))
+		if fileNotifyInformationFixed+nameLen <= len(buf) {
+			raw := buf[fileNotifyInformationFixed : fileNotifyInformationFixed+nameLen]
+			u16 := make([]uint16, nameLen/2)
+			for i := range u16 {
+				u16[i] = binary.LittleEndian.Uint16(raw[2*i:])
+			}
+			if strings.EqualFold(string(utf16.Decode(u16)), w.name) {
+				return true
+			}
+		}
+		if next == 0 || int(next) > len(buf) {
+			break
+		}
+		buf = buf[next:]
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+//nolint:gochecknoglobals
+var (
+	wimgapi                   = windows.NewLazySystemDLL("wimgapi.dll")
+	wimCreateFileProc         = wimgapi.NewProc("WIMCreateFile")
+	wimCloseHandleProc        = wimgapi.NewProc("WIMCloseHandle")
+	wimSetTemporaryPathProc   = wimgapi.NewProc("WIMSetTemporaryPath")
+	wimGetImageCountProc      = wimgapi.NewProc("WIMGetImageCount")
+	wimLoadImageProc          = wimgapi.NewProc("WIMLoadImage")
+	wimMountImageHandleProc   = wimgapi.NewProc("WIMMountImageHandle")
+	wimUnmountImageHandleProc = wimgapi.NewProc("WIMUnmountImageHandle")
+)
+
+// wimgapi constants, see wimgapi.h of the Windows ADK.
+const (
+	wimGenericRead       = windows.GENERIC_READ
+	wimGenericMount      = windows.GENERIC_EXECUTE
+	wimOpenExisting      = windows.OPEN_EXISTING
+	wimFlagMountReadOnly = 0x00000200
+)
+
+// walkWIM walks the files of every image of a WIM file, the names are
+// prefixed with the 1-based image index like "1/Windows/System32/ntdll.dll".
+// The images are mounted read-only with wimgapi, which requires
+// administrator rights. ESD files can't be mounted, export them to WIM with
+// DISM first.
+func walkWIM(path string, fn func(name string, r io.Reader) error) error {
+	if err := wimCreateFileProc.Find(); err != nil {
+		return fmt.Errorf("wimgapi is not available: %w", err)
+	}
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+	var created uint32
+	wim, _, err := wimCreateFileProc.Call(uintptr(unsafe.Pointer(pathPtr)), wimGenericRead|wimGenericMount,
+		wimOpenExisting, 0, 0, uintptr(unsafe.Pointer(&created)))
+	if wim == 0 {
+		return fmt.Errorf("failed to open WIM file: %w", err)
+	}
+	defer wimCloseHandleProc.Call(wim) //nolint:errcheck
+
+	temp, err := windows.UTF16PtrFromString(os.TempDir())
+	if err != nil {
+		return err
+	}
+	if ok, _, err := wimSetTemporaryPathProc.Call(wim, uintptr(unsafe.Pointer(temp))); ok == 0 {
+		return fmt.Errorf("failed to set WIM temporary path: %w", err)
+	}
+	count, _, _ := wimGetImageCountProc.Call(wim)
+	for index := uintptr(1); index <= count; index++ {
+		if err := walkWIMImage(wim, index, fn); err != nil {
+			return fmt.Errorf("failed to walk image %d: %w", index, err)
+		}
+	}
+	return nil
+}
+
+// walkWIMImage mounts the image to a temporary directory and walks it.
+func walkWIMImage(wim, index uintptr, fn func(name string, r io.Reader) error) error {
+	image, _, err := wimLoadImageProc.Call(wim, index)
+	if image == 0 {
+		return fmt.Errorf("failed to load image: %w", err)
+	}
+	defer wimCloseHandleProc.Call(image) //nolint:errcheck
+
+	dir, err := ioutil.TempDir("", "fileversion-wim")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(dir)
+	dirPtr, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return err
+	}
+	if ok, _, err := wimMountImageHandleProc.Call(image, uintptr(unsafe.Pointer(dirPtr)), wimFlagMountReadOnly); ok == 0 {
+		return fmt.Errorf("failed to mount image: %w", err)
+	}
+	// The image is read-only, nothing to commit.
+	defer wimUnmountImageHandleProc.Call(image, 0) //nolint:errcheck
+
+	prefix := strconv.Itoa(int(index)) + "/"
+	return filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			// Directories denied even to administrators, like System Volume
+			// Information, are skipped.
+			if fi != nil && fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		return walkWIMFile(path, prefix+filepath.ToSlash(rel), fn)
+	})
+}
+
+func walkWIMFile(path, name string, fn func(name string, r io.Reader) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		// Like the unreadable directories.
+		return nil
+	}
+	defer file.Close()
+	return fn(name, file)
+}
@@ -0,0 +1,152 @@
+package fileversion
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// WindowsRelease is a Windows release identified by its build number.
+type WindowsRelease struct {
+	Major uint16
+	Minor uint16
+	Build uint16
+	// Product is the client product name, e.g. "Windows 11".
+	Product string
+	// Version is the marketing version, e.g. "24H2". It's empty for releases
+	// without one.
+	Version string
+	// Server is the server product sharing the build, if any.
+	Server string
+}
+
+// WindowsUpdate is a cumulative update raising the update build revision
+// (UBR, the fourth version part) of a release.
+type WindowsUpdate struct {
+	Build    uint16
+	UBR      uint16
+	KB       string
+	Released time.Time
+}
+
+// PatchLevel is the Windows release and the latest known cumulative update a
+// system file version corresponds to.
+type PatchLevel struct {
+	Release WindowsRelease
+	// Update is the latest known update with UBR not greater than the file
+	// one. HasUpdate is false if the table has no such update, e.g. for the
+	// RTM builds and for updates newer than the table.
+	Update    WindowsUpdate
+	HasUpdate bool
+	// Exact reports whether the file UBR equals Update.UBR. Files not changed
+	// by the latest updates keep older revisions, so only files updated by
+	// every CU (like ntoskrnl.exe) give exact matches.
+	Exact bool
+}
+
+//nolint:gochecknoglobals
+var windowsReleases = []WindowsRelease{
+	{5, 1, 2600, "Windows XP", "", ""},
+	{5, 2, 3790, "Windows XP x64", "", "Windows Server 2003"},
+	{6, 0, 6000, "Windows Vista", "", ""},
+	{6, 0, 6001, "Windows Vista", "SP1", "Windows Server 2008"},
+	{6, 0, 6002, "Windows Vista", "SP2", "Windows Server 2008 SP2"},
+	{6, 1, 7600, "Windows 7", "", "Windows Server 2008 R2"},
+	{6, 1, 7601, "Windows 7", "SP1", "Windows Server 2008 R2 SP1"},
+	{6, 2, 9200, "Windows 8", "", "Windows Server 2012"},
+	{6, 3, 9600, "Windows 8.1", "", "Windows Server 2012 R2"},
+	{10, 0, 10240, "Windows 10", "1507", ""},
+	{10, 0, 10586, "Windows 10", "1511", ""},
+	{10, 0, 14393, "Windows 10", "1607", "Windows Server 2016"},
+	{10, 0, 15063, "Windows 10", "1703", ""},
+	{10, 0, 16299, "Windows 10", "1709", ""},
+	{10, 0, 17134, "Windows 10", "1803", ""},
+	{10, 0, 17763, "Windows 10", "1809", "Windows Server 2019"},
+	{10, 0, 18362, "Windows 10", "1903", ""},
+	{10, 0, 18363, "Windows 10", "1909", ""},
+	{10, 0, 19041, "Windows 10", "2004", ""},
+	{10, 0, 19042, "Windows 10", "20H2", ""},
+	{10, 0, 19043, "Windows 10", "21H1", ""},
+	{10, 0, 19044, "Windows 10", "21H2", ""},
+	{10, 0, 19045, "Windows 10", "22H2", ""},
+	{10, 0, 20348, "", "", "Windows Server 2022"},
+	{10, 0, 22000, "Windows 11", "21H2", ""},
+	{10, 0, 22621, "Windows 11", "22H2", ""},
+	{10, 0, 22631, "Windows 11", "23H2", ""},
+	{10, 0, 26100, "Windows 11", "24H2", "Windows Server 2025"},
+	{10, 0, 26200, "Windows 11", "25H2", ""},
+}
+
+//nolint:gochecknoglobals
+var (
+	windowsUpdatesMu sync.RWMutex
+	// windowsUpdates are kept sorted by build and UBR. The embedded table is
+	// only a seed, up-to-date data should be registered by the caller, e.g.
+	// from the Microsoft Update Catalog.
+	windowsUpdates = []WindowsUpdate{
+		{19045, 5608, "KB5053606", time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{19045, 5737, "KB5055518", time.Date(2025, 4, 8, 0, 0, 0, 0, time.UTC)},
+		{22631, 5039, "KB5053602", time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{22631, 5189, "KB5055528", time.Date(2025, 4, 8, 0, 0, 0, 0, time.UTC)},
+		{26100, 3476, "KB5053598", time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{26100, 3775, "KB5055523", time.Date(2025, 4, 8, 0, 0, 0, 0, time.UTC)},
+	}
+)
+
+// RegisterWindowsUpdates adds cumulative updates to the table used by
+// WindowsPatchLevel, replacing the known ones with the same build and UBR.
+func RegisterWindowsUpdates(updates ...WindowsUpdate) {
+	windowsUpdatesMu.Lock()
+	defer windowsUpdatesMu.Unlock()
+	for _, u := range updates {
+		i := sort.Search(len(windowsUpdates), func(i int) bool {
+			return !updateLess(windowsUpdates[i], u)
+		})
+		if i < len(windowsUpdates) && windowsUpdates[i].Build == u.Build && windowsUpdates[i].UBR == u.UBR {
+			windowsUpdates[i] = u
+			continue
+		}
+		windowsUpdates = append(windowsUpdates, WindowsUpdate{})
+		copy(windowsUpdates[i+1:], windowsUpdates[i:])
+		windowsUpdates[i] = u
+	}
+}
+
+func updateLess(a, b WindowsUpdate) bool {
+	if a.Build != b.Build {
+		return a.Build < b.Build
+	}
+	return a.UBR < b.UBR
+}
+
+// WindowsPatchLevel maps the binary version of a system file (e.g. the
+// FixedInfo FileVersion of ntoskrnl.exe, 10.0.26100.3775) to the Windows
+// release and the cumulative update (24H2, KB5055523 of April 2025). The
+// third version part is the build and the fourth one is the UBR. It returns
+// false for versions of unknown releases.
+func WindowsPatchLevel(v FileVersion) (PatchLevel, bool) {
+	var level PatchLevel
+	found := false
+	for _, r := range windowsReleases {
+		if r.Major == v.Major && r.Minor == v.Minor && r.Build == v.Build {
+			level.Release = r
+			found = true
+			break
+		}
+	}
+	if !found {
+		return PatchLevel{}, false
+	}
+
+	windowsUpdatesMu.RLock()
+	defer windowsUpdatesMu.RUnlock()
+	for _, u := range windowsUpdates {
+		if u.Build != v.Build || u.UBR > v.Patch {
+			continue
+		}
+		level.Update = u
+		level.HasUpdate = true
+	}
+	level.Exact = level.HasUpdate && level.Update.UBR == v.Patch
+	return level, true
+}
@@ -0,0 +1,96 @@
+package fileversion
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestRegisterWindowsUpdates(t *testing.T) {
+	saved := append([]WindowsUpdate(nil), windowsUpdates...)
+	defer func() { windowsUpdates = saved }()
+
+	may := time.Date(2025, 5, 13, 0, 0, 0, 0, time.UTC)
+	RegisterWindowsUpdates(
+		// Out of order, before, between and after the known ones.
+		WindowsUpdate{26100, 4061, "KB5058411", may},
+		WindowsUpdate{19045, 100, "KB0000001", may},
+		WindowsUpdate{22631, 5100, "KB0000002", may},
+		WindowsUpdate{26200, 1, "KB0000003", may},
+		// Replaces the seed entry.
+		WindowsUpdate{26100, 3775, "KB5055523-v2", may},
+		// Replaces the update registered above in the same call.
+		WindowsUpdate{26100, 4061, "KB5058411-v2", may},
+	)
+
+	if !sort.SliceIsSorted(windowsUpdates, func(i, j int) bool { return updateLess(windowsUpdates[i], windowsUpdates[j]) }) {
+		t.Errorf("updates are not sorted: %v", windowsUpdates)
+	}
+	for i := 1; i < len(windowsUpdates); i++ {
+		if !updateLess(windowsUpdates[i-1], windowsUpdates[i]) {
+			t.Errorf("duplicate update %v", windowsUpdates[i])
+		}
+	}
+	if got, want := len(windowsUpdates), len(saved)+4; got != want {
+		t.Errorf("got %d updates, want %d", got, want)
+	}
+
+	tests := []struct {
+		version FileVersion
+		kb      string
+		exact   bool
+	}{
+		{FileVersion{Major: 10, Build: 26100, Patch: 3775}, "KB5055523-v2", true},
+		{FileVersion{Major: 10, Build: 26100, Patch: 4100}, "KB5058411-v2", false},
+		{FileVersion{Major: 10, Build: 22631, Patch: 5150}, "KB0000002", false},
+		{FileVersion{Major: 10, Build: 19045, Patch: 100}, "KB0000001", true},
+		{FileVersion{Major: 10, Build: 26200, Patch: 7}, "KB0000003", false},
+	}
+	for _, tt := range tests {
+		level, ok := WindowsPatchLevel(tt.version)
+		if !ok || !level.HasUpdate || level.Update.KB != tt.kb || level.Exact != tt.exact {
+			t.Errorf("WindowsPatchLevel(%v) = %+v, %v, want %s, exact %v", tt.version, level, ok, tt.kb, tt.exact)
+		}
+	}
+}
+
+func TestWindowsPatchLevel(t *testing.T) {
+	tests := []struct {
+		version FileVersion
+		ok      bool
+		want    PatchLevel
+	}{
+		{
+			FileVersion{Major: 10, Build: 26100, Patch: 3775}, true,
+			PatchLevel{
+				Release:   WindowsRelease{10, 0, 26100, "Windows 11", "24H2", "Windows Server 2025"},
+				Update:    WindowsUpdate{26100, 3775, "KB5055523", time.Date(2025, 4, 8, 0, 0, 0, 0, time.UTC)},
+				HasUpdate: true,
+				Exact:     true,
+			},
+		},
+		{
+			FileVersion{Major: 10, Build: 19045, Patch: 5700}, true,
+			PatchLevel{
+				Release:   WindowsRelease{10, 0, 19045, "Windows 10", "22H2", ""},
+				Update:    WindowsUpdate{19045, 5608, "KB5053606", time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC)},
+				HasUpdate: true,
+			},
+		},
+		// RTM: no update in the table.
+		{
+			FileVersion{Major: 6, Minor: 1, Build: 7601, Patch: 17514}, true,
+			PatchLevel{Release: WindowsRelease{6, 1, 7601, "Windows 7", "SP1", "Windows Server 2008 R2 SP1"}},
+		},
+		{FileVersion{Major: 10, Build: 12345}, false, PatchLevel{}},
+		// The build of another major version.
+		{FileVersion{Major: 6, Build: 19045}, false, PatchLevel{}},
+	}
+	for _, tt := range tests {
+		got, ok := WindowsPatchLevel(tt.version)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("WindowsPatchLevel(%v) = %+v, %v, want %+v, %v", tt.version, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+//go:build windows
+// +build windows
+
+package fileversion
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// newWindowsError wraps err returned by a windows call on path.
+func newWindowsError(op, path string, err error) error {
+	e := &Error{Op: op, Path: path, Err: err}
+	if errno, ok := err.(windows.Errno); ok {
+		switch errno {
+		case windows.ERROR_RESOURCE_DATA_NOT_FOUND, windows.ERROR_RESOURCE_TYPE_NOT_FOUND,
+			windows.ERROR_RESOURCE_NAME_NOT_FOUND, windows.ERROR_RESOURCE_LANG_NOT_FOUND:
+			e.Kind = ErrNoVersionInfo
+		case windows.ERROR_BAD_FORMAT, windows.ERROR_BAD_EXE_FORMAT:
+			e.Kind = ErrNotPE
+		}
+	}
+	return e
+}
+
+// newQueryError wraps err returned by VerQueryValue. VerQueryValue doesn't
+// always set the last error, so a zero errno is dropped.
+func newQueryError(path, subBlock string, err error) error {
+	if errno, ok := err.(windows.Errno); ok && errno == 0 {
+		err = nil
+	}
+	return &Error{Op: "VerQueryValue", Path: path, SubBlock: subBlock, Err: err}
+}